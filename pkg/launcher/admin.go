@@ -0,0 +1,166 @@
+// Package launcher provides the built-in admin HTTP server.
+// This file contains the admin server that runs as a managed task next to the
+// launched applications and exposes liveness, readiness and health endpoints.
+package launcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// adminShutdownTimeout bounds the graceful shutdown of the admin server.
+const adminShutdownTimeout = 5 * time.Second
+
+// adminServerTaskName is the task name of the admin server in logs and results.
+const adminServerTaskName = "admin-server"
+
+// adminServer holds the admin HTTP configuration.
+type adminServer struct {
+	addr       string                 // Listen address, empty if the server is disabled
+	mux        *http.ServeMux         // Admin routes, shared by all launcher features
+	listenAddr atomic.Pointer[string] // Actual address once the server is listening
+}
+
+// readinessReport is the /readyz response body.
+type readinessReport struct {
+	HealthReport
+	Ready bool `json:"ready"`
+}
+
+// WithAdminServer starts an admin HTTP server on addr as a managed task next to
+// the launched applications. The server exposes:
+//   - /livez  - always 200 while the process is serving requests,
+//   - /readyz - 200 when the launcher is ready and all health checks pass, 503 otherwise,
+//   - /healthz - 200 when all health checks pass, 503 otherwise.
+//
+// Readiness and health responses contain a JSON body listing each check's status and latency.
+// The server is stopped after all application tasks have returned; if the server
+// itself fails (for example, the address is already in use) the application tasks are stopped.
+// Returns the same launcher instance for method chaining (fluent API).
+func (a *AppLauncher) WithAdminServer(addr string) *AppLauncher {
+	admin := a.adminServer()
+	admin.addr = addr
+	return a
+}
+
+// HandleAdmin mounts a handler on the admin server.
+// Handlers can be registered before WithAdminServer is called.
+// Returns the same launcher instance for method chaining (fluent API).
+func (a *AppLauncher) HandleAdmin(pattern string, handler http.Handler) *AppLauncher {
+	a.adminServer().mux.Handle(pattern, handler)
+	return a
+}
+
+// AdminAddr returns the address the admin server is listening on,
+// or an empty string if the server is disabled or not started yet.
+// It is useful when the server was configured with port 0.
+func (a *AppLauncher) AdminAddr() string {
+	if a.admin == nil {
+		return ""
+	}
+	if addr := a.admin.listenAddr.Load(); addr != nil {
+		return *addr
+	}
+	return ""
+}
+
+// adminServer returns the admin configuration, creating it and registering
+// the built-in routes on first use.
+func (a *AppLauncher) adminServer() *adminServer {
+	if a.admin == nil {
+		a.admin = &adminServer{mux: http.NewServeMux()}
+		a.admin.mux.HandleFunc("GET /livez", a.handleLivez)
+		a.admin.mux.HandleFunc("GET /readyz", a.handleReadyz)
+		a.admin.mux.HandleFunc("GET /healthz", a.handleHealthz)
+	}
+	return a.admin
+}
+
+// managedTasks returns the tasks the launcher runs on its own next to the application tasks.
+func (a *AppLauncher) managedTasks() []namedTask {
+	var tasks []namedTask
+	if a.admin != nil && a.admin.addr != "" {
+		tasks = append(tasks, namedTask{name: adminServerTaskName, task: a.admin.run, managed: true})
+	}
+	return tasks
+}
+
+// run serves admin requests until the context is done and then shuts the server down.
+func (s *adminServer) run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	addr := listener.Addr().String()
+	s.listenAddr.Store(&addr)
+
+	server := &http.Server{
+		Handler:           s.mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), adminShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// handleLivez reports that the process is alive.
+func (a *AppLauncher) handleLivez(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": HealthStatusOK})
+}
+
+// handleReadyz reports whether the application is ready to receive traffic.
+func (a *AppLauncher) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	report := readinessReport{
+		HealthReport: a.health.Check(r.Context()),
+		Ready:        a.Ready(),
+	}
+	if !report.Ready {
+		report.Status = HealthStatusFail
+	}
+	writeJSON(w, healthStatusCode(report.HealthReport), report)
+}
+
+// handleHealthz reports the status of every registered health check.
+func (a *AppLauncher) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	report := a.health.Check(r.Context())
+	writeJSON(w, healthStatusCode(report), report)
+}
+
+// healthStatusCode maps a health report to an HTTP status code.
+func healthStatusCode(report HealthReport) int {
+	if report.Healthy() {
+		return http.StatusOK
+	}
+	return http.StatusServiceUnavailable
+}
+
+// writeJSON writes the value as a JSON response with the given status code.
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
@@ -0,0 +1,197 @@
+package launcher
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// getJSON performs a GET request against the admin server and decodes the JSON body
+func getJSON(t *testing.T, url string) (int, map[string]any) {
+	t.Helper()
+
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	var body map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode %s response: %v", url, err)
+	}
+	return resp.StatusCode, body
+}
+
+// TestAdminServerEndpoints tests liveness, readiness and health endpoints
+func TestAdminServerEndpoints(t *testing.T) {
+	var redisHealthy atomic.Bool
+	redisHealthy.Store(true)
+
+	launcher := NewAppLauncher().
+		WithGracefulShutdown(100*time.Millisecond, time.Second).
+		WithAdminServer("127.0.0.1:0").
+		WithHealthCheck("redis", HealthCheckFunc(func(ctx context.Context) error {
+			if !redisHealthy.Load() {
+				return errors.New("redis unavailable")
+			}
+			return nil
+		}), HealthCheckOptions{})
+
+	done := make(chan *AppResult, 1)
+	go func() {
+		done <- launcher.WaitApplication(func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		})
+	}()
+
+	waitUntil(t, time.Second, func() bool { return launcher.AdminAddr() != "" && launcher.Ready() })
+	base := "http://" + launcher.AdminAddr()
+
+	if status, body := getJSON(t, base+"/livez"); status != http.StatusOK || body["status"] != "ok" {
+		t.Errorf("Unexpected /livez response: %d %v", status, body)
+	}
+
+	status, body := getJSON(t, base+"/readyz")
+	if status != http.StatusOK || body["ready"] != true {
+		t.Errorf("Unexpected /readyz response: %d %v", status, body)
+	}
+	checks, _ := body["checks"].([]any)
+	if len(checks) != 1 {
+		t.Fatalf("Expected 1 check in /readyz body, got %v", body["checks"])
+	}
+	check := checks[0].(map[string]any)
+	if check["name"] != "redis" || check["status"] != "ok" {
+		t.Errorf("Unexpected check result: %v", check)
+	}
+	if _, ok := check["latency_ms"]; !ok {
+		t.Error("Expected latency in check result")
+	}
+
+	redisHealthy.Store(false)
+	if status, body := getJSON(t, base+"/healthz"); status != http.StatusServiceUnavailable || body["status"] != "fail" {
+		t.Errorf("Unexpected /healthz response: %d %v", status, body)
+	}
+	redisHealthy.Store(true)
+
+	// During the drain period the server keeps serving but reports not ready
+	launcher.Shutdown()
+	waitUntil(t, time.Second, func() bool { return !launcher.Ready() })
+	if status, body := getJSON(t, base+"/readyz"); status != http.StatusServiceUnavailable || body["ready"] != false {
+		t.Errorf("Unexpected /readyz response while draining: %d %v", status, body)
+	}
+
+	result := <-done
+	if result.Error() != nil {
+		t.Errorf("Expected clean shutdown, got: %v", result.Error())
+	}
+	if len(result.Tasks) != 2 || result.Tasks[1].Name != adminServerTaskName {
+		t.Errorf("Expected admin server to be a managed task, got %+v", result.Tasks)
+	}
+}
+
+// TestAdminServerConfiguredTwice tests that WithAdminServer can be called again to change the address
+func TestAdminServerConfiguredTwice(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	launcher := NewAppLauncherWithContext(ctx).
+		HandleAdmin("GET /version", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusOK, map[string]string{"version": "1.0.0"})
+		})).
+		WithAdminServer("127.0.0.1:1").
+		WithAdminServer("127.0.0.1:0")
+
+	done := make(chan *AppResult, 1)
+	go func() {
+		done <- launcher.WaitApplication(func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		})
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	waitUntil(t, time.Second, func() bool { return launcher.AdminAddr() != "" })
+
+	for _, path := range []string{"/livez", "/version"} {
+		if status, _ := getJSON(t, "http://"+launcher.AdminAddr()+path); status != http.StatusOK {
+			t.Errorf("Expected 200 from %s, got %d", path, status)
+		}
+	}
+}
+
+// TestAdminServerStopsWithTasks tests that the admin server does not outlive the application tasks
+func TestAdminServerStopsWithTasks(t *testing.T) {
+	done := make(chan *AppResult, 1)
+	go func() {
+		done <- NewAppLauncher().
+			WithAdminServer("127.0.0.1:0").
+			WaitApplication(func(ctx context.Context) error {
+				time.Sleep(10 * time.Millisecond)
+				return nil
+			})
+	}()
+
+	select {
+	case result := <-done:
+		if result.Error() != nil {
+			t.Errorf("Expected no error, got: %v", result.Error())
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Admin server kept the launcher running after the tasks returned")
+	}
+}
+
+// TestAdminServerListenError tests that a bind failure is reported as a task failure
+func TestAdminServerListenError(t *testing.T) {
+	result := NewAppLauncher().
+		WithAdminServer("invalid-address").
+		WaitApplication(func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		})
+
+	if len(result.Failed()) != 1 || result.Failed()[0].Name != adminServerTaskName {
+		t.Errorf("Expected admin server failure, got: %v", result.Error())
+	}
+}
+
+// TestAdminServerReservedTaskName tests that a task cannot take the name of the admin server
+func TestAdminServerReservedTaskName(t *testing.T) {
+	run := func(ctx context.Context) error {
+		t.Error("Task must not be started with a reserved name")
+		return nil
+	}
+
+	result := NewAppLauncher().
+		WithAdminServer("127.0.0.1:0").
+		WaitTasks(Task{Name: adminServerTaskName, Run: run}, Task{Name: "consumer", Run: run, DependsOn: []string{adminServerTaskName}})
+	if result.Error() == nil || !strings.Contains(result.Error().Error(), "is reserved") {
+		t.Errorf("Expected a reserved name error, got: %v", result.Error())
+	}
+
+	var output bytes.Buffer
+	result = NewAppLauncher().
+		WithValidateMode(true).
+		WithValidateOutput(&output).
+		WithAdminServer("127.0.0.1:0").
+		WaitTasks(Task{Name: adminServerTaskName, Run: run})
+	if !errors.Is(result.Error(), ErrValidationFailed) {
+		t.Fatalf("Expected ErrValidationFailed, got: %v", result.Error())
+	}
+	if failed := failedChecks(decodeReport(t, &output)); !strings.Contains(failed["task "+adminServerTaskName], "is reserved") {
+		t.Errorf("Expected the reserved name to be reported, got %v", failed)
+	}
+
+	if result := NewAppLauncher().WaitTasks(Task{Name: adminServerTaskName, Run: func(ctx context.Context) error { return nil }}); result.Error() != nil {
+		t.Errorf("Expected the name to be available without an admin server, got: %v", result.Error())
+	}
+}
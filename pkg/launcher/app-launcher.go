@@ -6,6 +6,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/goregion/goture"
@@ -13,32 +17,95 @@ import (
 	"github.com/goregion/hexago/pkg/log"
 )
 
+// TaskOutcome describes how a single launched task finished
+type TaskOutcome struct {
+	Name      string    // Task name, "task-<index>" for anonymous tasks
+	Index     int       // Position of the task in the launch call
+	Err       error     // Error returned by the task, nil on success
+	StartedAt time.Time // When the task was started
+	EndedAt   time.Time // When the task returned, zero if it was abandoned at the shutdown deadline
+	Canceled  bool      // Whether the task ended after its context was canceled
+}
+
+// Duration returns how long the task was running.
+// For abandoned tasks it returns zero.
+func (o TaskOutcome) Duration() time.Duration {
+	if o.EndedAt.IsZero() {
+		return 0
+	}
+	return o.EndedAt.Sub(o.StartedAt)
+}
+
 // AppResult represents the result of application execution with error handling capabilities
 type AppResult struct {
-	Err error // Public error field for easy access
+	Err   error         // Public error field for easy access, joins the errors of all failed tasks
+	Tasks []TaskOutcome // Per-task outcomes in launch order, empty if no task was started
 }
 
 // LogIfError logs the error if it exists using the provided logger.
-// It safely handles nil logger and only logs when there's an actual error.
+// Every failed task is logged as a separate record with its name, index, duration
+// and cancellation flag. It safely handles nil logger and only logs when there's an actual error.
 func (r *AppResult) LogIfError(logger *log.Logger, messages ...any) {
-	if r.Err != nil && logger != nil {
+	if r.Err == nil || logger == nil {
+		return
+	}
+
+	failed := r.Failed()
+	if len(failed) == 0 {
 		logger.LogIfError(r.Err, messages...)
+		return
+	}
+
+	for _, outcome := range failed {
+		logger.WithFields(map[string]any{
+			"task":       outcome.Name,
+			"task_index": outcome.Index,
+			"duration":   outcome.Duration(),
+			"canceled":   outcome.Canceled,
+		}).LogIfError(outcome.Err, messages...)
 	}
 }
 
-// Error returns the underlying error from application execution
+// Error returns the underlying error from application execution.
+// When several tasks fail, the errors are joined and can be inspected with errors.Is and errors.As.
 func (r *AppResult) Error() error {
 	return r.Err
 }
 
+// Failed returns the outcomes of tasks that finished with an error, in launch order.
+func (r *AppResult) Failed() []TaskOutcome {
+	var failed []TaskOutcome
+	for _, outcome := range r.Tasks {
+		if outcome.Err != nil {
+			failed = append(failed, outcome)
+		}
+	}
+	return failed
+}
+
 // AppLauncher provides a fluent API for launching applications
 // with proper context management, logging, and graceful shutdown capabilities.
 // It manages application context and provides methods for launching applications
 // with proper context enrichment (logging, graceful shutdown, etc.)
 type AppLauncher struct {
 	context.Context
-	parentContext context.Context    // Store parent context before timeout
-	cancelFunc    context.CancelFunc // Store cancel function for timeout cleanup - keep private for safety
+	parentContext  context.Context    // Store parent context before timeout
+	cancelFunc     context.CancelFunc // Store cancel function for timeout cleanup - keep private for safety
+	shutdown       *shutdownConfig    // Graceful shutdown sequence, nil if not configured
+	signals        chan os.Signal     // Termination signals, fed by the OS and by Shutdown
+	ready          atomic.Bool        // Whether tasks are running and not shutting down
+	repanic        bool               // Re-panic after logging a task panic
+	health         *HealthRegistry    // Health checks exposed by the admin server
+	admin          *adminServer       // Admin HTTP server, nil if not configured
+	reloads        chan os.Signal     // Reload requests, fed by SIGHUP and by Reload
+	reloadMu       sync.Mutex         // Guards reloadHooks
+	reloadHooks    []reloadHook       // Hooks called on every reload
+	startupTimeout time.Duration      // Time tasks have to become ready, zero means no limit
+	metrics        *launcherMetrics   // Runtime metrics, nil if not configured
+	stackDump      bool               // Log every goroutine stack when the shutdown deadline is exceeded
+	validateMode   bool               // Check the wiring and report instead of starting tasks
+	validateOutput io.Writer          // Validation report output, os.Stdout if nil
+	validators     []namedValidator   // Configuration checks registered with ValidateConfig
 }
 
 // NewAppLauncher creates a new application launcher with background context.
@@ -49,6 +116,7 @@ func NewAppLauncher() *AppLauncher {
 
 // NewAppLauncherWithContext creates a new application launcher with the provided context.
 // If the provided context is nil, it defaults to background context for safety.
+// The validate mode is enabled if the APP_VALIDATE environment variable is true.
 func NewAppLauncherWithContext(ctx context.Context) *AppLauncher {
 	if ctx == nil {
 		ctx = context.Background()
@@ -56,6 +124,10 @@ func NewAppLauncherWithContext(ctx context.Context) *AppLauncher {
 	return &AppLauncher{
 		Context:       ctx,
 		parentContext: ctx,
+		signals:       make(chan os.Signal, 2),
+		reloads:       make(chan os.Signal, 1),
+		health:        NewHealthRegistry(),
+		validateMode:  validateEnvEnabled(),
 	}
 }
 
@@ -72,6 +144,7 @@ func (a *AppLauncher) WithLoggerContext(logger *log.Logger) *AppLauncher {
 
 // WithGrexitContext enriches the launcher context with graceful exit capabilities.
 // This enables automatic handling of system signals (SIGINT, SIGTERM) for clean shutdown.
+// SIGHUP does not stop the applications, it triggers the reload hooks (see OnReload).
 // Returns the same launcher instance for method chaining (fluent API).
 func (a *AppLauncher) WithGrexitContext() *AppLauncher {
 	a.Context = grexit.WithGrexitContext(a.Context)
@@ -123,25 +196,22 @@ func (a *AppLauncher) WithoutTimeout() *AppLauncher {
 
 // WaitApplication launches a single application task and waits for its completion.
 // The task receives the enriched context with all configured dependencies (logger, graceful exit, etc.)
+// After cancellation the launcher waits for the task to return, bounded by WithGracefulShutdown if configured.
 // Returns AppResult which can be used to check for errors and log them if needed.
 func (a *AppLauncher) WaitApplication(task goture.Task) *AppResult {
 	if task == nil {
 		return &AppResult{Err: errors.New("task cannot be nil")}
 	}
 
-	// Ensure cleanup if timeout was set
-	if a.cancelFunc != nil {
-		defer a.cancelFunc()
-	}
-
-	return &AppResult{
-		Err: goture.NewGoture(a.Context, task).Wait(),
-	}
+	return a.runTasks(defaultTaskNames([]goture.Task{task}))
 }
 
 // WaitApplications launches multiple application tasks in parallel and waits for their completion.
-// All tasks receive the same enriched context and run concurrently.
-// If any task fails, the error will be returned in the result.
+// All tasks receive the enriched context and run concurrently. Each task context carries the task
+// identity (see TaskFromContext) and a logger with the task=task-<index> attribute; use WaitTasks
+// to give the tasks meaningful names.
+// If any tasks fail, their errors are joined in the result and listed per task in AppResult.Tasks.
+// After cancellation the launcher waits for all tasks to return, bounded by WithGracefulShutdown if configured.
 // Returns AppResult which can be used to check for errors and log them if needed.
 func (a *AppLauncher) WaitApplications(tasks ...goture.Task) *AppResult {
 	if len(tasks) == 0 {
@@ -155,12 +225,5 @@ func (a *AppLauncher) WaitApplications(tasks ...goture.Task) *AppResult {
 		}
 	}
 
-	// Ensure cleanup if timeout was set
-	if a.cancelFunc != nil {
-		defer a.cancelFunc()
-	}
-
-	return &AppResult{
-		Err: goture.NewParallelGoture(a.Context, tasks...).Wait(),
-	}
+	return a.runTasks(defaultTaskNames(tasks))
 }
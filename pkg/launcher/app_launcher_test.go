@@ -2,7 +2,9 @@ package launcher
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -375,3 +377,106 @@ func BenchmarkFluentAPI(b *testing.B) {
 		}
 	}
 }
+
+// outcomeError is a custom error type used to verify errors.As on joined results
+type outcomeError struct {
+	code int
+}
+
+func (e *outcomeError) Error() string {
+	return "outcome error"
+}
+
+// TestAppResultTaskOutcomes tests that every task outcome is recorded
+func TestAppResultTaskOutcomes(t *testing.T) {
+	errA := errors.New("task a failed")
+	errB := &outcomeError{code: 42}
+
+	result := NewAppLauncher().WaitApplications(
+		func(ctx context.Context) error { return errA },
+		func(ctx context.Context) error { return nil },
+		func(ctx context.Context) error {
+			time.Sleep(5 * time.Millisecond)
+			return errB
+		},
+	)
+
+	if len(result.Tasks) != 3 {
+		t.Fatalf("Expected 3 task outcomes, got %d", len(result.Tasks))
+	}
+	for i, outcome := range result.Tasks {
+		if outcome.Index != i {
+			t.Errorf("Expected index %d, got %d", i, outcome.Index)
+		}
+		if outcome.Name == "" {
+			t.Errorf("Task %d has no name", i)
+		}
+		if outcome.StartedAt.IsZero() || outcome.EndedAt.Before(outcome.StartedAt) {
+			t.Errorf("Task %d has invalid timing: %+v", i, outcome)
+		}
+		if outcome.Canceled {
+			t.Errorf("Task %d must not be marked as canceled", i)
+		}
+	}
+	if result.Tasks[2].Duration() < 5*time.Millisecond {
+		t.Errorf("Expected duration of at least 5ms, got %v", result.Tasks[2].Duration())
+	}
+
+	if failed := result.Failed(); len(failed) != 2 || failed[0].Index != 0 || failed[1].Index != 2 {
+		t.Errorf("Expected tasks 0 and 2 to fail, got %+v", failed)
+	}
+
+	// All errors must be reachable through the joined error
+	if !errors.Is(result.Error(), errA) {
+		t.Errorf("Expected joined error to contain errA, got: %v", result.Error())
+	}
+	var target *outcomeError
+	if !errors.As(result.Error(), &target) || target.code != 42 {
+		t.Errorf("Expected joined error to contain outcomeError, got: %v", result.Error())
+	}
+}
+
+// TestAppResultCanceledOutcome tests that cancellation is reflected in task outcomes
+func TestAppResultCanceledOutcome(t *testing.T) {
+	result := NewAppLauncher().
+		WithTimeout(10 * time.Millisecond).
+		WaitApplication(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+	if len(result.Tasks) != 1 || !result.Tasks[0].Canceled {
+		t.Errorf("Expected canceled outcome, got %+v", result.Tasks)
+	}
+}
+
+// TestAppResultLogIfErrorPerTask tests that LogIfError emits one record per failed task
+func TestAppResultLogIfErrorPerTask(t *testing.T) {
+	var logOutput strings.Builder
+	logger := log.NewLogger(log.NewJsonHandler(&logOutput))
+
+	result := NewAppLauncher().WaitApplications(
+		func(ctx context.Context) error { return errors.New("first") },
+		func(ctx context.Context) error { return nil },
+		func(ctx context.Context) error { return errors.New("second") },
+	)
+	result.LogIfError(logger, "task failed")
+
+	lines := strings.Split(strings.TrimSpace(logOutput.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 log records, got %d: %s", len(lines), logOutput.String())
+	}
+
+	for i, expected := range []struct {
+		task  string
+		error string
+	}{{"task-0", "first"}, {"task-2", "second"}} {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(lines[i]), &entry); err != nil {
+			t.Fatalf("Failed to parse log record: %v", err)
+		}
+		if entry["msg"] != "task failed" || entry["task"] != expected.task || entry["error"] != expected.error {
+			t.Errorf("Unexpected log record: %v", entry)
+		}
+	}
+}
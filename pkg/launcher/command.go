@@ -0,0 +1,379 @@
+// Package launcher provides multi-command binaries on top of AppLauncher.
+// This file contains the CommandRegistry that dispatches a binary to one of its
+// named commands (or all of them together) with shared flag parsing and help output.
+package launcher
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/goregion/goture"
+)
+
+// DefaultAllInOneCommand is the name of the command that runs every registered command together.
+const DefaultAllInOneCommand = "all-in-one"
+
+// Command describes a named command of a multi-command binary.
+type Command struct {
+	Name        string                    // Command name used on the command line
+	Description string                    // One-line description shown in the help output
+	Flags       func(*flag.FlagSet)       // Defines command-specific flags, optional
+	Standalone  bool                      // Excluded from the all-in-one command, e.g. one-shot migrations
+	Tasks       CommandTasksFunc          // Builds the tasks of the command after flags are parsed
+	Validate    func(args []string) error // Checks the parsed configuration in validate mode, optional
+}
+
+// CommandTasksFunc builds the tasks a command runs. It is called with the launcher
+// context (carrying the logger) after all flags have been parsed, and receives the
+// positional arguments left after the flags.
+type CommandTasksFunc func(ctx context.Context, args []string) ([]goture.Task, error)
+
+// CommandRegistry dispatches a binary to one of its registered commands.
+// The command line has the form "<program> <command> [flags] [args]", where flags
+// are the shared flags plus the flags of the selected command. The all-in-one
+// command accepts the flags of every command it runs.
+//
+// Example:
+//
+//	var cfg Config
+//	commands := launcher.NewCommandRegistry("app").
+//		WithSharedFlags(func(fs *flag.FlagSet) {
+//			fs.StringVar(&cfg.RedisURL, "redis-url", "redis://localhost:6379", "Redis connection URL")
+//		}).
+//		Register(launcher.Command{Name: "serve", Description: "Run the backoffice API", Tasks: serveTasks}).
+//		Register(launcher.Command{Name: "consume", Description: "Consume Binance ticks", Tasks: consumeTasks}).
+//		Register(launcher.Command{Name: "migrate", Description: "Apply migrations", Standalone: true, Tasks: migrateTasks})
+//
+//	launcher.NewAppLauncher().
+//		WithLoggerContext(logger).
+//		WithGrexitContext().
+//		WaitCommand(commands, os.Args[1:]).
+//		LogIfError(logger, "Application stopped")
+type CommandRegistry struct {
+	program     string
+	allInOne    string
+	commands    []Command
+	names       map[string]struct{}
+	sharedFlags []func(*flag.FlagSet)
+	output      io.Writer
+	err         error // First registration error, reported by WaitCommand
+}
+
+// NewCommandRegistry creates an empty registry for the given program name,
+// which is used in the help output.
+func NewCommandRegistry(program string) *CommandRegistry {
+	return &CommandRegistry{
+		program:  program,
+		allInOne: DefaultAllInOneCommand,
+		names:    make(map[string]struct{}),
+		output:   os.Stderr,
+	}
+}
+
+// Register adds a command to the registry.
+// Configuration errors (empty, reserved or duplicate name, nil tasks) are reported by WaitCommand.
+// Returns the same registry instance for method chaining (fluent API).
+func (r *CommandRegistry) Register(command Command) *CommandRegistry {
+	if r.err != nil {
+		return r
+	}
+
+	switch {
+	case command.Name == "":
+		r.err = errors.New("command name cannot be empty")
+	case command.Name == "help" || command.Name == r.allInOne:
+		r.err = fmt.Errorf("command name %q is reserved", command.Name)
+	case command.Tasks == nil:
+		r.err = fmt.Errorf("command %q: tasks cannot be nil", command.Name)
+	default:
+		if _, exists := r.names[command.Name]; exists {
+			r.err = fmt.Errorf("command %q is already registered", command.Name)
+			break
+		}
+		r.names[command.Name] = struct{}{}
+		r.commands = append(r.commands, command)
+	}
+	return r
+}
+
+// WithSharedFlags defines flags accepted by every command, such as config paths or log format.
+// Returns the same registry instance for method chaining (fluent API).
+func (r *CommandRegistry) WithSharedFlags(define func(*flag.FlagSet)) *CommandRegistry {
+	if define != nil {
+		r.sharedFlags = append(r.sharedFlags, define)
+	}
+	return r
+}
+
+// WithAllInOne renames the command that runs every registered command together.
+// An empty name disables it.
+// Returns the same registry instance for method chaining (fluent API).
+func (r *CommandRegistry) WithAllInOne(name string) *CommandRegistry {
+	r.allInOne = name
+	return r
+}
+
+// WithOutput sets the writer for help and usage output, os.Stderr by default.
+// Returns the same registry instance for method chaining (fluent API).
+func (r *CommandRegistry) WithOutput(output io.Writer) *CommandRegistry {
+	r.output = output
+	return r
+}
+
+// WaitCommand selects the command named by the first argument, parses its flags,
+// builds its tasks with the launcher context and waits for their completion.
+// "help", "-h" and "--help" print the help output and return an empty successful result;
+// a missing or unknown command prints the usage and returns an error.
+// Every command accepts the --validate flag (see WithValidateMode): the Validate function
+// of the selected commands is called instead of Tasks, and nothing is started.
+// Returns AppResult which can be used to check for errors and log them if needed.
+func (a *AppLauncher) WaitCommand(registry *CommandRegistry, args []string) *AppResult {
+	if registry == nil {
+		return &AppResult{Err: errors.New("command registry cannot be nil")}
+	}
+	if registry.err != nil {
+		return &AppResult{Err: registry.err}
+	}
+	if len(registry.commands) == 0 {
+		return &AppResult{Err: errors.New("at least one command must be registered")}
+	}
+
+	if len(args) == 0 {
+		registry.printUsage()
+		return &AppResult{Err: errors.New("no command specified")}
+	}
+
+	name, args := args[0], args[1:]
+	switch name {
+	case "help", "-h", "-help", "--help":
+		if len(args) > 0 {
+			if selected, ok := registry.selectCommands(args[0]); ok {
+				registry.newFlagSet(a, args[0], selected).Usage()
+				return &AppResult{}
+			}
+		}
+		registry.printUsage()
+		return &AppResult{}
+	}
+
+	selected, ok := registry.selectCommands(name)
+	if !ok {
+		registry.printUsage()
+		return &AppResult{Err: fmt.Errorf("unknown command %q", name)}
+	}
+
+	flags := registry.newFlagSet(a, name, selected)
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return &AppResult{}
+		}
+		return &AppResult{Err: fmt.Errorf("command %q: %w", name, err)}
+	}
+
+	if a.validateMode {
+		return a.validateCommands(selected, flags.Args())
+	}
+
+	var tasks []namedTask
+	for _, command := range selected {
+		commandTasks, err := command.Tasks(a.Context, flags.Args())
+		if err != nil {
+			return &AppResult{Err: fmt.Errorf("command %q: %w", command.Name, err)}
+		}
+		for i, task := range commandTasks {
+			if task == nil {
+				return &AppResult{Err: fmt.Errorf("command %q: task %d cannot be nil", command.Name, i)}
+			}
+			taskName := command.Name
+			if len(commandTasks) > 1 {
+				taskName = fmt.Sprintf("%s-%d", command.Name, i)
+			}
+			tasks = append(tasks, namedTask{name: taskName, task: task})
+		}
+	}
+	if len(tasks) == 0 {
+		return &AppResult{Err: fmt.Errorf("command %q has no tasks to run", name)}
+	}
+
+	return a.runTasks(tasks)
+}
+
+// selectCommands returns the commands run by the given command name.
+func (r *CommandRegistry) selectCommands(name string) ([]Command, bool) {
+	if r.allInOne != "" && name == r.allInOne {
+		var selected []Command
+		for _, command := range r.commands {
+			if !command.Standalone {
+				selected = append(selected, command)
+			}
+		}
+		return selected, true
+	}
+	for _, command := range r.commands {
+		if command.Name == name {
+			return []Command{command}, true
+		}
+	}
+	return nil, false
+}
+
+// newFlagSet builds the flag set of the given command from the shared flags and
+// the flags of every selected command. A flag defined by several commands is set for all of them.
+func (r *CommandRegistry) newFlagSet(a *AppLauncher, name string, selected []Command) *flag.FlagSet {
+	flags := flag.NewFlagSet(r.program+" "+name, flag.ContinueOnError)
+	flags.SetOutput(r.output)
+
+	for _, define := range r.sharedFlags {
+		define(flags)
+	}
+	if flags.Lookup(ValidateFlag) == nil {
+		flags.Var(validateModeFlag{a}, ValidateFlag, "Check the configuration and wiring, then exit without starting anything")
+	}
+
+	for _, command := range selected {
+		if command.Flags == nil {
+			continue
+		}
+		commandFlags := flag.NewFlagSet(command.Name, flag.ContinueOnError)
+		command.Flags(commandFlags)
+		commandFlags.VisitAll(func(f *flag.Flag) {
+			if existing := flags.Lookup(f.Name); existing != nil {
+				// Only the value is replaced, DefValue and Usage stay those of the first definition
+				if fanOut, ok := existing.Value.(fanOutFlagValue); ok {
+					existing.Value = append(fanOut, f.Value)
+				} else {
+					existing.Value = fanOutFlagValue{existing.Value, f.Value}
+				}
+				return
+			}
+			flags.Var(f.Value, f.Name, f.Usage)
+		})
+	}
+
+	description := r.allInOneDescription(selected)
+	if len(selected) == 1 && selected[0].Name == name {
+		description = selected[0].Description
+	}
+
+	flags.Usage = func() {
+		fmt.Fprintf(r.output, "Usage: %s %s [flags] [args]\n", r.program, name)
+		if description != "" {
+			fmt.Fprintf(r.output, "\n%s\n", description)
+		}
+		hasFlags := false
+		flags.VisitAll(func(*flag.Flag) { hasFlags = true })
+		if hasFlags {
+			fmt.Fprintf(r.output, "\nFlags:\n")
+			printFlagDefaults(r.output, flags)
+		}
+	}
+	return flags
+}
+
+// allInOneDescription describes the all-in-one command by listing the commands it runs.
+func (r *CommandRegistry) allInOneDescription(selected []Command) string {
+	names := make([]string, len(selected))
+	for i, command := range selected {
+		names[i] = command.Name
+	}
+	return "Run every command in one process: " + strings.Join(names, ", ")
+}
+
+// printUsage prints the list of available commands.
+func (r *CommandRegistry) printUsage() {
+	fmt.Fprintf(r.output, "Usage: %s <command> [flags] [args]\n\nCommands:\n", r.program)
+
+	writer := tabwriter.NewWriter(r.output, 0, 0, 2, ' ', 0)
+	for _, command := range r.commands {
+		fmt.Fprintf(writer, "  %s\t%s\n", command.Name, command.Description)
+	}
+	if r.allInOne != "" {
+		fmt.Fprintf(writer, "  %s\t%s\n", r.allInOne, "Run every command in one process")
+	}
+	fmt.Fprintf(writer, "  %s\t%s\n", "help", "Show help for a command")
+	_ = writer.Flush()
+
+	fmt.Fprintf(r.output, "\nRun '%s help <command>' for the flags of a command.\n", r.program)
+}
+
+// fanOutFlagValue sets a flag shared by several commands on all of them.
+type fanOutFlagValue []flag.Value
+
+// String returns the value of the first definition. The flag package calls it on
+// the zero value to detect default values, so an empty value must not panic.
+func (v fanOutFlagValue) String() string {
+	if len(v) == 0 {
+		return ""
+	}
+	return v[0].String()
+}
+
+// Set sets the value on every definition.
+func (v fanOutFlagValue) Set(value string) error {
+	for _, target := range v {
+		if err := target.Set(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsBoolFlag reports whether the flag can be used without a value.
+func (v fanOutFlagValue) IsBoolFlag() bool {
+	if len(v) == 0 {
+		return false
+	}
+	boolFlag, ok := v[0].(interface{ IsBoolFlag() bool })
+	return ok && boolFlag.IsBoolFlag()
+}
+
+// printFlagDefaults prints the flags like flag.PrintDefaults, showing a flag shared by
+// several commands with the type of its first definition instead of a generic "value".
+func printFlagDefaults(output io.Writer, flags *flag.FlagSet) {
+	view := flag.NewFlagSet(flags.Name(), flag.ContinueOnError)
+	view.SetOutput(output)
+	flags.VisitAll(func(f *flag.Flag) {
+		value := f.Value
+		if fanOut, ok := value.(fanOutFlagValue); ok && len(fanOut) > 0 {
+			value = fanOut[0]
+		}
+		view.Var(value, f.Name, f.Usage)
+		view.Lookup(f.Name).DefValue = f.DefValue
+	})
+	view.PrintDefaults()
+}
+
+// validateModeFlag is the built-in --validate flag, which enables the validate mode of the launcher.
+type validateModeFlag struct {
+	launcher *AppLauncher
+}
+
+// String returns the current mode.
+func (f validateModeFlag) String() string {
+	if f.launcher == nil {
+		return "false"
+	}
+	return strconv.FormatBool(f.launcher.validateMode)
+}
+
+// Set enables or disables the validate mode.
+func (f validateModeFlag) Set(value string) error {
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+	f.launcher.validateMode = enabled
+	return nil
+}
+
+// IsBoolFlag reports that the flag can be used without a value.
+func (f validateModeFlag) IsBoolFlag() bool {
+	return true
+}
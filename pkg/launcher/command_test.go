@@ -0,0 +1,227 @@
+package launcher
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/goregion/goture"
+)
+
+// commandRecorder records which commands ran and with which flag values
+type commandRecorder struct {
+	mu       sync.Mutex
+	ran      []string
+	addr     string
+	workers  int
+	verbose  bool
+	leftover []string
+}
+
+func (r *commandRecorder) registry(output *bytes.Buffer) *CommandRegistry {
+	tasks := func(name string, count int) CommandTasksFunc {
+		return func(ctx context.Context, args []string) ([]goture.Task, error) {
+			r.leftover = args
+			result := make([]goture.Task, count)
+			for i := range result {
+				result[i] = func(ctx context.Context) error {
+					r.mu.Lock()
+					defer r.mu.Unlock()
+					r.ran = append(r.ran, name)
+					return nil
+				}
+			}
+			return result, nil
+		}
+	}
+
+	return NewCommandRegistry("app").
+		WithOutput(output).
+		WithSharedFlags(func(fs *flag.FlagSet) {
+			fs.BoolVar(&r.verbose, "verbose", false, "Verbose output")
+		}).
+		Register(Command{
+			Name:        "serve",
+			Description: "Run the HTTP API",
+			Flags: func(fs *flag.FlagSet) {
+				fs.StringVar(&r.addr, "addr", ":8080", "Listen address")
+			},
+			Tasks: tasks("serve", 1),
+		}).
+		Register(Command{
+			Name:        "consume",
+			Description: "Consume ticks",
+			Flags: func(fs *flag.FlagSet) {
+				fs.IntVar(&r.workers, "workers", 1, "Number of workers")
+			},
+			Tasks: tasks("consume", 2),
+		}).
+		Register(Command{
+			Name:        "migrate",
+			Description: "Apply migrations",
+			Standalone:  true,
+			Tasks:       tasks("migrate", 1),
+		})
+}
+
+// TestWaitCommandSingle tests running one command with shared and command flags
+func TestWaitCommandSingle(t *testing.T) {
+	var output bytes.Buffer
+	recorder := &commandRecorder{}
+
+	result := NewAppLauncher().WaitCommand(recorder.registry(&output), []string{"consume", "-verbose", "-workers", "4", "extra"})
+
+	if result.Error() != nil {
+		t.Fatalf("Expected no error, got: %v", result.Error())
+	}
+	if len(recorder.ran) != 2 || recorder.ran[0] != "consume" {
+		t.Errorf("Expected consume tasks to run, got %v", recorder.ran)
+	}
+	if !recorder.verbose || recorder.workers != 4 {
+		t.Errorf("Flags were not parsed: verbose=%v workers=%d", recorder.verbose, recorder.workers)
+	}
+	if len(recorder.leftover) != 1 || recorder.leftover[0] != "extra" {
+		t.Errorf("Expected positional args to be passed through, got %v", recorder.leftover)
+	}
+	if result.Tasks[0].Name != "consume-0" || result.Tasks[1].Name != "consume-1" {
+		t.Errorf("Unexpected task names: %+v", result.Tasks)
+	}
+}
+
+// TestWaitCommandAllInOne tests that all-in-one runs every non-standalone command
+func TestWaitCommandAllInOne(t *testing.T) {
+	var output bytes.Buffer
+	recorder := &commandRecorder{}
+
+	result := NewAppLauncher().WaitCommand(recorder.registry(&output), []string{"all-in-one", "-addr", ":9090", "-workers", "2"})
+
+	if result.Error() != nil {
+		t.Fatalf("Expected no error, got: %v", result.Error())
+	}
+	ran := strings.Join(recorder.ran, ",")
+	if strings.Contains(ran, "migrate") || !strings.Contains(ran, "serve") || !strings.Contains(ran, "consume") {
+		t.Errorf("Unexpected commands ran: %v", recorder.ran)
+	}
+	if recorder.addr != ":9090" || recorder.workers != 2 {
+		t.Errorf("Flags of all commands must be accepted: addr=%s workers=%d", recorder.addr, recorder.workers)
+	}
+	if len(result.Tasks) != 3 || result.Tasks[0].Name != "serve" {
+		t.Errorf("Unexpected task outcomes: %+v", result.Tasks)
+	}
+}
+
+// TestWaitCommandHelp tests the help output
+func TestWaitCommandHelp(t *testing.T) {
+	var output bytes.Buffer
+	recorder := &commandRecorder{}
+	registry := recorder.registry(&output)
+
+	if result := NewAppLauncher().WaitCommand(registry, []string{"help"}); result.Error() != nil || len(result.Tasks) != 0 {
+		t.Errorf("Expected help to succeed without running tasks, got %v", result.Error())
+	}
+	for _, expected := range []string{"Usage: app <command>", "serve", "Run the HTTP API", "migrate", "all-in-one"} {
+		if !strings.Contains(output.String(), expected) {
+			t.Errorf("Expected help output to contain %q, got:\n%s", expected, output.String())
+		}
+	}
+
+	output.Reset()
+	NewAppLauncher().WaitCommand(registry, []string{"serve", "-h"})
+	for _, expected := range []string{"Usage: app serve", "-addr", "-verbose"} {
+		if !strings.Contains(output.String(), expected) {
+			t.Errorf("Expected command help to contain %q, got:\n%s", expected, output.String())
+		}
+	}
+	if len(recorder.ran) != 0 {
+		t.Errorf("Help must not run tasks, got %v", recorder.ran)
+	}
+}
+
+// TestWaitCommandErrors tests usage and registration errors
+func TestWaitCommandErrors(t *testing.T) {
+	var output bytes.Buffer
+	recorder := &commandRecorder{}
+	noTasks := func(ctx context.Context, args []string) ([]goture.Task, error) { return nil, nil }
+
+	tests := map[string]struct {
+		registry *CommandRegistry
+		args     []string
+	}{
+		"no command":      {recorder.registry(&output), nil},
+		"unknown command": {recorder.registry(&output), []string{"deploy"}},
+		"unknown flag":    {recorder.registry(&output), []string{"serve", "-workers", "2"}},
+		"reserved name":   {NewCommandRegistry("app").Register(Command{Name: "help", Tasks: noTasks}), []string{"help"}},
+		"duplicate":       {NewCommandRegistry("app").Register(Command{Name: "a", Tasks: noTasks}).Register(Command{Name: "a", Tasks: noTasks}), []string{"a"}},
+		"nil tasks":       {NewCommandRegistry("app").Register(Command{Name: "a"}), []string{"a"}},
+		"empty tasks":     {NewCommandRegistry("app").WithOutput(&output).Register(Command{Name: "a", Tasks: noTasks}), []string{"a"}},
+		"setup failure": {NewCommandRegistry("app").Register(Command{Name: "a", Tasks: func(ctx context.Context, args []string) ([]goture.Task, error) {
+			return nil, errors.New("missing config")
+		}}), []string{"a"}},
+	}
+
+	for name, tt := range tests {
+		if result := NewAppLauncher().WaitCommand(tt.registry, tt.args); result.Error() == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+	if len(recorder.ran) != 0 {
+		t.Errorf("No task must run on errors, got %v", recorder.ran)
+	}
+}
+
+// TestWaitCommandHelpSharedFlag tests the help output of a flag defined by several commands
+func TestWaitCommandHelpSharedFlag(t *testing.T) {
+	var output bytes.Buffer
+	var serveRedis, consumeRedis string
+	var serveTimeout, consumeTimeout time.Duration
+	noop := func(ctx context.Context, args []string) ([]goture.Task, error) {
+		return []goture.Task{func(ctx context.Context) error { return nil }}, nil
+	}
+	registry := NewCommandRegistry("app").
+		WithOutput(&output).
+		WithAllInOne("all-in-one").
+		Register(Command{
+			Name: "serve",
+			Flags: func(fs *flag.FlagSet) {
+				fs.StringVar(&serveRedis, "redis", "localhost:6379", "Redis `address`")
+				fs.DurationVar(&serveTimeout, "timeout", time.Second, "Request timeout")
+			},
+			Tasks: noop,
+		}).
+		Register(Command{
+			Name: "consume",
+			Flags: func(fs *flag.FlagSet) {
+				fs.StringVar(&consumeRedis, "redis", "localhost:6379", "Redis address of the consumer")
+				fs.DurationVar(&consumeTimeout, "timeout", 2*time.Second, "Consumer timeout")
+			},
+			Tasks: noop,
+		})
+
+	NewAppLauncher().WaitCommand(registry, []string{"help", "all-in-one"})
+
+	help := output.String()
+	if strings.Contains(help, "panic") {
+		t.Fatalf("Expected help without panics, got:\n%s", help)
+	}
+	for _, expected := range []string{
+		"-redis address",
+		"Redis address (default \"localhost:6379\")",
+		"-timeout duration",
+		"Request timeout (default 1s)",
+	} {
+		if !strings.Contains(help, expected) {
+			t.Errorf("Expected help to contain %q, got:\n%s", expected, help)
+		}
+	}
+
+	output.Reset()
+	result := NewAppLauncher().WaitCommand(registry, []string{"all-in-one", "-redis", "redis:6380"})
+	if result.Error() != nil || serveRedis != "redis:6380" || consumeRedis != "redis:6380" {
+		t.Errorf("Expected the shared flag to be set for both commands, got %q and %q: %v", serveRedis, consumeRedis, result.Error())
+	}
+}
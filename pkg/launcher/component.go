@@ -0,0 +1,270 @@
+// Package launcher provides a component registry with dependency-ordered lifecycle.
+// This file contains the Component and ComponentRegistry types that start named
+// components in dependency order and stop them in reverse order.
+package launcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/goregion/hexago/pkg/log"
+)
+
+// DefaultComponentStopTimeout is the stop timeout used for components
+// that do not declare their own StopTimeout.
+const DefaultComponentStopTimeout = 10 * time.Second
+
+// ErrDependencyCycle is returned when component or task dependencies form a cycle.
+var ErrDependencyCycle = errors.New("dependency cycle")
+
+// Component describes a named application part with an explicit lifecycle.
+// Start must return once the component is operational; long-running work
+// should be spawned by Start and stopped by Stop.
+type Component struct {
+	Name        string                          // Unique component name
+	Start       func(ctx context.Context) error // Brings the component up, required
+	Stop        func(ctx context.Context) error // Tears the component down, optional
+	DependsOn   []string                        // Names of components that must start first
+	StopTimeout time.Duration                   // Per-component stop timeout, defaults to DefaultComponentStopTimeout
+	Validate    func() error                    // Checks the component configuration without connecting to anything, optional
+}
+
+// ComponentRegistry holds components and manages their lifecycle.
+// Components are started in dependency order and stopped in reverse order.
+//
+// Example:
+//
+//	registry := launcher.NewComponentRegistry().
+//		Register(launcher.Component{Name: "db", Start: db.Open, Stop: db.Close}).
+//		Register(launcher.Component{Name: "redis", Start: redis.Connect, Stop: redis.Close}).
+//		Register(launcher.Component{Name: "consumer", Start: consumer.Start, Stop: consumer.Stop, DependsOn: []string{"db", "redis"}}).
+//		Register(launcher.Component{Name: "http", Start: server.Start, Stop: server.Shutdown, DependsOn: []string{"consumer"}})
+//
+//	launcher.NewAppLauncher().
+//		WithLoggerContext(logger).
+//		WithGrexitContext().
+//		WaitComponents(registry)
+type ComponentRegistry struct {
+	components map[string]Component
+	order      []string // Registration order, used for deterministic start order
+	err        error    // First registration error, reported by StartOrder and Run
+}
+
+// NewComponentRegistry creates an empty component registry.
+func NewComponentRegistry() *ComponentRegistry {
+	return &ComponentRegistry{
+		components: make(map[string]Component),
+	}
+}
+
+// Register adds a component to the registry.
+// Registration errors (empty or duplicate name, missing Start) are reported by StartOrder and Run.
+// Returns the same registry instance for method chaining (fluent API).
+func (r *ComponentRegistry) Register(component Component) *ComponentRegistry {
+	if r.err != nil {
+		return r
+	}
+
+	switch {
+	case component.Name == "":
+		r.err = errors.New("component name cannot be empty")
+	case component.Start == nil:
+		r.err = fmt.Errorf("component %q must have a Start function", component.Name)
+	default:
+		if _, exists := r.components[component.Name]; exists {
+			r.err = fmt.Errorf("component %q is already registered", component.Name)
+			break
+		}
+		r.components[component.Name] = component
+		r.order = append(r.order, component.Name)
+	}
+	return r
+}
+
+// StartOrder resolves the dependency graph and returns component names in start order.
+// Components without mutual dependencies keep their registration order.
+// Returns an error for missing dependencies or dependency cycles.
+func (r *ComponentRegistry) StartOrder() ([]string, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	dependsOn := make(map[string][]string, len(r.components))
+	for name, component := range r.components {
+		dependsOn[name] = component.DependsOn
+	}
+	return dependencyOrder("component", r.order, dependsOn)
+}
+
+// dependencyOrder sorts names so that every name comes after its dependencies.
+// Names without mutual dependencies keep their relative order. The kind
+// ("component", "task") is used in error messages.
+func dependencyOrder(kind string, order []string, dependsOn map[string][]string) ([]string, error) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	var (
+		state  = make(map[string]int, len(order))
+		result = make([]string, 0, len(order))
+		path   []string
+		visit  func(name string) error
+	)
+
+	visit = func(name string) error {
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			start := 0
+			for i, n := range path {
+				if n == name {
+					start = i
+					break
+				}
+			}
+			cycle := append(append([]string{}, path[start:]...), name)
+			return fmt.Errorf("%s %w: %s", kind, ErrDependencyCycle, strings.Join(cycle, " -> "))
+		}
+
+		state[name] = visiting
+		path = append(path, name)
+		for _, dep := range dependsOn[name] {
+			if _, exists := dependsOn[dep]; !exists {
+				return fmt.Errorf("%s %q depends on unknown %s %q", kind, name, kind, dep)
+			}
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+		result = append(result, name)
+		return nil
+	}
+
+	for _, name := range order {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// Run validates all components, starts them in dependency order, blocks until the context
+// is done and then stops started components in reverse order. If a component fails to start,
+// the components started before it are stopped and the start error is returned.
+// Run has the goture.Task signature, so a registry can be passed anywhere a task is expected.
+func (r *ComponentRegistry) Run(ctx context.Context) error {
+	order, err := r.StartOrder()
+	if err != nil {
+		return err
+	}
+	if len(order) == 0 {
+		return errors.New("at least one component must be registered")
+	}
+
+	var validateErrs []error
+	for _, name := range order {
+		if validate := r.components[name].Validate; validate != nil {
+			if err := validate(); err != nil {
+				validateErrs = append(validateErrs, fmt.Errorf("component %q is invalid: %w", name, err))
+			}
+		}
+	}
+	if len(validateErrs) > 0 {
+		return errors.Join(validateErrs...)
+	}
+
+	logger, _ := log.GetLoggerFromContext(ctx)
+
+	started := make([]Component, 0, len(order))
+	var startErr error
+	for _, name := range order {
+		component := r.components[name]
+		if err := component.Start(ctx); err != nil {
+			startErr = fmt.Errorf("component %q failed to start: %w", name, err)
+			break
+		}
+		if logger != nil {
+			logger.Info("component started", "component", name)
+		}
+		started = append(started, component)
+	}
+
+	if startErr == nil {
+		<-ctx.Done()
+	}
+
+	stopErr := r.stopAll(context.WithoutCancel(ctx), started, logger)
+	if startErr != nil {
+		return errors.Join(startErr, stopErr)
+	}
+	return stopErr
+}
+
+// stopAll stops the given components in reverse order, each with its own timeout.
+// All components are stopped even if some of them fail, and the errors are joined.
+func (r *ComponentRegistry) stopAll(ctx context.Context, started []Component, logger *log.Logger) error {
+	var errs []error
+	for i := len(started) - 1; i >= 0; i-- {
+		component := started[i]
+		if component.Stop == nil {
+			continue
+		}
+
+		timeout := component.StopTimeout
+		if timeout <= 0 {
+			timeout = DefaultComponentStopTimeout
+		}
+
+		stopCtx, cancel := context.WithTimeout(ctx, timeout)
+		err := callWithContext(stopCtx, component.Stop)
+		cancel()
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("component %q failed to stop: %w", component.Name, err))
+			continue
+		}
+		if logger != nil {
+			logger.Info("component stopped", "component", component.Name)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// callWithContext runs fn and returns when it finishes or the context expires,
+// so a function that ignores its context cannot block the caller forever.
+func callWithContext(ctx context.Context, fn func(ctx context.Context) error) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- fn(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// WaitComponents starts the registered components with the enriched launcher context,
+// waits until the context is done and stops the components in reverse order.
+// It returns after all components have been stopped or the shutdown deadline has passed.
+// Returns AppResult which can be used to check for errors and log them if needed.
+func (a *AppLauncher) WaitComponents(registry *ComponentRegistry) *AppResult {
+	if registry == nil {
+		return &AppResult{Err: errors.New("component registry cannot be nil")}
+	}
+	if a.validateMode {
+		return a.validateComponents(registry)
+	}
+
+	return a.runTasks([]namedTask{{name: "components", task: registry.Run}})
+}
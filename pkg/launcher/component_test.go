@@ -0,0 +1,185 @@
+package launcher
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// lifecycleRecorder records component start and stop events in order
+type lifecycleRecorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *lifecycleRecorder) record(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *lifecycleRecorder) component(name string, deps ...string) Component {
+	return Component{
+		Name:      name,
+		DependsOn: deps,
+		Start: func(ctx context.Context) error {
+			r.record("start:" + name)
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			r.record("stop:" + name)
+			return nil
+		},
+	}
+}
+
+// TestComponentStartOrder tests dependency resolution
+func TestComponentStartOrder(t *testing.T) {
+	rec := &lifecycleRecorder{}
+	registry := NewComponentRegistry().
+		Register(rec.component("http", "consumer")).
+		Register(rec.component("consumer", "db", "redis")).
+		Register(rec.component("redis")).
+		Register(rec.component("db"))
+
+	order, err := registry.StartOrder()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{"db", "redis", "consumer", "http"}
+	if !reflect.DeepEqual(order, expected) {
+		t.Errorf("Expected order %v, got %v", expected, order)
+	}
+}
+
+// TestComponentGraphErrors tests cycle and missing dependency detection
+func TestComponentGraphErrors(t *testing.T) {
+	rec := &lifecycleRecorder{}
+
+	t.Run("cycle", func(t *testing.T) {
+		_, err := NewComponentRegistry().
+			Register(rec.component("a", "b")).
+			Register(rec.component("b", "c")).
+			Register(rec.component("c", "a")).
+			StartOrder()
+
+		if !errors.Is(err, ErrDependencyCycle) {
+			t.Fatalf("Expected ErrDependencyCycle, got: %v", err)
+		}
+		if got := err.Error(); got != "component dependency cycle: a -> b -> c -> a" {
+			t.Errorf("Unexpected cycle description: %s", got)
+		}
+	})
+
+	t.Run("missing dependency", func(t *testing.T) {
+		_, err := NewComponentRegistry().
+			Register(rec.component("a", "missing")).
+			StartOrder()
+		if err == nil {
+			t.Error("Expected error for missing dependency")
+		}
+	})
+
+	t.Run("registration errors", func(t *testing.T) {
+		registries := map[string]*ComponentRegistry{
+			"empty name": NewComponentRegistry().Register(Component{Start: func(context.Context) error { return nil }}),
+			"no start":   NewComponentRegistry().Register(Component{Name: "a"}),
+			"duplicate":  NewComponentRegistry().Register(rec.component("a")).Register(rec.component("a")),
+		}
+		for name, registry := range registries {
+			if _, err := registry.StartOrder(); err == nil {
+				t.Errorf("%s: expected registration error", name)
+			}
+		}
+	})
+}
+
+// TestWaitComponentsLifecycle tests ordered startup and reverse-order shutdown
+func TestWaitComponentsLifecycle(t *testing.T) {
+	rec := &lifecycleRecorder{}
+	registry := NewComponentRegistry().
+		Register(rec.component("http", "consumer")).
+		Register(rec.component("consumer", "db")).
+		Register(rec.component("db"))
+
+	launcher := NewAppLauncher()
+	time.AfterFunc(20*time.Millisecond, launcher.Shutdown)
+
+	result := launcher.WaitComponents(registry)
+	if result.Error() != nil {
+		t.Fatalf("Expected clean shutdown, got: %v", result.Error())
+	}
+
+	expected := []string{
+		"start:db", "start:consumer", "start:http",
+		"stop:http", "stop:consumer", "stop:db",
+	}
+	if !reflect.DeepEqual(rec.events, expected) {
+		t.Errorf("Expected events %v, got %v", expected, rec.events)
+	}
+}
+
+// TestWaitComponentsStartFailure tests that started components are stopped when a later one fails
+func TestWaitComponentsStartFailure(t *testing.T) {
+	rec := &lifecycleRecorder{}
+	startErr := errors.New("connection refused")
+
+	broken := rec.component("redis", "db")
+	broken.Start = func(ctx context.Context) error { return startErr }
+
+	registry := NewComponentRegistry().
+		Register(rec.component("db")).
+		Register(broken).
+		Register(rec.component("http", "redis"))
+
+	result := NewAppLauncher().WithTimeout(time.Second).WaitComponents(registry)
+
+	if !errors.Is(result.Error(), startErr) {
+		t.Errorf("Expected start error, got: %v", result.Error())
+	}
+
+	expected := []string{"start:db", "stop:db"}
+	if !reflect.DeepEqual(rec.events, expected) {
+		t.Errorf("Expected events %v, got %v", expected, rec.events)
+	}
+}
+
+// TestWaitComponentsStopTimeout tests that a hanging Stop is bounded by its timeout
+func TestWaitComponentsStopTimeout(t *testing.T) {
+	rec := &lifecycleRecorder{}
+
+	hanging := rec.component("hanging")
+	hanging.StopTimeout = 20 * time.Millisecond
+	hanging.Stop = func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	}
+
+	registry := NewComponentRegistry().
+		Register(rec.component("db")).
+		Register(hanging)
+
+	start := time.Now()
+	result := NewAppLauncher().
+		WithTimeout(10 * time.Millisecond).
+		WaitComponents(registry)
+	duration := time.Since(start)
+
+	if !errors.Is(result.Error(), context.DeadlineExceeded) {
+		t.Errorf("Expected stop timeout error, got: %v", result.Error())
+	}
+	if duration > 200*time.Millisecond {
+		t.Errorf("Stop timeout was not enforced, took %v", duration)
+	}
+	if rec.events[len(rec.events)-1] != "stop:db" {
+		t.Errorf("Expected remaining components to be stopped, got %v", rec.events)
+	}
+
+	if result := NewAppLauncher().WaitComponents(nil); result.Error() == nil {
+		t.Error("Expected error for nil registry")
+	}
+}
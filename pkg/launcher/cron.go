@@ -0,0 +1,275 @@
+// Package launcher provides schedules for periodic tasks.
+// This file contains the Schedule interface, a fixed-interval schedule and
+// a parser for standard five-field cron expressions with timezone support.
+package launcher
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule computes activation times of a scheduled task.
+type Schedule interface {
+	// Next returns the first activation time strictly after the given time,
+	// or the zero time if there is none.
+	Next(after time.Time) time.Time
+}
+
+// intervalSchedule activates at a fixed interval.
+type intervalSchedule struct {
+	interval time.Duration
+}
+
+// Every returns a schedule that activates every interval.
+func Every(interval time.Duration) Schedule {
+	return intervalSchedule{interval: interval}
+}
+
+// Next returns the time one interval after the given time.
+func (s intervalSchedule) Next(after time.Time) time.Time {
+	return after.Add(s.interval)
+}
+
+// cronField is a bitset of allowed values for a single cron field.
+type cronField uint64
+
+// has reports whether the value is allowed.
+func (f cronField) has(value int) bool {
+	return f&(1<<uint(value)) != 0
+}
+
+// cronBounds describes the allowed range and value names of a cron field.
+type cronBounds struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteBounds = cronBounds{name: "minute", min: 0, max: 59}
+	hourBounds   = cronBounds{name: "hour", min: 0, max: 23}
+	domBounds    = cronBounds{name: "day of month", min: 1, max: 31}
+	monthBounds  = cronBounds{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	dowBounds = cronBounds{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// cronMacros maps the supported shorthand expressions to their five-field form.
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// CronSchedule is a parsed cron expression bound to a timezone.
+type CronSchedule struct {
+	minute, hour, dom, month, dow cronField
+	domRestricted, dowRestricted  bool
+	location                      *time.Location
+}
+
+// ParseCron parses a standard five-field cron expression
+// ("minute hour day-of-month month day-of-week") evaluated in the given location.
+// Fields support lists, ranges, steps and month/weekday names, e.g. "*/5 9-18 * * MON-FRI".
+// The macros @yearly, @monthly, @weekly, @daily and @hourly are also accepted, and the
+// expression may be prefixed with "CRON_TZ=<zone> " to override the location.
+// A nil location means time.Local.
+func ParseCron(expr string, location *time.Location) (*CronSchedule, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, errors.New("cron expression cannot be empty")
+	}
+
+	if strings.HasPrefix(expr, "CRON_TZ=") || strings.HasPrefix(expr, "TZ=") {
+		zone, rest, _ := strings.Cut(expr, " ")
+		_, name, _ := strings.Cut(zone, "=")
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			return nil, fmt.Errorf("cron expression %q: invalid timezone: %w", expr, err)
+		}
+		location = loc
+		expr = strings.TrimSpace(rest)
+	}
+	if location == nil {
+		location = time.Local
+	}
+
+	if macro, ok := cronMacros[strings.ToLower(expr)]; ok {
+		expr = macro
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression %q: expected 5 fields, got %d", expr, len(fields))
+	}
+
+	// As in Vixie cron, a day field starting with "*" (e.g. "*/2") is not restricted
+	schedule := &CronSchedule{
+		location:      location,
+		domRestricted: !strings.HasPrefix(fields[2], "*") && fields[2] != "?",
+		dowRestricted: !strings.HasPrefix(fields[4], "*") && fields[4] != "?",
+	}
+
+	targets := []struct {
+		field  *cronField
+		bounds cronBounds
+	}{
+		{&schedule.minute, minuteBounds},
+		{&schedule.hour, hourBounds},
+		{&schedule.dom, domBounds},
+		{&schedule.month, monthBounds},
+		{&schedule.dow, dowBounds},
+	}
+	for i, target := range targets {
+		value, err := parseCronField(fields[i], target.bounds)
+		if err != nil {
+			return nil, fmt.Errorf("cron expression %q: %w", expr, err)
+		}
+		*target.field = value
+	}
+
+	// Sunday may be written as 0 or 7
+	if schedule.dow.has(7) {
+		schedule.dow |= 1
+	}
+	return schedule, nil
+}
+
+// parseCronField parses a comma-separated list of cron ranges.
+func parseCronField(field string, bounds cronBounds) (cronField, error) {
+	var result cronField
+	for _, part := range strings.Split(field, ",") {
+		bits, err := parseCronRange(part, bounds)
+		if err != nil {
+			return 0, err
+		}
+		result |= bits
+	}
+	return result, nil
+}
+
+// parseCronRange parses a single "*", "a", "a-b" term with an optional "/step".
+func parseCronRange(term string, bounds cronBounds) (cronField, error) {
+	rangePart, stepPart, hasStep := strings.Cut(term, "/")
+
+	step := 1
+	if hasStep {
+		var err error
+		if step, err = strconv.Atoi(stepPart); err != nil || step <= 0 {
+			return 0, fmt.Errorf("invalid step %q in %s field", stepPart, bounds.name)
+		}
+	}
+
+	var low, high int
+	switch {
+	case rangePart == "*" || rangePart == "?":
+		low, high = bounds.min, bounds.max
+	case strings.Contains(rangePart, "-"):
+		lowPart, highPart, _ := strings.Cut(rangePart, "-")
+		var err error
+		if low, err = parseCronValue(lowPart, bounds); err != nil {
+			return 0, err
+		}
+		if high, err = parseCronValue(highPart, bounds); err != nil {
+			return 0, err
+		}
+	default:
+		var err error
+		if low, err = parseCronValue(rangePart, bounds); err != nil {
+			return 0, err
+		}
+		high = low
+		if hasStep {
+			high = bounds.max
+		}
+	}
+
+	if low > high {
+		return 0, fmt.Errorf("invalid range %q in %s field", rangePart, bounds.name)
+	}
+
+	var bits cronField
+	for value := low; value <= high; value += step {
+		bits |= 1 << uint(value)
+	}
+	return bits, nil
+}
+
+// parseCronValue parses a single numeric or named value and checks its bounds.
+func parseCronValue(value string, bounds cronBounds) (int, error) {
+	if named, ok := bounds.names[strings.ToLower(value)]; ok {
+		return named, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q in %s field", value, bounds.name)
+	}
+	if number < bounds.min || number > bounds.max {
+		return 0, fmt.Errorf("value %d out of range [%d-%d] in %s field", number, bounds.min, bounds.max, bounds.name)
+	}
+	return number, nil
+}
+
+// cronSearchYears bounds the search for the next activation, so that
+// impossible expressions such as "0 0 30 2 *" do not loop forever.
+const cronSearchYears = 5
+
+// Next returns the first minute strictly after the given time that matches the expression.
+// The result is expressed in the schedule location.
+func (s *CronSchedule) Next(after time.Time) time.Time {
+	t := after.In(s.location)
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, s.location).Add(time.Minute)
+	yearLimit := t.Year() + cronSearchYears
+
+wrap:
+	for t.Year() <= yearLimit {
+		for !s.month.has(int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.location)
+			if t.Month() == time.January {
+				continue wrap
+			}
+		}
+		for !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.location)
+			if t.Day() == 1 {
+				continue wrap
+			}
+		}
+		for !s.hour.has(t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, s.location)
+			if t.Hour() == 0 {
+				continue wrap
+			}
+		}
+		for !s.minute.has(t.Minute()) {
+			t = t.Add(time.Minute)
+			if t.Minute() == 0 {
+				continue wrap
+			}
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches applies the cron rule for day fields: when both day of month and
+// day of week are restricted (do not start with "*"), a day matches if either of them matches.
+func (s *CronSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom.has(t.Day())
+	dowMatch := s.dow.has(int(t.Weekday()))
+	if s.domRestricted && s.dowRestricted {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
@@ -0,0 +1,101 @@
+package launcher
+
+import (
+	"testing"
+	"time"
+)
+
+// TestParseCronNext tests activation times of common cron expressions
+func TestParseCronNext(t *testing.T) {
+	from := time.Date(2024, time.January, 31, 10, 17, 30, 0, time.UTC) // Wednesday
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2024, time.January, 31, 10, 18, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, time.January, 31, 10, 30, 0, 0, time.UTC)},
+		{"0 9-18 * * MON-FRI", time.Date(2024, time.January, 31, 11, 0, 0, 0, time.UTC)},
+		{"30 2 * * sun", time.Date(2024, time.February, 4, 2, 30, 0, 0, time.UTC)},
+		{"0 0 29 feb *", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1,15 * *", time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 13 * 5", time.Date(2024, time.February, 2, 0, 0, 0, 0, time.UTC)},  // day of month OR Friday
+		{"0 0 */2 * 1", time.Date(2024, time.February, 5, 0, 0, 0, 0, time.UTC)}, // odd day of month AND Monday
+		{"0 0 * * 7", time.Date(2024, time.February, 4, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2024, time.January, 31, 11, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			schedule, err := ParseCron(tt.expr, time.UTC)
+			if err != nil {
+				t.Fatalf("Unexpected parse error: %v", err)
+			}
+			if got := schedule.Next(from); !got.Equal(tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestParseCronLocation tests that expressions are evaluated in their timezone
+func TestParseCronLocation(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Skipf("Timezone database not available: %v", err)
+	}
+
+	from := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+	want := time.Date(2024, time.June, 1, 6, 0, 0, 0, time.UTC) // 09:00 MSK
+
+	fromLocation, err := ParseCron("0 9 * * *", moscow)
+	if err != nil {
+		t.Fatalf("Unexpected parse error: %v", err)
+	}
+	fromPrefix, err := ParseCron("CRON_TZ=Europe/Moscow 0 9 * * *", time.UTC)
+	if err != nil {
+		t.Fatalf("Unexpected parse error: %v", err)
+	}
+
+	for _, schedule := range []*CronSchedule{fromLocation, fromPrefix} {
+		if got := schedule.Next(from); !got.Equal(want) {
+			t.Errorf("Expected %v, got %v", want, got)
+		}
+	}
+}
+
+// TestParseCronErrors tests rejection of malformed expressions
+func TestParseCronErrors(t *testing.T) {
+	invalid := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"CRON_TZ=Mars/Olympus 0 0 * * *",
+	}
+
+	for _, expr := range invalid {
+		if _, err := ParseCron(expr, time.UTC); err == nil {
+			t.Errorf("Expected error for %q", expr)
+		}
+	}
+}
+
+// TestParseCronImpossible tests that an expression without activations returns the zero time
+func TestParseCronImpossible(t *testing.T) {
+	schedule, err := ParseCron("0 0 30 feb *", time.UTC)
+	if err != nil {
+		t.Fatalf("Unexpected parse error: %v", err)
+	}
+	if next := schedule.Next(time.Now()); !next.IsZero() {
+		t.Errorf("Expected no activation, got %v", next)
+	}
+}
@@ -0,0 +1,183 @@
+// Package launcher provides runtime diagnostics on the admin server.
+// This file contains the pprof, goroutine dump, GC stats and build info endpoints
+// and the goroutine stack dump logged when the shutdown deadline is exceeded.
+package launcher
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+// DiagnosticsConfig toggles the runtime diagnostics endpoints of the admin server.
+// It can be parsed from the environment with config.ParseEnv or embedded in an
+// application config.
+type DiagnosticsConfig struct {
+	EnablePprof bool `env:"ENABLE_PPROF" yaml:"enable_pprof"` // Mount pprof and the runtime diagnostics endpoints
+}
+
+// gcReport is the /debug/gc response body.
+type gcReport struct {
+	NumGC          int64     `json:"num_gc"`
+	LastGC         time.Time `json:"last_gc"`
+	PauseTotalMs   float64   `json:"pause_total_ms"`
+	RecentPausesMs []float64 `json:"recent_pauses_ms"` // Most recent first
+	HeapAllocBytes uint64    `json:"heap_alloc_bytes"`
+	HeapSysBytes   uint64    `json:"heap_sys_bytes"`
+	HeapObjects    uint64    `json:"heap_objects"`
+	NextGCBytes    uint64    `json:"next_gc_bytes"`
+	Goroutines     int       `json:"goroutines"`
+	GOMAXPROCS     int       `json:"gomaxprocs"`
+}
+
+// moduleReport describes a module in the /debug/buildinfo response body.
+type moduleReport struct {
+	Path    string        `json:"path"`
+	Version string        `json:"version"`
+	Sum     string        `json:"sum,omitempty"`
+	Replace *moduleReport `json:"replace,omitempty"`
+}
+
+// buildReport is the /debug/buildinfo response body.
+type buildReport struct {
+	GoVersion string            `json:"go_version"`
+	Path      string            `json:"path"`
+	Main      moduleReport      `json:"main"`
+	Settings  map[string]string `json:"settings"` // Build flags and VCS info, e.g. vcs.revision
+	Deps      []moduleReport    `json:"deps"`
+}
+
+// WithDiagnostics mounts runtime diagnostics on the admin server when cfg.EnablePprof is set:
+//   - /debug/pprof/     - the net/http/pprof profiles,
+//   - /debug/goroutines - a text dump of every goroutine stack,
+//   - /debug/gc         - GC and heap statistics as JSON,
+//   - /debug/buildinfo  - Go version, module versions and VCS info as JSON.
+//
+// The endpoints expose internals of the process, so the admin listener
+// must not be reachable from outside the cluster when they are enabled.
+// Returns the same launcher instance for method chaining (fluent API).
+//
+// Example:
+//
+//	diagnostics, err := config.ParseEnv[launcher.DiagnosticsConfig]() // reads ENABLE_PPROF
+//	if err != nil {
+//		return err
+//	}
+//
+//	launcher.NewAppLauncher().
+//		WithAdminServer(":8081").
+//		WithDiagnostics(*diagnostics).
+//		WaitApplication(server.Run)
+func (a *AppLauncher) WithDiagnostics(cfg DiagnosticsConfig) *AppLauncher {
+	if !cfg.EnablePprof {
+		return a
+	}
+	return a.
+		HandleAdmin("/debug/pprof/", http.HandlerFunc(pprof.Index)).
+		HandleAdmin("/debug/pprof/cmdline", http.HandlerFunc(pprof.Cmdline)).
+		HandleAdmin("/debug/pprof/profile", http.HandlerFunc(pprof.Profile)).
+		HandleAdmin("/debug/pprof/symbol", http.HandlerFunc(pprof.Symbol)).
+		HandleAdmin("/debug/pprof/trace", http.HandlerFunc(pprof.Trace)).
+		HandleAdmin("GET /debug/goroutines", http.HandlerFunc(handleGoroutines)).
+		HandleAdmin("GET /debug/gc", http.HandlerFunc(handleGCStats)).
+		HandleAdmin("GET /debug/buildinfo", http.HandlerFunc(handleBuildInfo))
+}
+
+// WithStackDumpOnDeadline logs the stack of every goroutine when the shutdown deadline
+// (see WithGracefulShutdown) is exceeded, to show where the remaining tasks are stuck.
+// Returns the same launcher instance for method chaining (fluent API).
+func (a *AppLauncher) WithStackDumpOnDeadline() *AppLauncher {
+	a.stackDump = true
+	return a
+}
+
+// logStackDump logs the stack of every goroutine through the launcher logger.
+func (a *AppLauncher) logStackDump() {
+	if logger := a.logger(); logger != nil {
+		logger.Error("goroutine dump",
+			"goroutines", runtime.NumGoroutine(),
+			"stacks", string(allStacks()),
+		)
+	}
+}
+
+// allStacks returns the stack traces of all goroutines.
+func allStacks() []byte {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+// handleGoroutines writes the stack of every goroutine as plain text.
+func handleGoroutines(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write(allStacks())
+}
+
+// handleGCStats reports GC and heap statistics.
+func handleGCStats(w http.ResponseWriter, r *http.Request) {
+	var gc debug.GCStats
+	debug.ReadGCStats(&gc)
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	recent := gc.Pause[:min(len(gc.Pause), 16)]
+	pauses := make([]float64, 0, len(recent))
+	for _, pause := range recent {
+		pauses = append(pauses, float64(pause)/float64(time.Millisecond))
+	}
+
+	writeJSON(w, http.StatusOK, gcReport{
+		NumGC:          gc.NumGC,
+		LastGC:         gc.LastGC,
+		PauseTotalMs:   float64(gc.PauseTotal) / float64(time.Millisecond),
+		RecentPausesMs: pauses,
+		HeapAllocBytes: mem.HeapAlloc,
+		HeapSysBytes:   mem.HeapSys,
+		HeapObjects:    mem.HeapObjects,
+		NextGCBytes:    mem.NextGC,
+		Goroutines:     runtime.NumGoroutine(),
+		GOMAXPROCS:     runtime.GOMAXPROCS(0),
+	})
+}
+
+// handleBuildInfo reports the build information embedded in the binary.
+func handleBuildInfo(w http.ResponseWriter, r *http.Request) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "build info is not available"})
+		return
+	}
+
+	report := buildReport{
+		GoVersion: info.GoVersion,
+		Path:      info.Path,
+		Main:      newModuleReport(&info.Main),
+		Settings:  make(map[string]string, len(info.Settings)),
+		Deps:      make([]moduleReport, 0, len(info.Deps)),
+	}
+	for _, setting := range info.Settings {
+		report.Settings[setting.Key] = setting.Value
+	}
+	for _, dep := range info.Deps {
+		report.Deps = append(report.Deps, newModuleReport(dep))
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// newModuleReport converts a module of the build info.
+func newModuleReport(module *debug.Module) moduleReport {
+	report := moduleReport{Path: module.Path, Version: module.Version, Sum: module.Sum}
+	if module.Replace != nil {
+		replace := newModuleReport(module.Replace)
+		report.Replace = &replace
+	}
+	return report
+}
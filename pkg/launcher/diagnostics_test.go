@@ -0,0 +1,121 @@
+package launcher
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goregion/hexago/pkg/config"
+	"github.com/goregion/hexago/pkg/log"
+)
+
+// startAdmin runs a launcher with a single blocking task and returns the admin base URL
+func startAdmin(t *testing.T, launcher *AppLauncher) string {
+	t.Helper()
+
+	done := make(chan *AppResult, 1)
+	go func() {
+		done <- launcher.WaitApplication(func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		})
+	}()
+	t.Cleanup(func() {
+		launcher.Shutdown()
+		<-done
+	})
+
+	waitUntil(t, time.Second, func() bool { return launcher.AdminAddr() != "" })
+	return "http://" + launcher.AdminAddr()
+}
+
+// get performs a GET request and returns the status code and body
+func get(t *testing.T, url string) (int, string) {
+	t.Helper()
+
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", url, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
+
+// TestDiagnosticsEndpoints tests the endpoints mounted when diagnostics are enabled
+func TestDiagnosticsEndpoints(t *testing.T) {
+	t.Setenv("ENABLE_PPROF", "true")
+	cfg, err := config.ParseEnv[DiagnosticsConfig]()
+	if err != nil || !cfg.EnablePprof {
+		t.Fatalf("Expected ENABLE_PPROF to enable diagnostics, got %+v, %v", cfg, err)
+	}
+
+	base := startAdmin(t, NewAppLauncher().
+		WithGracefulShutdown(0, time.Second).
+		WithAdminServer("127.0.0.1:0").
+		WithDiagnostics(*cfg))
+
+	for path, expected := range map[string]string{
+		"/debug/pprof/":                  "goroutine",
+		"/debug/pprof/goroutine?debug=1": "goroutine profile:",
+		"/debug/pprof/cmdline":           "launcher.test",
+		"/debug/goroutines":              "goroutine ",
+		"/debug/gc":                      `"heap_alloc_bytes":`,
+		"/debug/buildinfo":               `"go_version":"go`,
+	} {
+		status, body := get(t, base+path)
+		if status != http.StatusOK || !strings.Contains(body, expected) {
+			t.Errorf("Unexpected %s response: %d, expected body to contain %q", path, status, expected)
+		}
+	}
+}
+
+// TestDiagnosticsDisabled tests that nothing is mounted when diagnostics are disabled
+func TestDiagnosticsDisabled(t *testing.T) {
+	base := startAdmin(t, NewAppLauncher().
+		WithGracefulShutdown(0, time.Second).
+		WithAdminServer("127.0.0.1:0").
+		WithDiagnostics(DiagnosticsConfig{}))
+
+	for _, path := range []string{"/debug/pprof/", "/debug/goroutines", "/debug/gc", "/debug/buildinfo"} {
+		if status, _ := get(t, base+path); status != http.StatusNotFound {
+			t.Errorf("Expected %s to be disabled, got status %d", path, status)
+		}
+	}
+}
+
+// stuckInShutdown blocks past the shutdown deadline, its name must appear in the stack dump
+func stuckInShutdown(ctx context.Context) error {
+	<-ctx.Done()
+	time.Sleep(200 * time.Millisecond)
+	return nil
+}
+
+// TestStackDumpOnDeadline tests that goroutine stacks are logged when the deadline is exceeded
+func TestStackDumpOnDeadline(t *testing.T) {
+	var logOutput bytes.Buffer
+	logger := log.NewLogger(log.NewJsonHandler(&logOutput))
+
+	launcher := NewAppLauncher().
+		WithLoggerContext(logger).
+		WithGracefulShutdown(0, 20*time.Millisecond).
+		WithStackDumpOnDeadline()
+
+	time.AfterFunc(10*time.Millisecond, launcher.Shutdown)
+	result := launcher.WaitApplication(stuckInShutdown)
+
+	if !errors.Is(result.Error(), ErrShutdownDeadlineExceeded) {
+		t.Fatalf("Expected ErrShutdownDeadlineExceeded, got: %v", result.Error())
+	}
+	output := logOutput.String()
+	for _, expected := range []string{`"msg":"goroutine dump"`, `"goroutines":`, "stuckInShutdown"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected log output to contain %s, got: %s", expected, output)
+		}
+	}
+}
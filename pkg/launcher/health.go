@@ -0,0 +1,224 @@
+// Package launcher provides a health-check registry for launched applications.
+// This file contains the HealthRegistry type that runs named probes with
+// per-check timeouts and result caching, and reports their status and latency.
+package launcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultHealthCheckTimeout is the timeout used for checks that do not declare their own.
+const DefaultHealthCheckTimeout = 2 * time.Second
+
+// Health check statuses reported by HealthRegistry.
+const (
+	HealthStatusOK   = "ok"
+	HealthStatusFail = "fail"
+)
+
+// HealthChecker is implemented by adapters that can probe their dependency,
+// such as redis.Client and sqlgen_db.Client.
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// HealthCheckFunc adapts a plain function to the HealthChecker interface.
+type HealthCheckFunc func(ctx context.Context) error
+
+// HealthCheck calls the function itself.
+func (f HealthCheckFunc) HealthCheck(ctx context.Context) error {
+	return f(ctx)
+}
+
+// HealthCheckOptions configures how a single check is executed.
+type HealthCheckOptions struct {
+	Timeout  time.Duration // Maximum duration of a single probe, defaults to DefaultHealthCheckTimeout
+	CacheTTL time.Duration // How long a probe result is reused, zero disables caching
+}
+
+// HealthCheckResult is the outcome of a single check.
+type HealthCheckResult struct {
+	Name      string    `json:"name"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	LatencyMs float64   `json:"latency_ms"`
+	CheckedAt time.Time `json:"checked_at"`
+	Cached    bool      `json:"cached"`
+}
+
+// HealthReport aggregates the results of all registered checks.
+type HealthReport struct {
+	Status string              `json:"status"`
+	Checks []HealthCheckResult `json:"checks"`
+}
+
+// Healthy reports whether every check in the report has passed.
+func (r HealthReport) Healthy() bool {
+	return r.Status == HealthStatusOK
+}
+
+// healthCheck is a registered check together with its cached result.
+type healthCheck struct {
+	name    string
+	checker HealthChecker
+	options HealthCheckOptions
+
+	mu   sync.Mutex
+	last *HealthCheckResult
+}
+
+// HealthRegistry holds named health checks and runs them on demand.
+// It is safe for concurrent use; checks can be registered while the application is running.
+//
+// Example:
+//
+//	registry := launcher.NewHealthRegistry().
+//		Register("redis", redisClient, launcher.HealthCheckOptions{Timeout: time.Second}).
+//		Register("postgres", dbClient, launcher.HealthCheckOptions{CacheTTL: 5 * time.Second})
+//
+//	report := registry.Check(ctx)
+type HealthRegistry struct {
+	mu     sync.RWMutex
+	checks []*healthCheck
+	names  map[string]struct{}
+	err    error // First registration error, reported by Err
+}
+
+// NewHealthRegistry creates an empty health-check registry.
+func NewHealthRegistry() *HealthRegistry {
+	return &HealthRegistry{
+		names: make(map[string]struct{}),
+	}
+}
+
+// Register adds a named check to the registry.
+// Registration errors (empty or duplicate name, nil checker) are reported by Err.
+// Returns the same registry instance for method chaining (fluent API).
+func (r *HealthRegistry) Register(name string, checker HealthChecker, options HealthCheckOptions) *HealthRegistry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return r
+	}
+
+	switch {
+	case name == "":
+		r.err = errors.New("health check name cannot be empty")
+	case checker == nil:
+		r.err = fmt.Errorf("health check %q cannot be nil", name)
+	default:
+		if _, exists := r.names[name]; exists {
+			r.err = fmt.Errorf("health check %q is already registered", name)
+			break
+		}
+		if options.Timeout <= 0 {
+			options.Timeout = DefaultHealthCheckTimeout
+		}
+		r.names[name] = struct{}{}
+		r.checks = append(r.checks, &healthCheck{name: name, checker: checker, options: options})
+	}
+	return r
+}
+
+// Err returns the first registration error, if any.
+func (r *HealthRegistry) Err() error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.err
+}
+
+// Len returns the number of registered checks.
+func (r *HealthRegistry) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.checks)
+}
+
+// Check runs all registered checks in parallel and returns the aggregated report.
+// Results are listed in registration order.
+func (r *HealthRegistry) Check(ctx context.Context) HealthReport {
+	r.mu.RLock()
+	checks := append([]*healthCheck(nil), r.checks...)
+	r.mu.RUnlock()
+
+	report := HealthReport{
+		Status: HealthStatusOK,
+		Checks: make([]HealthCheckResult, len(checks)),
+	}
+
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check *healthCheck) {
+			defer wg.Done()
+			report.Checks[i] = check.run(ctx)
+		}(i, check)
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status != HealthStatusOK {
+			report.Status = HealthStatusFail
+			break
+		}
+	}
+	return report
+}
+
+// run executes the check or returns the cached result if it is still fresh.
+// The mutex also guarantees that a slow probe is not executed concurrently.
+// A result is not cached when the caller's context ended during the probe,
+// since the failure says nothing about the dependency.
+func (c *healthCheck) run(ctx context.Context) HealthCheckResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.last != nil && c.options.CacheTTL > 0 && time.Since(c.last.CheckedAt) < c.options.CacheTTL {
+		cached := *c.last
+		cached.Cached = true
+		return cached
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, c.options.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := callWithContext(probeCtx, c.checker.HealthCheck)
+
+	result := HealthCheckResult{
+		Name:      c.name,
+		Status:    HealthStatusOK,
+		LatencyMs: float64(time.Since(start)) / float64(time.Millisecond),
+		CheckedAt: start,
+	}
+	if err != nil {
+		result.Status = HealthStatusFail
+		result.Error = err.Error()
+	}
+
+	if ctx.Err() == nil {
+		c.last = &result
+	}
+	return result
+}
+
+// WithHealthCheck registers a named check in the launcher health registry.
+// The checks are exposed by the admin server on /healthz and /readyz.
+// A registration error (empty or duplicate name, nil checker) fails the launch
+// before any task starts and is reported as a "health" check in validate mode.
+// Returns the same launcher instance for method chaining (fluent API).
+func (a *AppLauncher) WithHealthCheck(name string, checker HealthChecker, options HealthCheckOptions) *AppLauncher {
+	a.health.Register(name, checker, options)
+	return a
+}
+
+// HealthRegistry returns the launcher health registry, so that adapters
+// can register their checks after the launcher has been configured.
+func (a *AppLauncher) HealthRegistry() *HealthRegistry {
+	return a.health
+}
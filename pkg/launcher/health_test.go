@@ -0,0 +1,147 @@
+package launcher
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestHealthRegistryCheck tests aggregated status and per-check results
+func TestHealthRegistryCheck(t *testing.T) {
+	registry := NewHealthRegistry().
+		Register("ok", HealthCheckFunc(func(ctx context.Context) error { return nil }), HealthCheckOptions{}).
+		Register("broken", HealthCheckFunc(func(ctx context.Context) error { return errors.New("connection refused") }), HealthCheckOptions{})
+
+	report := registry.Check(context.Background())
+
+	if report.Healthy() {
+		t.Error("Expected report to be unhealthy")
+	}
+	if len(report.Checks) != 2 {
+		t.Fatalf("Expected 2 check results, got %d", len(report.Checks))
+	}
+	if report.Checks[0].Name != "ok" || report.Checks[0].Status != HealthStatusOK {
+		t.Errorf("Unexpected first result: %+v", report.Checks[0])
+	}
+	if report.Checks[1].Status != HealthStatusFail || report.Checks[1].Error != "connection refused" {
+		t.Errorf("Unexpected second result: %+v", report.Checks[1])
+	}
+}
+
+// TestHealthRegistryTimeout tests that slow checks fail after their timeout
+func TestHealthRegistryTimeout(t *testing.T) {
+	slow := HealthCheckFunc(func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+
+	start := time.Now()
+	report := NewHealthRegistry().
+		Register("slow", slow, HealthCheckOptions{Timeout: 20 * time.Millisecond}).
+		Check(context.Background())
+
+	if report.Healthy() {
+		t.Error("Expected slow check to fail")
+	}
+	if duration := time.Since(start); duration > 200*time.Millisecond {
+		t.Errorf("Check timeout was not enforced, took %v", duration)
+	}
+}
+
+// TestHealthRegistryCache tests that results are reused within the cache TTL
+func TestHealthRegistryCache(t *testing.T) {
+	var calls int32
+	check := HealthCheckFunc(func(ctx context.Context) error {
+		atomic.AddInt32(&calls, 1)
+		return nil
+	})
+
+	registry := NewHealthRegistry().Register("cached", check, HealthCheckOptions{CacheTTL: 50 * time.Millisecond})
+
+	first := registry.Check(context.Background())
+	second := registry.Check(context.Background())
+
+	if first.Checks[0].Cached || !second.Checks[0].Cached {
+		t.Errorf("Expected only the second result to be cached: %+v, %+v", first.Checks[0], second.Checks[0])
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("Expected 1 probe call, got %d", got)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	registry.Check(context.Background())
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("Expected cache to expire, got %d probe calls", got)
+	}
+}
+
+// TestHealthRegistryCacheCanceled tests that a probe interrupted by the caller is not cached
+func TestHealthRegistryCacheCanceled(t *testing.T) {
+	var calls int32
+	started := make(chan struct{})
+	check := HealthCheckFunc(func(ctx context.Context) error {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return nil
+	})
+
+	registry := NewHealthRegistry().Register("cached", check, HealthCheckOptions{CacheTTL: time.Minute})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+	if report := registry.Check(ctx); report.Healthy() {
+		t.Error("Expected the canceled probe to fail")
+	}
+
+	report := registry.Check(context.Background())
+	if !report.Healthy() || report.Checks[0].Cached {
+		t.Errorf("Expected a fresh successful probe, got: %+v", report.Checks[0])
+	}
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("Expected 2 probe calls, got %d", got)
+	}
+}
+
+// TestHealthRegistryRegistrationErrors tests registration validation
+func TestHealthRegistryRegistrationErrors(t *testing.T) {
+	check := HealthCheckFunc(func(ctx context.Context) error { return nil })
+
+	registries := map[string]*HealthRegistry{
+		"empty name": NewHealthRegistry().Register("", check, HealthCheckOptions{}),
+		"nil check":  NewHealthRegistry().Register("nil", nil, HealthCheckOptions{}),
+		"duplicate":  NewHealthRegistry().Register("a", check, HealthCheckOptions{}).Register("a", check, HealthCheckOptions{}),
+	}
+	for name, registry := range registries {
+		if registry.Err() == nil {
+			t.Errorf("%s: expected registration error", name)
+		}
+	}
+}
+
+// TestHealthRegistrationErrorFailsLaunch tests that a broken registration fails the launch before any task starts
+func TestHealthRegistrationErrorFailsLaunch(t *testing.T) {
+	var started atomic.Int32
+	result := NewAppLauncher().
+		WithHealthCheck("redis", HealthCheckFunc(func(ctx context.Context) error { return nil }), HealthCheckOptions{}).
+		WithHealthCheck("redis", HealthCheckFunc(func(ctx context.Context) error { return nil }), HealthCheckOptions{}).
+		WaitApplication(func(ctx context.Context) error {
+			started.Add(1)
+			return nil
+		})
+
+	if err := result.Error(); err == nil || !strings.Contains(err.Error(), `health check "redis" is already registered`) {
+		t.Errorf("Expected the registration error, got: %v", err)
+	}
+	if started.Load() != 0 {
+		t.Error("Expected no task to be started")
+	}
+}
@@ -0,0 +1,232 @@
+// Package launchertest provides utilities for testing launcher-based applications.
+// This file contains the Harness that runs an AppLauncher in the background, drives
+// its lifecycle from the test and checks that it shuts down cleanly.
+package launchertest
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goregion/hexago/pkg/launcher"
+	"github.com/goregion/hexago/pkg/log"
+)
+
+const (
+	// DefaultReadyTimeout is the default time the application has to become ready.
+	DefaultReadyTimeout = 5 * time.Second
+	// DefaultStopDeadline is the default time the application has to stop after a shutdown.
+	DefaultStopDeadline = 5 * time.Second
+	// DefaultLeakTimeout is the default time goroutines have to exit after the application stopped.
+	DefaultLeakTimeout = time.Second
+)
+
+// pollInterval is the interval between checks of a waited condition.
+const pollInterval = 5 * time.Millisecond
+
+// Harness runs an AppLauncher in the background of a test.
+// It captures every record logged through the launcher context logger, lets the test
+// simulate SIGTERM and SIGHUP, and fails the test if the application does not stop
+// within the stop deadline, if a task is abandoned at shutdown, or if goroutines
+// started while the application was running are still alive after it stopped.
+// An application still running when the test ends is stopped and checked the same way.
+//
+// Example:
+//
+//	func TestService(t *testing.T) {
+//		app := launcher.NewAppLauncher().WithGracefulShutdown(0, time.Second)
+//		h := launchertest.New(t, app).
+//			Start(func(app *launcher.AppLauncher) *launcher.AppResult {
+//				return app.WaitApplications(consumer.Run, server.Run)
+//			}).
+//			WaitReady()
+//
+//		h.Reload()
+//		h.WaitForLog("reload hook succeeded")
+//
+//		if result := h.Stop(); result.Error() != nil {
+//			t.Fatal(result.Error())
+//		}
+//	}
+type Harness struct {
+	t            testing.TB
+	app          *launcher.AppLauncher
+	logs         *LogRecorder
+	readyTimeout time.Duration
+	stopDeadline time.Duration
+	leakTimeout  time.Duration
+	ignored      []string
+	baseline     map[int64]string         // Goroutines that existed before Start
+	done         chan *launcher.AppResult // Receives the result once the launcher returns
+	exited       chan struct{}            // Closed once the launcher returns
+	result       *launcher.AppResult      // Result of the launcher, nil until Wait succeeds
+}
+
+// New creates a harness for the launcher. The launcher is not started until Start.
+func New(t testing.TB, app *launcher.AppLauncher) *Harness {
+	return &Harness{
+		t:            t,
+		app:          app,
+		logs:         NewLogRecorder(),
+		readyTimeout: DefaultReadyTimeout,
+		stopDeadline: DefaultStopDeadline,
+		leakTimeout:  DefaultLeakTimeout,
+		ignored:      defaultIgnoredGoroutines,
+	}
+}
+
+// WithReadyTimeout sets the time WaitReady and WaitForLog wait.
+// Returns the same harness instance for method chaining (fluent API).
+func (h *Harness) WithReadyTimeout(timeout time.Duration) *Harness {
+	h.readyTimeout = timeout
+	return h
+}
+
+// WithStopDeadline sets the time the application has to stop once Wait is called.
+// Returns the same harness instance for method chaining (fluent API).
+func (h *Harness) WithStopDeadline(deadline time.Duration) *Harness {
+	h.stopDeadline = deadline
+	return h
+}
+
+// WithLeakTimeout sets the time goroutines have to exit after the application stopped.
+// Returns the same harness instance for method chaining (fluent API).
+func (h *Harness) WithLeakTimeout(timeout time.Duration) *Harness {
+	h.leakTimeout = timeout
+	return h
+}
+
+// IgnoreGoroutines excludes goroutines whose stack contains any of the substrings,
+// e.g. a function name, from the leak check.
+// Returns the same harness instance for method chaining (fluent API).
+func (h *Harness) IgnoreGoroutines(substrings ...string) *Harness {
+	h.ignored = append(append([]string(nil), h.ignored...), substrings...)
+	return h
+}
+
+// Start replaces the launcher logger with one that records into Logs and runs
+// wait, typically a Wait* call on the launcher, in the background.
+// Returns the same harness instance for method chaining (fluent API).
+func (h *Harness) Start(wait func(app *launcher.AppLauncher) *launcher.AppResult) *Harness {
+	h.t.Helper()
+	if h.done != nil {
+		h.t.Fatal("launchertest: harness already started")
+	}
+
+	h.app.WithLoggerContext(log.NewLogger(h.logs.Handler()))
+	h.baseline = goroutineStacks()
+	h.done = make(chan *launcher.AppResult, 1)
+	h.exited = make(chan struct{})
+
+	go func() {
+		h.done <- wait(h.app)
+		close(h.exited)
+	}()
+
+	h.t.Cleanup(func() {
+		if h.result == nil {
+			h.Stop()
+		}
+		if h.t.Failed() {
+			h.t.Logf("launchertest: captured logs:\n%s", h.logs.String())
+		}
+	})
+	return h
+}
+
+// WaitReady waits until the launcher reports ready (see launcher.AppLauncher.Ready)
+// and fails the test if it is not ready within the ready timeout or the application stops first.
+// Returns the same harness instance for method chaining (fluent API).
+func (h *Harness) WaitReady() *Harness {
+	h.t.Helper()
+	if !h.poll(h.readyTimeout, h.exited, h.app.Ready) {
+		h.t.Fatalf("launchertest: application not ready after %v", h.readyTimeout)
+	}
+	return h
+}
+
+// WaitForLog waits until a record with the given message is logged and returns the
+// first one. It fails the test if no such record is logged within the ready timeout.
+func (h *Harness) WaitForLog(message string) LogRecord {
+	h.t.Helper()
+	if !h.poll(h.readyTimeout, h.exited, func() bool { return h.logs.Contains(message) }) {
+		h.t.Fatalf("launchertest: no %q log record after %v", message, h.readyTimeout)
+	}
+	return h.logs.Find(message)[0]
+}
+
+// Logs returns the recorder capturing the launcher logs.
+func (h *Harness) Logs() *LogRecorder {
+	return h.logs
+}
+
+// Shutdown simulates SIGTERM: it starts the shutdown sequence of the launcher.
+// A second call forces the exit, as a second signal would.
+func (h *Harness) Shutdown() {
+	h.app.Shutdown()
+}
+
+// Reload simulates SIGHUP: it runs the reload hooks of the launcher.
+func (h *Harness) Reload() {
+	h.app.Reload()
+}
+
+// Stop simulates SIGTERM and waits for the application to stop (see Wait).
+func (h *Harness) Stop() *launcher.AppResult {
+	h.t.Helper()
+	h.Shutdown()
+	return h.Wait()
+}
+
+// Wait waits for the application to return and returns its result. It fails the test
+// if the application does not return within the stop deadline, if a task was still
+// running when it returned, or if goroutines started after Start leak.
+func (h *Harness) Wait() *launcher.AppResult {
+	h.t.Helper()
+	if h.result != nil {
+		return h.result
+	}
+	if h.done == nil {
+		h.t.Fatal("launchertest: harness not started")
+	}
+
+	select {
+	case h.result = <-h.done:
+	case <-time.After(h.stopDeadline):
+		h.t.Fatalf("launchertest: application did not stop within %v, goroutines:\n\n%s",
+			h.stopDeadline, strings.Join(leakedGoroutines(h.baseline, h.ignored), "\n\n"))
+	}
+
+	for _, outcome := range h.result.Tasks {
+		if outcome.EndedAt.IsZero() {
+			h.t.Errorf("launchertest: task %q was still running when the application stopped", outcome.Name)
+		}
+	}
+
+	var leaked []string
+	h.poll(h.leakTimeout, nil, func() bool {
+		leaked = leakedGoroutines(h.baseline, h.ignored)
+		return len(leaked) == 0
+	})
+	if len(leaked) > 0 {
+		h.t.Errorf("launchertest: %d goroutines leaked after shutdown:\n\n%s",
+			len(leaked), strings.Join(leaked, "\n\n"))
+	}
+	return h.result
+}
+
+// poll checks the condition until it holds or the timeout expires.
+// It gives up early once abort is closed, e.g. when the application returned.
+func (h *Harness) poll(timeout time.Duration, abort <-chan struct{}, condition func() bool) bool {
+	deadline := time.After(timeout)
+	for !condition() {
+		select {
+		case <-deadline:
+			return false
+		case <-abort:
+			return condition()
+		case <-time.After(pollInterval):
+		}
+	}
+	return true
+}
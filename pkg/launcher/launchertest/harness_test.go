@@ -0,0 +1,235 @@
+package launchertest
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/goregion/hexago/pkg/launcher"
+	"github.com/goregion/hexago/pkg/log"
+)
+
+// recordingT records the failures reported by a harness instead of failing the test
+type recordingT struct {
+	testing.TB
+	mu       sync.Mutex
+	failures []string
+	cleanups []func()
+}
+
+func (r *recordingT) Helper() {}
+
+func (r *recordingT) Logf(format string, args ...any) {}
+
+func (r *recordingT) Errorf(format string, args ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingT) Fatal(args ...any) {
+	r.Errorf("%s", fmt.Sprint(args...))
+	runtime.Goexit()
+}
+
+func (r *recordingT) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+	runtime.Goexit()
+}
+
+func (r *recordingT) Failed() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.failures) > 0
+}
+
+func (r *recordingT) Cleanup(f func()) {
+	r.cleanups = append(r.cleanups, f)
+}
+
+// run calls f in its own goroutine, as the testing package does, so that Fatal can stop it,
+// then runs the registered cleanups and returns all reported failures
+func (r *recordingT) run(f func()) string {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer func() {
+			for i := len(r.cleanups) - 1; i >= 0; i-- {
+				r.cleanups[i]()
+			}
+		}()
+		f()
+	}()
+	<-done
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return strings.Join(r.failures, "\n")
+}
+
+// TestHarnessLifecycle tests readiness, reload, log capture and a clean stop
+func TestHarnessLifecycle(t *testing.T) {
+	var reloads int
+	app := launcher.NewAppLauncher().
+		WithGracefulShutdown(0, time.Second).
+		OnReload("config", func(ctx context.Context) error {
+			reloads++
+			return nil
+		})
+
+	h := New(t, app).
+		Start(func(app *launcher.AppLauncher) *launcher.AppResult {
+			return app.WaitApplication(func(ctx context.Context) error {
+				log.MustGetLoggerFromContext(ctx).WithGroup("consumer").Info("consuming", "stream", "ticks")
+				<-ctx.Done()
+				return nil
+			})
+		}).
+		WaitReady()
+
+	record := h.WaitForLog("consuming")
+	if record.Attrs["consumer.stream"] != "ticks" {
+		t.Errorf("Expected grouped attribute, got %v", record.Attrs)
+	}
+
+	h.Reload()
+	h.WaitForLog("reload hook succeeded")
+	if reloads != 1 {
+		t.Errorf("Expected one reload, got %d", reloads)
+	}
+
+	result := h.Stop()
+	if result.Error() != nil {
+		t.Errorf("Expected clean stop, got: %v", result.Error())
+	}
+	if !h.Logs().Contains("shutdown signal received") {
+		t.Errorf("Expected shutdown record, got:\n%s", h.Logs())
+	}
+}
+
+// TestHarnessDetectsLeak tests that goroutines outliving the application fail the test
+func TestHarnessDetectsLeak(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	rt := &recordingT{TB: t}
+	failures := rt.run(func() {
+		New(rt, launcher.NewAppLauncher()).
+			WithLeakTimeout(20 * time.Millisecond).
+			Start(func(app *launcher.AppLauncher) *launcher.AppResult {
+				return app.WaitApplication(func(ctx context.Context) error {
+					go leakyWorker(release)
+					return nil
+				})
+			}).
+			Wait()
+	})
+
+	if !strings.Contains(failures, "1 goroutines leaked") || !strings.Contains(failures, "leakyWorker") {
+		t.Errorf("Expected the leaked goroutine to be reported, got: %s", failures)
+	}
+}
+
+// leakyWorker blocks until released, its name must appear in the leak report
+func leakyWorker(release <-chan struct{}) {
+	<-release
+}
+
+// TestHarnessIgnoreGoroutines tests that ignored goroutines are not reported
+func TestHarnessIgnoreGoroutines(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	rt := &recordingT{TB: t}
+	failures := rt.run(func() {
+		New(rt, launcher.NewAppLauncher()).
+			WithLeakTimeout(20 * time.Millisecond).
+			IgnoreGoroutines("leakyWorker").
+			Start(func(app *launcher.AppLauncher) *launcher.AppResult {
+				return app.WaitApplication(func(ctx context.Context) error {
+					go leakyWorker(release)
+					return nil
+				})
+			}).
+			Wait()
+	})
+
+	if failures != "" {
+		t.Errorf("Expected no failures, got: %s", failures)
+	}
+}
+
+// TestHarnessStopDeadline tests that an application ignoring the shutdown fails the test
+func TestHarnessStopDeadline(t *testing.T) {
+	stopped := make(chan struct{})
+
+	rt := &recordingT{TB: t}
+	failures := rt.run(func() {
+		New(rt, launcher.NewAppLauncher()).
+			WithStopDeadline(30 * time.Millisecond).
+			Start(func(app *launcher.AppLauncher) *launcher.AppResult {
+				return app.WaitApplication(func(ctx context.Context) error {
+					<-ctx.Done()
+					time.Sleep(100 * time.Millisecond)
+					close(stopped)
+					return nil
+				})
+			}).
+			WaitReady().
+			Stop()
+	})
+	<-stopped
+
+	if !strings.Contains(failures, "did not stop within 30ms") {
+		t.Errorf("Expected stop deadline failure, got: %s", failures)
+	}
+}
+
+// TestHarnessAbandonedTask tests that a task abandoned at the shutdown deadline fails the test
+func TestHarnessAbandonedTask(t *testing.T) {
+	rt := &recordingT{TB: t}
+	failures := rt.run(func() {
+		New(rt, launcher.NewAppLauncher().WithGracefulShutdown(0, 10*time.Millisecond)).
+			Start(func(app *launcher.AppLauncher) *launcher.AppResult {
+				return app.WaitTasks(launcher.Task{Name: "stuck", Run: func(ctx context.Context) error {
+					<-ctx.Done()
+					time.Sleep(50 * time.Millisecond)
+					return nil
+				}})
+			}).
+			WaitReady().
+			Stop()
+	})
+
+	if !strings.Contains(failures, `task "stuck" was still running`) {
+		t.Errorf("Expected abandoned task failure, got: %s", failures)
+	}
+	if strings.Contains(failures, "leaked") {
+		t.Errorf("Expected the stuck task to finish within the leak timeout, got: %s", failures)
+	}
+}
+
+// TestHarnessCleanupStops tests that a running application is stopped when the test ends
+func TestHarnessCleanupStops(t *testing.T) {
+	var stopped bool
+	rt := &recordingT{TB: t}
+	failures := rt.run(func() {
+		New(rt, launcher.NewAppLauncher()).
+			Start(func(app *launcher.AppLauncher) *launcher.AppResult {
+				return app.WaitApplication(func(ctx context.Context) error {
+					<-ctx.Done()
+					stopped = true
+					return nil
+				})
+			}).
+			WaitReady()
+	})
+
+	if failures != "" || !stopped {
+		t.Errorf("Expected the application to be stopped by cleanup, got stopped=%v, failures: %s", stopped, failures)
+	}
+}
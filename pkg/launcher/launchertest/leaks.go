@@ -0,0 +1,73 @@
+// Package launchertest provides goroutine leak detection.
+// This file contains the goroutine snapshots used to find goroutines that
+// were started while the application was running and outlived its shutdown.
+package launchertest
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// defaultIgnoredGoroutines match goroutines that legitimately outlive an application:
+// the os/signal watcher started by the first signal.Notify call and test goroutines.
+var defaultIgnoredGoroutines = []string{
+	"os/signal.loop",
+	"os/signal.signal_recv",
+	"runtime.ensureSigM",
+	"testing.tRunner",
+}
+
+// goroutineStacks returns the stack of every goroutine by goroutine ID.
+func goroutineStacks() map[int64]string {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	stacks := make(map[int64]string)
+	for _, stack := range strings.Split(string(buf), "\n\n") {
+		var id int64
+		if _, err := fmt.Sscanf(stack, "goroutine %d ", &id); err == nil {
+			stacks[id] = stack
+		}
+	}
+	return stacks
+}
+
+// leakedGoroutines returns the stacks of goroutines that are not in the baseline
+// and do not match any of the ignored substrings, ordered by goroutine ID.
+func leakedGoroutines(baseline map[int64]string, ignored []string) []string {
+	current := goroutineStacks()
+
+	ids := make([]int64, 0, len(current))
+	for id := range current {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	var leaked []string
+	for _, id := range ids {
+		if _, existed := baseline[id]; existed || matchesAny(current[id], ignored) {
+			continue
+		}
+		leaked = append(leaked, current[id])
+	}
+	return leaked
+}
+
+// matchesAny reports whether the stack contains any of the substrings.
+func matchesAny(stack string, substrings []string) bool {
+	for _, substring := range substrings {
+		if strings.Contains(stack, substring) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,177 @@
+// Package launchertest provides utilities for testing launcher-based applications.
+// This file contains the LogRecorder that captures every record logged through
+// the launcher context logger.
+package launchertest
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LogRecord is a captured log record. Attributes of groups are flattened
+// into dotted keys, e.g. "request.id".
+type LogRecord struct {
+	Time    time.Time
+	Level   slog.Level
+	Message string
+	Attrs   map[string]any
+}
+
+// String formats the record as a single line with sorted attributes.
+func (r LogRecord) String() string {
+	keys := make([]string, 0, len(r.Attrs))
+	for key := range r.Attrs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s %q", r.Time.Format(time.TimeOnly+".000"), r.Level, r.Message)
+	for _, key := range keys {
+		fmt.Fprintf(&b, " %s=%v", key, r.Attrs[key])
+	}
+	return b.String()
+}
+
+// LogRecorder captures log records of every level in memory.
+// It is safe for concurrent use.
+type LogRecorder struct {
+	mu      sync.Mutex
+	records []LogRecord
+}
+
+// NewLogRecorder creates an empty recorder.
+func NewLogRecorder() *LogRecorder {
+	return &LogRecorder{}
+}
+
+// Handler returns a slog.Handler that records into the recorder,
+// e.g. for log.NewLogger(recorder.Handler()).
+func (r *LogRecorder) Handler() slog.Handler {
+	return &recordHandler{recorder: r}
+}
+
+// Records returns a copy of all captured records in logging order.
+func (r *LogRecorder) Records() []LogRecord {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]LogRecord(nil), r.records...)
+}
+
+// Find returns the captured records with the given message.
+func (r *LogRecorder) Find(message string) []LogRecord {
+	var found []LogRecord
+	for _, record := range r.Records() {
+		if record.Message == message {
+			found = append(found, record)
+		}
+	}
+	return found
+}
+
+// Contains reports whether a record with the given message was captured.
+func (r *LogRecorder) Contains(message string) bool {
+	return len(r.Find(message)) > 0
+}
+
+// String returns all captured records, one per line.
+func (r *LogRecorder) String() string {
+	var b strings.Builder
+	for _, record := range r.Records() {
+		b.WriteString(record.String())
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// add appends a record.
+func (r *LogRecorder) add(record LogRecord) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, record)
+}
+
+// recordHandler is the slog.Handler of a LogRecorder.
+type recordHandler struct {
+	recorder *LogRecorder
+	attrs    map[string]any // Attributes added with WithAttrs, already flattened
+	group    string         // Dotted prefix of the groups opened with WithGroup
+}
+
+// Enabled records every level.
+func (h *recordHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return true
+}
+
+// Handle captures the record together with the handler attributes.
+func (h *recordHandler) Handle(ctx context.Context, record slog.Record) error {
+	attrs := make(map[string]any, len(h.attrs)+record.NumAttrs())
+	for key, value := range h.attrs {
+		attrs[key] = value
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		flattenAttr(h.group, attr, attrs)
+		return true
+	})
+
+	h.recorder.add(LogRecord{
+		Time:    record.Time,
+		Level:   record.Level,
+		Message: record.Message,
+		Attrs:   attrs,
+	})
+	return nil
+}
+
+// WithAttrs returns a handler that adds the attributes to every record.
+func (h *recordHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	merged := make(map[string]any, len(h.attrs)+len(attrs))
+	for key, value := range h.attrs {
+		merged[key] = value
+	}
+	for _, attr := range attrs {
+		flattenAttr(h.group, attr, merged)
+	}
+	return &recordHandler{recorder: h.recorder, attrs: merged, group: h.group}
+}
+
+// WithGroup returns a handler that nests the following attributes under the group.
+func (h *recordHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &recordHandler{recorder: h.recorder, attrs: h.attrs, group: joinKey(h.group, name)}
+}
+
+// flattenAttr stores the attribute under its dotted key, resolving LogValuers
+// and expanding groups.
+func flattenAttr(prefix string, attr slog.Attr, into map[string]any) {
+	value := attr.Value.Resolve()
+	if value.Kind() == slog.KindGroup {
+		for _, member := range value.Group() {
+			flattenAttr(joinKey(prefix, attr.Key), member, into)
+		}
+		return
+	}
+	if attr.Key == "" {
+		return
+	}
+	into[joinKey(prefix, attr.Key)] = value.Any()
+}
+
+// joinKey joins a group prefix and a key with a dot.
+func joinKey(prefix, key string) string {
+	switch {
+	case prefix == "":
+		return key
+	case key == "":
+		return prefix
+	default:
+		return prefix + "." + key
+	}
+}
@@ -0,0 +1,88 @@
+// Package launcher provides leader election for singleton tasks.
+// This file contains the LeaderElector interface and the LeaderTask wrapper that
+// runs a task only while the current replica holds leadership.
+package launcher
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/goregion/goture"
+	"github.com/goregion/hexago/pkg/log"
+)
+
+// LeaderElector elects a single leader among the replicas of a service.
+// Implementations keep the leadership alive in the background (for example by
+// renewing a lease) until it is released or lost. See pkg/redis for a Redis-backed elector.
+type LeaderElector interface {
+	// Lead blocks until leadership is acquired or ctx is done.
+	// The returned context is canceled as soon as leadership is lost or ctx is done;
+	// release gives the leadership up and must be called once the leader work is finished.
+	// An error is returned only when leadership cannot be campaigned for, e.g. ctx is done.
+	Lead(ctx context.Context) (leaderCtx context.Context, release func(), err error)
+}
+
+// LeaderTask wraps a task so that it runs only while this replica is the leader.
+// The task context is canceled the moment leadership is lost; the wrapper then
+// campaigns again and restarts the task once leadership is re-acquired.
+// The wrapper returns when ctx is done (nil) or when the task returns on its own
+// while still the leader (the task result).
+//
+// Example:
+//
+//	elector := redisClient.NewLeaderElector("leader:ohlc-generator", 15*time.Second)
+//
+//	launcher.NewAppLauncher().
+//		WithLoggerContext(logger).
+//		WithGrexitContext().
+//		WaitApplications(
+//			consumer.Run,
+//			launcher.LeaderTask("ohlc-generator", elector, generator.Run),
+//		)
+func LeaderTask(name string, elector LeaderElector, task goture.Task) goture.Task {
+	return func(ctx context.Context) error {
+		if elector == nil {
+			return errors.New("leader elector cannot be nil")
+		}
+		if task == nil {
+			return errors.New("leader task cannot be nil")
+		}
+
+		logger, _ := log.GetLoggerFromContext(ctx)
+
+		for {
+			leaderCtx, release, err := elector.Lead(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return err
+			}
+
+			if logger != nil {
+				logger.Info("leadership acquired", "task", name)
+			}
+
+			startedAt := time.Now()
+			err = callTask(leaderCtx, name, task)
+			lost := leaderCtx.Err() != nil && ctx.Err() == nil
+			release()
+
+			if ctx.Err() != nil {
+				return nil
+			}
+			if !lost {
+				return err
+			}
+
+			if logger != nil {
+				logger.Warn("leadership lost, task stopped",
+					"task", name,
+					"led_for", time.Since(startedAt),
+					"error", err,
+				)
+			}
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package launcher
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeElector grants leadership on demand and revokes it when asked
+type fakeElector struct {
+	mu       sync.Mutex
+	grant    chan struct{}
+	revoke   context.CancelFunc
+	releases int32
+}
+
+func newFakeElector() *fakeElector {
+	return &fakeElector{grant: make(chan struct{}, 1)}
+}
+
+func (e *fakeElector) Lead(ctx context.Context) (context.Context, func(), error) {
+	select {
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	case <-e.grant:
+	}
+
+	leaderCtx, cancel := context.WithCancel(ctx)
+	e.mu.Lock()
+	e.revoke = cancel
+	e.mu.Unlock()
+
+	return leaderCtx, func() {
+		cancel()
+		atomic.AddInt32(&e.releases, 1)
+	}, nil
+}
+
+func (e *fakeElector) lose() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.revoke()
+}
+
+// TestLeaderTaskLosesAndRegainsLeadership tests cancellation on lease loss and restart on re-election
+func TestLeaderTaskLosesAndRegainsLeadership(t *testing.T) {
+	elector := newFakeElector()
+	var starts, stops int32
+
+	task := LeaderTask("generator", elector, func(ctx context.Context) error {
+		atomic.AddInt32(&starts, 1)
+		<-ctx.Done()
+		atomic.AddInt32(&stops, 1)
+		return ctx.Err()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- task(ctx) }()
+
+	time.Sleep(10 * time.Millisecond)
+	if got := atomic.LoadInt32(&starts); got != 0 {
+		t.Fatalf("Task must not run before leadership is acquired, started %d times", got)
+	}
+
+	elector.grant <- struct{}{}
+	waitUntil(t, time.Second, func() bool { return atomic.LoadInt32(&starts) == 1 })
+
+	elector.lose()
+	waitUntil(t, time.Second, func() bool { return atomic.LoadInt32(&stops) == 1 })
+
+	elector.grant <- struct{}{}
+	waitUntil(t, time.Second, func() bool { return atomic.LoadInt32(&starts) == 2 })
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Expected nil on shutdown, got: %v", err)
+	}
+	if got := atomic.LoadInt32(&elector.releases); got != 2 {
+		t.Errorf("Expected leadership to be released twice, got %d", got)
+	}
+}
+
+// TestLeaderTaskResult tests that a task returning on its own ends the wrapper
+func TestLeaderTaskResult(t *testing.T) {
+	elector := newFakeElector()
+	elector.grant <- struct{}{}
+	taskErr := errors.New("generator failed")
+
+	err := LeaderTask("generator", elector, func(ctx context.Context) error {
+		return taskErr
+	})(context.Background())
+
+	if !errors.Is(err, taskErr) {
+		t.Errorf("Expected task error, got: %v", err)
+	}
+	if got := atomic.LoadInt32(&elector.releases); got != 1 {
+		t.Errorf("Expected leadership to be released, got %d releases", got)
+	}
+}
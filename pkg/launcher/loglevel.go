@@ -0,0 +1,45 @@
+// Package launcher provides runtime log level control on the admin server.
+// This file contains the endpoint that reads and changes the shared log level
+// of the log package without restarting the application.
+package launcher
+
+import (
+	"net/http"
+
+	"github.com/goregion/hexago/pkg/log"
+)
+
+// WithLogLevelEndpoint mounts /loglevel on the admin server to read and change the
+// shared log level and the level rules of the loggers created by the log package at runtime:
+//   - GET /loglevel returns the current settings, e.g. {"level":"INFO"},
+//   - PUT /loglevel with {"level":"debug"} or ?level=debug changes the level,
+//   - PUT /loglevel with {"rules":"service=ohlc-generator:debug"} changes the rules,
+//   - POST /loglevel is accepted as well and behaves like PUT.
+//
+// Every change is logged through the launcher logger.
+// Returns the same launcher instance for method chaining (fluent API).
+//
+// Example:
+//
+//	curl -X PUT 'http://localhost:8081/loglevel?level=debug'
+//	curl -X PUT 'http://localhost:8081/loglevel' -d '{"rules":"service=redis-consumer:trace"}'
+func (a *AppLauncher) WithLogLevelEndpoint() *AppLauncher {
+	handler := log.LevelHandler()
+	change := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		from, fromRules := log.Level(), log.GetLevelRules().String()
+		handler.ServeHTTP(w, r)
+		logger := a.logger()
+		if logger == nil {
+			return
+		}
+		if to := log.Level(); to != from {
+			logger.Warn("log level changed", "from", log.LevelName(from), "to", log.LevelName(to))
+		}
+		if toRules := log.GetLevelRules().String(); toRules != fromRules {
+			logger.Warn("log level rules changed", "from", fromRules, "to", toRules)
+		}
+	})
+	a.HandleAdmin("GET /loglevel", handler)
+	a.HandleAdmin("PUT /loglevel", change)
+	return a.HandleAdmin("POST /loglevel", change)
+}
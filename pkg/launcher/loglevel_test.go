@@ -0,0 +1,68 @@
+package launcher
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goregion/hexago/pkg/log"
+)
+
+// TestLogLevelEndpoint tests reading and changing the log level through the admin server
+func TestLogLevelEndpoint(t *testing.T) {
+	previous := log.Level()
+	log.SetLevel(slog.LevelInfo)
+	t.Cleanup(func() { log.SetLevel(previous) })
+
+	var logOutput bytes.Buffer
+	base := startAdmin(t, NewAppLauncher().
+		WithLoggerContext(log.NewLogger(log.NewJsonHandler(&logOutput))).
+		WithGracefulShutdown(0, time.Second).
+		WithAdminServer("127.0.0.1:0").
+		WithLogLevelEndpoint())
+
+	if status, body := get(t, base+"/loglevel"); status != http.StatusOK || !strings.Contains(body, `"level":"INFO"`) {
+		t.Errorf("Expected the current level, got %d: %s", status, body)
+	}
+
+	send := func(method, target, body string) (int, string) {
+		request, _ := http.NewRequest(method, base+target, strings.NewReader(body))
+		resp, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatalf("%s %s failed: %v", method, target, err)
+		}
+		defer resp.Body.Close()
+		respBody, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(respBody)
+	}
+
+	put := func(target, body string) (int, string) { return send(http.MethodPut, target, body) }
+
+	if status, body := put("/loglevel", `{"level":"trace"}`); status != http.StatusOK || !strings.Contains(body, `"level":"TRACE"`) {
+		t.Errorf("Expected the level to change, got %d: %s", status, body)
+	}
+	if log.Level() != log.LevelTrace {
+		t.Errorf("Expected TRACE level, got %v", log.Level())
+	}
+	if status, _ := put("/loglevel?level=loud", ""); status != http.StatusBadRequest || log.Level() != log.LevelTrace {
+		t.Errorf("Expected an invalid level to be rejected, got %d and level %v", status, log.Level())
+	}
+
+	if status, body := send(http.MethodPost, "/loglevel?level=debug", ""); status != http.StatusOK || log.Level() != slog.LevelDebug {
+		t.Errorf("Expected POST to change the level, got %d: %s", status, body)
+	}
+
+	var changes []string
+	for _, record := range decodeRecords(t, logOutput.String()) {
+		if record["msg"] == "log level changed" {
+			changes = append(changes, record["from"].(string)+"->"+record["to"].(string))
+		}
+	}
+	if strings.Join(changes, ",") != "INFO->TRACE,TRACE->DEBUG" {
+		t.Errorf("Expected every change to be logged once, got %v", changes)
+	}
+}
@@ -0,0 +1,122 @@
+// Package launcher provides runtime metrics of the launcher.
+// This file contains the metrics the launcher publishes about its tasks and the
+// shutdown sequence, and the option that exposes them on the admin server.
+package launcher
+
+import (
+	"context"
+	"time"
+
+	"github.com/goregion/hexago/pkg/metrics"
+)
+
+// taskDurationBuckets are histogram buckets for task run times, from 100ms to one day.
+var taskDurationBuckets = []float64{0.1, 1, 10, 60, 300, 1800, 3600, 6 * 3600, 24 * 3600}
+
+// metricsKey is the context key of the launcher metrics.
+type metricsKey struct{}
+
+// launcherMetrics holds the metrics published by the launcher.
+// All methods are safe to call on a nil receiver, which records nothing.
+type launcherMetrics struct {
+	tasksRunning     *metrics.Gauge
+	taskRestarts     *metrics.Counter
+	taskDuration     *metrics.Histogram
+	taskErrors       *metrics.Counter
+	lastError        *metrics.Gauge
+	shutdownDuration *metrics.Gauge
+}
+
+// newLauncherMetrics registers the launcher metrics in the registry.
+func newLauncherMetrics(registry *metrics.Registry) *launcherMetrics {
+	return &launcherMetrics{
+		tasksRunning: registry.Gauge("launcher_tasks_running",
+			"Tasks currently running."),
+		taskRestarts: registry.Counter("launcher_task_restarts_total",
+			"Restarts of supervised tasks.", "task"),
+		taskDuration: registry.Histogram("launcher_task_duration_seconds",
+			"Run time of tasks that returned.", taskDurationBuckets, "task"),
+		taskErrors: registry.Counter("launcher_task_errors_total",
+			"Tasks that returned an error or panicked.", "task"),
+		lastError: registry.Gauge("launcher_last_error_timestamp_seconds",
+			"Unix time of the last task error, zero if no task failed."),
+		shutdownDuration: registry.Gauge("launcher_shutdown_duration_seconds",
+			"Time from the shutdown request until every task returned or the exit was forced."),
+	}
+}
+
+// metricsFromContext returns the launcher metrics stored in the context, or nil.
+func metricsFromContext(ctx context.Context) *launcherMetrics {
+	m, _ := ctx.Value(metricsKey{}).(*launcherMetrics)
+	return m
+}
+
+// taskStarted records a task that started running.
+func (m *launcherMetrics) taskStarted() {
+	if m == nil {
+		return
+	}
+	m.tasksRunning.Inc()
+}
+
+// taskFinished records a task that returned after running for duration.
+func (m *launcherMetrics) taskFinished(name string, duration time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	m.tasksRunning.Dec()
+	m.taskDuration.Observe(duration.Seconds(), name)
+	if err != nil {
+		m.taskErrors.Inc(name)
+		m.lastError.Set(float64(time.Now().UnixNano()) / 1e9)
+	}
+}
+
+// taskRestarted records a restart of a supervised task.
+func (m *launcherMetrics) taskRestarted(name string) {
+	if m == nil {
+		return
+	}
+	m.taskRestarts.Inc(name)
+}
+
+// shutdownFinished records the duration of the shutdown sequence.
+func (m *launcherMetrics) shutdownFinished(duration time.Duration) {
+	if m == nil {
+		return
+	}
+	m.shutdownDuration.Set(duration.Seconds())
+}
+
+// WithMetrics publishes the launcher metrics in the registry and serves the registry
+// on the admin server at /metrics in the Prometheus text format. The launcher records:
+//   - launcher_tasks_running - tasks currently running,
+//   - launcher_task_restarts_total{task} - restarts of supervised tasks,
+//   - launcher_task_duration_seconds{task} - run time of tasks that returned,
+//   - launcher_task_errors_total{task} - tasks that returned an error,
+//   - launcher_last_error_timestamp_seconds - when the last task error happened,
+//   - launcher_shutdown_duration_seconds - how long the last shutdown took.
+//
+// Applications and adapters can register their own metrics in the same registry,
+// e.g. redis.Client.RegisterMetrics and sqlgen_db.Client.RegisterMetrics.
+// Returns the same launcher instance for method chaining (fluent API).
+//
+// Example:
+//
+//	registry := metrics.NewRegistry()
+//	redisClient.RegisterMetrics(registry, "ticks")
+//
+//	launcher.NewAppLauncher().
+//		WithLoggerContext(logger).
+//		WithGrexitContext().
+//		WithMetrics(registry).
+//		WithAdminServer(":8081").
+//		WaitApplications(consumer.Run, server.Run)
+func (a *AppLauncher) WithMetrics(registry *metrics.Registry) *AppLauncher {
+	if registry == nil {
+		return a
+	}
+	a.metrics = newLauncherMetrics(registry)
+	a.WithContext(metricsKey{}, a.metrics)
+	return a.HandleAdmin("GET /metrics", registry.Handler())
+}
@@ -0,0 +1,95 @@
+package launcher
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/goregion/hexago/pkg/metrics"
+)
+
+// TestLauncherMetrics tests the metrics published by the launcher and the /metrics endpoint
+func TestLauncherMetrics(t *testing.T) {
+	registry := metrics.NewRegistry()
+	launcher := NewAppLauncher().
+		WithMetrics(registry).
+		WithAdminServer("127.0.0.1:0")
+
+	var attempts int32
+	supervisor := NewSupervisor().Add("flaky", func(ctx context.Context) error {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			return errors.New("transient error")
+		}
+		<-ctx.Done()
+		return nil
+	}, fastPolicy(RestartOnFailure, 5))
+
+	done := make(chan *AppResult, 1)
+	go func() {
+		done <- launcher.WaitTasks(
+			Task{Name: "supervisor", Run: supervisor.Run},
+			Task{Name: "failing", Run: func(ctx context.Context) error { return errors.New("boom") }},
+		)
+	}()
+
+	waitUntil(t, time.Second, func() bool {
+		var current strings.Builder
+		registry.WriteText(&current)
+		return launcher.AdminAddr() != "" && atomic.LoadInt32(&attempts) >= 3 &&
+			strings.Contains(current.String(), "launcher_tasks_running 2\n")
+	})
+
+	resp, err := http.Get("http://" + launcher.AdminAddr() + "/metrics")
+	if err != nil {
+		t.Fatalf("GET /metrics failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	output := string(body)
+	for _, expected := range []string{
+		"# TYPE launcher_tasks_running gauge",
+		"launcher_tasks_running 2\n", // supervisor and admin server
+		`launcher_task_restarts_total{task="flaky"} 2`,
+		`launcher_task_errors_total{task="failing"} 1`,
+		`launcher_task_duration_seconds_count{task="failing"} 1`,
+		"launcher_last_error_timestamp_seconds ",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected /metrics to contain %q, got: %s", expected, output)
+		}
+	}
+
+	launcher.Shutdown()
+	<-done
+
+	var final strings.Builder
+	registry.WriteText(&final)
+	for _, expected := range []string{
+		"launcher_tasks_running 0\n",
+		"launcher_shutdown_duration_seconds ",
+		`launcher_task_duration_seconds_count{task="admin-server"} 1`,
+	} {
+		if !strings.Contains(final.String(), expected) {
+			t.Errorf("Expected metrics after shutdown to contain %q, got: %s", expected, final.String())
+		}
+	}
+}
+
+// TestLauncherWithoutMetrics tests that tasks run normally when metrics are not configured
+func TestLauncherWithoutMetrics(t *testing.T) {
+	result := NewAppLauncher().
+		WithMetrics(nil).
+		WaitSupervisor(NewSupervisor().Add("once", func(ctx context.Context) error {
+			return nil
+		}, fastPolicy(RestartNever, 0)))
+
+	if result.Error() != nil {
+		t.Errorf("Expected no error, got: %v", result.Error())
+	}
+}
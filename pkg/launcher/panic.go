@@ -0,0 +1,107 @@
+// Package launcher provides panic recovery for launched tasks.
+// This file contains the PanicError type and the helpers that turn a task panic
+// into a regular task failure carrying the recovered value and stack trace.
+package launcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/goregion/goture"
+)
+
+// PanicError is returned for a task that panicked instead of returning.
+// It carries the recovered value and the stack trace of the panicking goroutine.
+// If the recovered value is an error, it can be reached through errors.Is and errors.As.
+type PanicError struct {
+	Task  string // Name of the task that panicked
+	Value any    // Value passed to panic
+	Stack []byte // Stack trace captured at the point of recovery
+}
+
+// Error returns a short description of the panic without the stack trace.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("task %q panicked: %v", e.Task, e.Value)
+}
+
+// Unwrap returns the recovered value if it is an error, otherwise nil.
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// WithRepanic makes the launcher re-panic after a task panic has been logged,
+// crashing the process as an unrecovered panic would. This includes panics recovered
+// inside a task, such as supervised tasks that would be restarted, worker pool items
+// and scheduled runs. By default a panic is converted into a PanicError and handled
+// like any other task failure.
+// Returns the same launcher instance for method chaining (fluent API).
+func (a *AppLauncher) WithRepanic() *AppLauncher {
+	a.repanic = true
+	return a
+}
+
+// callTask executes the task and converts a panic into a *PanicError,
+// so that a panicking task fails like any other task instead of crashing the process.
+func callTask(ctx context.Context, name string, task goture.Task) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{
+				Task:  name,
+				Value: r,
+				Stack: debug.Stack(),
+			}
+		}
+	}()
+	return task(ctx)
+}
+
+// panicHandlerKey is the context key of the panic handler of the launched task.
+type panicHandlerKey struct{}
+
+// withPanicHandler stores the handler of panics recovered inside the task in the context.
+func withPanicHandler(ctx context.Context, handler func(*PanicError)) context.Context {
+	return context.WithValue(ctx, panicHandlerKey{}, handler)
+}
+
+// handleContextPanic passes the panic carried by err to the panic handler of the context,
+// if any. It is called where a panic is recovered and handled inside a task, e.g. by the
+// supervisor, the worker pool and scheduled tasks, so that WithRepanic applies to them.
+func handleContextPanic(ctx context.Context, err error) {
+	handler, ok := ctx.Value(panicHandlerKey{}).(func(*PanicError))
+	if !ok {
+		return
+	}
+	var panicErr *PanicError
+	if errors.As(err, &panicErr) {
+		handler(panicErr)
+	}
+}
+
+// handleTaskPanic handles the panic carried by err, however deeply it was wrapped,
+// e.g. by the supervisor or a reload hook. Errors without a panic are ignored.
+func (a *AppLauncher) handleTaskPanic(err error) {
+	var panicErr *PanicError
+	if errors.As(err, &panicErr) {
+		a.handlePanic(panicErr)
+	}
+}
+
+// handlePanic logs a task panic through the context logger and re-panics if configured.
+// It is called from the task goroutine, so a re-panic crashes the process.
+func (a *AppLauncher) handlePanic(panicErr *PanicError) {
+	if logger := a.logger(); logger != nil {
+		logger.Error("task panicked",
+			"task", panicErr.Task,
+			"panic", fmt.Sprint(panicErr.Value),
+			"stack", string(panicErr.Stack),
+		)
+	}
+	if a.repanic {
+		panic(panicErr)
+	}
+}
@@ -0,0 +1,200 @@
+package launcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/goregion/hexago/pkg/log"
+)
+
+// TestPanicRecovery tests that a panicking task becomes a PanicError and is logged
+func TestPanicRecovery(t *testing.T) {
+	var logOutput strings.Builder
+	logger := log.NewLogger(log.NewTextHandler(&logOutput))
+
+	result := NewAppLauncher().
+		WithLoggerContext(logger).
+		WaitApplications(
+			func(ctx context.Context) error { return nil },
+			func(ctx context.Context) error { panic("something broke") },
+		)
+
+	var panicErr *PanicError
+	if !errors.As(result.Error(), &panicErr) {
+		t.Fatalf("Expected PanicError, got: %v", result.Error())
+	}
+	if panicErr.Task != "task-1" || panicErr.Value != "something broke" {
+		t.Errorf("Unexpected panic details: %+v", panicErr)
+	}
+	if !strings.Contains(string(panicErr.Stack), "panic_test.go") {
+		t.Errorf("Expected stack trace to point at the panicking task, got: %s", panicErr.Stack)
+	}
+
+	output := logOutput.String()
+	if !strings.Contains(output, "task panicked") || !strings.Contains(output, "panic=\"something broke\"") {
+		t.Errorf("Expected structured panic record, got: %s", output)
+	}
+	if !strings.Contains(output, "stack=") {
+		t.Errorf("Expected stack trace in log record, got: %s", output)
+	}
+}
+
+// TestPanicErrorUnwrap tests that an error passed to panic stays reachable
+func TestPanicErrorUnwrap(t *testing.T) {
+	cause := errors.New("wrapped cause")
+
+	result := NewAppLauncher().WaitApplication(func(ctx context.Context) error {
+		panic(cause)
+	})
+
+	if !errors.Is(result.Error(), cause) {
+		t.Errorf("Expected panic value to be unwrapped, got: %v", result.Error())
+	}
+	if (&PanicError{Value: 42}).Unwrap() != nil {
+		t.Error("Expected nil Unwrap for non-error panic values")
+	}
+}
+
+// TestPanicSupervisorRestart tests that supervised panics follow the restart policy
+func TestPanicSupervisorRestart(t *testing.T) {
+	var attempts int32
+	task := func(ctx context.Context) error {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			panic("first run panics")
+		}
+		return nil
+	}
+
+	var logOutput strings.Builder
+	result := NewAppLauncher().
+		WithLoggerContext(log.NewLogger(log.NewJsonHandler(&logOutput))).
+		WaitSupervisor(
+			NewSupervisor().Add("panicky", task, RestartPolicy{
+				Mode:        RestartOnFailure,
+				Backoff:     Backoff{Initial: time.Millisecond},
+				MaxRestarts: 1,
+			}),
+		)
+
+	if result.Error() != nil {
+		t.Errorf("Expected restart after panic, got: %v", result.Error())
+	}
+	if got := atomic.LoadInt32(&attempts); got != 2 {
+		t.Errorf("Expected 2 attempts, got %d", got)
+	}
+	for _, record := range decodeRecords(t, logOutput.String()) {
+		if record["msg"] == "restarting supervised task" {
+			if stack, _ := record["stack"].(string); !strings.Contains(stack, "goroutine") {
+				t.Errorf("Expected the restart record to carry the panic stack, got %v", record)
+			}
+			return
+		}
+	}
+	t.Error("Expected the restart to be logged")
+}
+
+// TestWithRepanic tests that the option re-panics in the task goroutine
+func TestWithRepanic(t *testing.T) {
+	launcher := NewAppLauncher().WithRepanic()
+
+	repanicked := make(chan any, 1)
+	defer func() {
+		// handlePanic is called directly to avoid crashing the test binary
+		if r := recover(); r != nil {
+			repanicked <- r
+		}
+		select {
+		case r := <-repanicked:
+			if _, ok := r.(*PanicError); !ok {
+				t.Errorf("Expected re-panic with PanicError, got %T", r)
+			}
+		default:
+			t.Error("Expected re-panic")
+		}
+	}()
+
+	launcher.handlePanic(&PanicError{Task: "task-0", Value: "boom"})
+}
+
+// TestWithRepanicSupervised tests that a panic wrapped by the supervisor still re-panics
+func TestWithRepanicSupervised(t *testing.T) {
+	task := func(ctx context.Context) error {
+		panic("supervised boom")
+	}
+
+	// The supervised error is captured without re-panicking to avoid crashing the test binary
+	result := NewAppLauncher().WaitSupervisor(
+		NewSupervisor().Add("panicky", task, RestartPolicy{Mode: RestartNever}),
+	)
+	err := result.Error()
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) || panicErr.Task != "panicky" {
+		t.Fatalf("Expected a wrapped PanicError, got: %v", err)
+	}
+	if _, ok := err.(*PanicError); ok {
+		t.Fatalf("Expected the supervisor to wrap the PanicError, got it unwrapped")
+	}
+
+	defer func() {
+		r := recover()
+		if repanicked, ok := r.(*PanicError); !ok || repanicked.Value != "supervised boom" {
+			t.Errorf("Expected re-panic with the supervised PanicError, got %v", r)
+		}
+	}()
+	NewAppLauncher().WithRepanic().handleTaskPanic(err)
+	t.Error("Expected re-panic")
+}
+
+// TestHandleTaskPanicIgnoresErrors tests that plain errors never re-panic
+func TestHandleTaskPanicIgnoresErrors(t *testing.T) {
+	NewAppLauncher().WithRepanic().handleTaskPanic(fmt.Errorf("task %q failed: %w", "plain", errors.New("boom")))
+	NewAppLauncher().WithRepanic().handleTaskPanic(nil)
+}
+
+// TestContextPanicHandler tests that panics recovered inside tasks reach the panic handler
+// of the context, which re-panics with WithRepanic
+func TestContextPanicHandler(t *testing.T) {
+	handled := make(chan *PanicError, 3)
+	ctx, cancel := context.WithCancel(withPanicHandler(context.Background(), func(panicErr *PanicError) {
+		handled <- panicErr
+	}))
+	defer cancel()
+
+	// A supervised panic is handled before the task is restarted
+	var attempts atomic.Int32
+	supervisor := NewSupervisor().Add("supervised", func(ctx context.Context) error {
+		if attempts.Add(1) == 1 {
+			panic("supervised boom")
+		}
+		<-ctx.Done()
+		return nil
+	}, RestartPolicy{Mode: RestartOnFailure, Backoff: Backoff{Initial: time.Millisecond}})
+	go supervisor.Run(ctx)
+
+	pool := NewWorkerPool("pool", func(ctx context.Context, item int) error { panic("pool boom") }, PoolOptions{Workers: 1, StatsInterval: -1})
+	go pool.Run(ctx)
+	if err := pool.Submit(ctx, 1); err != nil {
+		t.Fatalf("Failed to submit: %v", err)
+	}
+
+	scheduled := ScheduledTask{Name: "scheduled", Interval: time.Hour, RunOnStart: true, Job: func(ctx context.Context) error { panic("scheduled boom") }}
+	go scheduled.Run(ctx)
+
+	values := make(map[string]any)
+	for range 3 {
+		select {
+		case panicErr := <-handled:
+			values[panicErr.Task] = panicErr.Value
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Expected every panic to be handled, got %v", values)
+		}
+	}
+	if values["supervised"] != "supervised boom" || values["pool"] != "pool boom" || values["scheduled"] != "scheduled boom" {
+		t.Errorf("Unexpected handled panics: %v", values)
+	}
+}
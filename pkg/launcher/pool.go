@@ -0,0 +1,307 @@
+// Package launcher provides a managed worker pool task.
+// This file contains the WorkerPool type that processes submitted items with a
+// fixed number of workers, a bounded queue and a backpressure policy.
+package launcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/goregion/hexago/pkg/log"
+)
+
+// DefaultPoolStatsInterval is the default interval between worker pool stats records.
+const DefaultPoolStatsInterval = 30 * time.Second
+
+var (
+	// ErrPoolFull is returned by Submit when the queue is full and the policy is QueueReject.
+	ErrPoolFull = errors.New("worker pool queue is full")
+	// ErrPoolStopped is returned by Submit once the pool has stopped.
+	ErrPoolStopped = errors.New("worker pool is stopped")
+)
+
+// QueuePolicy defines what Submit does when the queue is full.
+type QueuePolicy int
+
+const (
+	// QueueBlock makes Submit wait for free space in the queue. This is the default.
+	QueueBlock QueuePolicy = iota
+	// QueueReject makes Submit fail immediately with ErrPoolFull.
+	QueueReject
+)
+
+// String returns the policy name used in logs.
+func (p QueuePolicy) String() string {
+	switch p {
+	case QueueBlock:
+		return "block"
+	case QueueReject:
+		return "reject"
+	default:
+		return fmt.Sprintf("QueuePolicy(%d)", int(p))
+	}
+}
+
+// PoolOptions configures a worker pool.
+type PoolOptions struct {
+	Workers       int           // Number of workers, zero means GOMAXPROCS
+	QueueSize     int           // Capacity of the input queue, zero means items are handed to workers directly
+	Policy        QueuePolicy   // What Submit does when the queue is full
+	DrainQueue    bool          // Process queued items on shutdown instead of dropping them
+	StatsInterval time.Duration // Interval between stats records, zero means DefaultPoolStatsInterval, negative disables them
+}
+
+// PoolStats is a snapshot of worker pool counters.
+type PoolStats struct {
+	QueueDepth int   // Items waiting in the queue
+	InFlight   int64 // Items being processed
+	Processed  int64 // Items processed successfully
+	Failed     int64 // Items whose handler returned an error or panicked
+	Rejected   int64 // Items rejected by Submit because the queue was full
+	Dropped    int64 // Queued items dropped on shutdown
+}
+
+// WorkerPool processes submitted items with a fixed number of workers.
+// Items are submitted from any goroutine with Submit and processed by Run, which has
+// the goture.Task signature and runs until its context is done. On shutdown the items
+// being processed are finished (handlers receive a context that is not canceled by the
+// shutdown) and queued items are dropped, or processed if DrainQueue is set.
+//
+// Example:
+//
+//	pool := launcher.NewWorkerPool("tick-writer", writeTick, launcher.PoolOptions{
+//		Workers:   8,
+//		QueueSize: 1024,
+//		Policy:    launcher.QueueBlock,
+//	})
+//
+//	launcher.NewAppLauncher().
+//		WithLoggerContext(logger).
+//		WithGrexitContext().
+//		WaitApplications(pool.Run, consumer.Run) // consumer calls pool.Submit
+type WorkerPool[T any] struct {
+	name     string
+	handler  func(ctx context.Context, item T) error
+	onError  func(ctx context.Context, item T, err error)
+	options  PoolOptions
+	queue    chan T
+	started  atomic.Bool
+	stopped  chan struct{}
+	submitMu sync.RWMutex // Held for reading by Submit, for writing when the pool drops leftovers
+	inFlight atomic.Int64
+	counters struct {
+		processed, failed, rejected, dropped atomic.Int64
+	}
+}
+
+// NewWorkerPool creates a worker pool that calls handler for every submitted item.
+func NewWorkerPool[T any](name string, handler func(ctx context.Context, item T) error, options PoolOptions) *WorkerPool[T] {
+	if options.Workers <= 0 {
+		options.Workers = runtime.GOMAXPROCS(0)
+	}
+	if options.QueueSize < 0 {
+		options.QueueSize = 0
+	}
+	if options.StatsInterval == 0 {
+		options.StatsInterval = DefaultPoolStatsInterval
+	}
+
+	return &WorkerPool[T]{
+		name:    name,
+		handler: handler,
+		options: options,
+		queue:   make(chan T, options.QueueSize),
+		stopped: make(chan struct{}),
+	}
+}
+
+// OnError sets the callback for items whose handler failed or panicked.
+// By default failures are logged through the context logger.
+// Returns the same pool instance for method chaining (fluent API).
+func (p *WorkerPool[T]) OnError(onError func(ctx context.Context, item T, err error)) *WorkerPool[T] {
+	p.onError = onError
+	return p
+}
+
+// Submit adds an item to the queue. When the queue is full it waits for free space
+// (QueueBlock) or fails with ErrPoolFull (QueueReject). It fails with ErrPoolStopped once
+// the pool has stopped and with the context error if ctx is done while waiting.
+// Items can be submitted before Run is called, up to the queue capacity.
+func (p *WorkerPool[T]) Submit(ctx context.Context, item T) error {
+	p.submitMu.RLock()
+	defer p.submitMu.RUnlock()
+
+	select {
+	case <-p.stopped:
+		return ErrPoolStopped
+	default:
+	}
+
+	if p.options.Policy == QueueReject {
+		select {
+		case p.queue <- item:
+			return nil
+		default:
+			p.counters.rejected.Add(1)
+			return ErrPoolFull
+		}
+	}
+
+	select {
+	case p.queue <- item:
+		return nil
+	case <-p.stopped:
+		return ErrPoolStopped
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stats returns a snapshot of the pool counters.
+func (p *WorkerPool[T]) Stats() PoolStats {
+	return PoolStats{
+		QueueDepth: len(p.queue),
+		InFlight:   p.inFlight.Load(),
+		Processed:  p.counters.processed.Load(),
+		Failed:     p.counters.failed.Load(),
+		Rejected:   p.counters.rejected.Load(),
+		Dropped:    p.counters.dropped.Load(),
+	}
+}
+
+// Run starts the workers and blocks until ctx is done and the workers have wound down.
+// A pool can be run only once.
+func (p *WorkerPool[T]) Run(ctx context.Context) error {
+	if p.handler == nil {
+		return fmt.Errorf("worker pool %q: handler cannot be nil", p.name)
+	}
+	if !p.started.CompareAndSwap(false, true) {
+		return fmt.Errorf("worker pool %q is already running", p.name)
+	}
+
+	logger, _ := log.GetLoggerFromContext(ctx)
+	if logger != nil {
+		logger.Info("worker pool started",
+			"pool", p.name,
+			"workers", p.options.Workers,
+			"queue_capacity", cap(p.queue),
+			"policy", p.options.Policy.String(),
+		)
+	}
+
+	// In-flight items are finished even though the pool is shutting down
+	itemCtx := context.WithoutCancel(ctx)
+
+	var wg sync.WaitGroup
+	for range p.options.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.work(ctx, itemCtx)
+		}()
+	}
+
+	var stats <-chan time.Time
+	if p.options.StatsInterval > 0 && logger != nil {
+		ticker := time.NewTicker(p.options.StatsInterval)
+		defer ticker.Stop()
+		stats = ticker.C
+	}
+
+	for ctx.Err() == nil {
+		select {
+		case <-ctx.Done():
+		case <-stats:
+			p.logStats(logger, "worker pool stats")
+		}
+	}
+
+	close(p.stopped)
+	wg.Wait()
+
+	// Wait for pending Submit calls to observe the stop before dropping the leftovers
+	p.submitMu.Lock()
+	for len(p.queue) > 0 {
+		<-p.queue
+		p.counters.dropped.Add(1)
+	}
+	p.submitMu.Unlock()
+
+	if logger != nil {
+		p.logStats(logger, "worker pool stopped")
+	}
+	return nil
+}
+
+// work processes items until the pool stops. With DrainQueue it then processes
+// the remaining queued items before returning.
+func (p *WorkerPool[T]) work(ctx, itemCtx context.Context) {
+	// The context is checked before every item, since select picks
+	// randomly between a done context and a non-empty queue
+	for ctx.Err() == nil {
+		select {
+		case <-ctx.Done():
+		case item := <-p.queue:
+			p.process(itemCtx, item)
+		}
+	}
+
+	for p.options.DrainQueue {
+		select {
+		case item := <-p.queue:
+			p.process(itemCtx, item)
+		default:
+			return
+		}
+	}
+}
+
+// process calls the handler for a single item and handles its failure.
+func (p *WorkerPool[T]) process(ctx context.Context, item T) {
+	p.inFlight.Add(1)
+	defer p.inFlight.Add(-1)
+
+	err := callTask(ctx, p.name, func(ctx context.Context) error {
+		return p.handler(ctx, item)
+	})
+	if err == nil {
+		p.counters.processed.Add(1)
+		return
+	}
+
+	p.counters.failed.Add(1)
+	// Runs after the failure has been handled
+	defer handleContextPanic(ctx, err)
+	if p.onError != nil {
+		p.onError(ctx, item, err)
+		return
+	}
+	if logger, logErr := log.GetLoggerFromContext(ctx); logErr == nil {
+		attrs := []any{"pool", p.name, "error", err}
+		var panicErr *PanicError
+		if errors.As(err, &panicErr) {
+			attrs = append(attrs, "stack", string(panicErr.Stack))
+		}
+		logger.Error("worker pool item failed", attrs...)
+	}
+}
+
+// logStats logs the pool counters.
+func (p *WorkerPool[T]) logStats(logger *log.Logger, message string) {
+	stats := p.Stats()
+	logger.Info(message,
+		"pool", p.name,
+		"queue_depth", stats.QueueDepth,
+		"queue_capacity", cap(p.queue),
+		"in_flight", stats.InFlight,
+		"processed", stats.Processed,
+		"failed", stats.Failed,
+		"rejected", stats.Rejected,
+		"dropped", stats.Dropped,
+	)
+}
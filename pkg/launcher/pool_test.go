@@ -0,0 +1,235 @@
+package launcher
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/goregion/hexago/pkg/log"
+)
+
+// startPool runs the pool in the background and returns a function that stops it
+func startPool[T any](t *testing.T, ctx context.Context, pool *WorkerPool[T]) func() error {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	go func() { done <- pool.Run(ctx) }()
+
+	return func() error {
+		cancel()
+		select {
+		case err := <-done:
+			return err
+		case <-time.After(time.Second):
+			t.Fatal("Worker pool did not stop")
+			return nil
+		}
+	}
+}
+
+// TestWorkerPoolProcessesItems tests bounded concurrency and per-item error handling
+func TestWorkerPoolProcessesItems(t *testing.T) {
+	var (
+		active, maxActive int32
+		mu                sync.Mutex
+		failedItems       []int
+	)
+
+	pool := NewWorkerPool("squares", func(ctx context.Context, item int) error {
+		current := atomic.AddInt32(&active, 1)
+		defer atomic.AddInt32(&active, -1)
+		for {
+			observed := atomic.LoadInt32(&maxActive)
+			if current <= observed || atomic.CompareAndSwapInt32(&maxActive, observed, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		if item%10 == 0 {
+			return errors.New("bad item")
+		}
+		return nil
+	}, PoolOptions{Workers: 3, QueueSize: 5}).
+		OnError(func(ctx context.Context, item int, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			failedItems = append(failedItems, item)
+		})
+
+	stop := startPool(t, context.Background(), pool)
+	for i := 1; i <= 30; i++ {
+		if err := pool.Submit(context.Background(), i); err != nil {
+			t.Fatalf("Submit failed: %v", err)
+		}
+	}
+	waitUntil(t, time.Second, func() bool {
+		stats := pool.Stats()
+		return stats.Processed+stats.Failed == 30
+	})
+	if err := stop(); err != nil {
+		t.Errorf("Expected nil on shutdown, got: %v", err)
+	}
+
+	stats := pool.Stats()
+	if stats.Processed != 27 || stats.Failed != 3 || len(failedItems) != 3 {
+		t.Errorf("Unexpected stats %+v, failed items %v", stats, failedItems)
+	}
+	if got := atomic.LoadInt32(&maxActive); got > 3 {
+		t.Errorf("Expected at most 3 concurrent items, got %d", got)
+	}
+	if err := pool.Submit(context.Background(), 31); !errors.Is(err, ErrPoolStopped) {
+		t.Errorf("Expected ErrPoolStopped after shutdown, got: %v", err)
+	}
+}
+
+// TestWorkerPoolReject tests the reject policy when the queue is full
+func TestWorkerPoolReject(t *testing.T) {
+	release := make(chan struct{})
+	pool := NewWorkerPool("blocked", func(ctx context.Context, item int) error {
+		<-release
+		return nil
+	}, PoolOptions{Workers: 1, QueueSize: 2, Policy: QueueReject})
+
+	stop := startPool(t, context.Background(), pool)
+	defer stop()
+
+	// One item in flight and two queued fill the pool
+	if err := pool.Submit(context.Background(), 1); err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	waitUntil(t, time.Second, func() bool { return pool.Stats().InFlight == 1 })
+	for i := 2; i <= 3; i++ {
+		if err := pool.Submit(context.Background(), i); err != nil {
+			t.Fatalf("Submit failed: %v", err)
+		}
+	}
+
+	if err := pool.Submit(context.Background(), 4); !errors.Is(err, ErrPoolFull) {
+		t.Errorf("Expected ErrPoolFull, got: %v", err)
+	}
+	if stats := pool.Stats(); stats.Rejected != 1 || stats.QueueDepth != 2 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+	close(release)
+}
+
+// TestWorkerPoolBlock tests that Submit waits for free space with the block policy
+func TestWorkerPoolBlock(t *testing.T) {
+	release := make(chan struct{})
+	pool := NewWorkerPool("blocked", func(ctx context.Context, item int) error {
+		<-release
+		return nil
+	}, PoolOptions{Workers: 1, QueueSize: 1})
+
+	stop := startPool(t, context.Background(), pool)
+	defer stop()
+
+	pool.Submit(context.Background(), 1)
+	waitUntil(t, time.Second, func() bool { return pool.Stats().InFlight == 1 })
+	pool.Submit(context.Background(), 2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := pool.Submit(ctx, 3); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected Submit to block until its context expired, got: %v", err)
+	}
+
+	close(release)
+	if err := pool.Submit(context.Background(), 4); err != nil {
+		t.Errorf("Expected Submit to succeed once space is free, got: %v", err)
+	}
+}
+
+// TestWorkerPoolGracefulWindDown tests that in-flight items finish on shutdown
+func TestWorkerPoolGracefulWindDown(t *testing.T) {
+	for _, drain := range []bool{false, true} {
+		started := make(chan struct{})
+		var finished int32
+
+		pool := NewWorkerPool("slow", func(ctx context.Context, item int) error {
+			if item == 1 {
+				close(started)
+			}
+			time.Sleep(30 * time.Millisecond)
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			atomic.AddInt32(&finished, 1)
+			return nil
+		}, PoolOptions{Workers: 1, QueueSize: 3, DrainQueue: drain})
+
+		stop := startPool(t, context.Background(), pool)
+		for i := 1; i <= 3; i++ {
+			pool.Submit(context.Background(), i)
+		}
+		<-started
+		stop()
+
+		stats := pool.Stats()
+		if drain && (finished != 3 || stats.Dropped != 0) {
+			t.Errorf("DrainQueue: expected all items to be processed, got %d, stats %+v", finished, stats)
+		}
+		if !drain && (finished != 1 || stats.Dropped != 2) {
+			t.Errorf("Expected the in-flight item to finish and the rest to be dropped, got %d, stats %+v", finished, stats)
+		}
+	}
+}
+
+// TestWorkerPoolLogs tests stats records and default failure logging
+func TestWorkerPoolLogs(t *testing.T) {
+	var logOutput bytes.Buffer
+	logger := log.NewLogger(log.NewJsonHandler(&logOutput))
+	ctx := log.WithLoggerContext(context.Background(), logger)
+
+	pool := NewWorkerPool("ticks", func(ctx context.Context, item string) error {
+		if item == "bad" {
+			panic("corrupted tick")
+		}
+		return nil
+	}, PoolOptions{Workers: 2, QueueSize: 4, StatsInterval: 10 * time.Millisecond})
+
+	stop := startPool(t, ctx, pool)
+	pool.Submit(ctx, "good")
+	pool.Submit(ctx, "bad")
+	waitUntil(t, time.Second, func() bool { return pool.Stats().Failed == 1 })
+	time.Sleep(25 * time.Millisecond)
+	stop()
+
+	output := logOutput.String()
+	for _, expected := range []string{
+		`"msg":"worker pool started"`,
+		`"msg":"worker pool stats"`,
+		`"queue_depth":`,
+		`"msg":"worker pool item failed"`,
+		`corrupted tick`,
+		`"msg":"worker pool stopped"`,
+		`"processed":1`,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected log output to contain %s, got: %s", expected, output)
+		}
+	}
+}
+
+// TestWorkerPoolRunOnce tests that a pool cannot be started twice
+func TestWorkerPoolRunOnce(t *testing.T) {
+	pool := NewWorkerPool("once", func(ctx context.Context, item int) error { return nil }, PoolOptions{})
+	stop := startPool(t, context.Background(), pool)
+	defer stop()
+
+	waitUntil(t, time.Second, pool.started.Load)
+	if err := pool.Run(context.Background()); err == nil {
+		t.Error("Expected error when running the pool twice")
+	}
+
+	nilHandler := NewWorkerPool[int]("nil", nil, PoolOptions{})
+	if err := nilHandler.Run(context.Background()); err == nil {
+		t.Error("Expected error for nil handler")
+	}
+}
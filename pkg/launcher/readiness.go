@@ -0,0 +1,246 @@
+// Package launcher provides startup readiness gating for launched tasks.
+// This file contains the Task type with dependencies, the MarkReady signal used by
+// tasks to report that they finished initializing, and the startup timeout.
+package launcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/goregion/goture"
+)
+
+// ErrStartupTimeout is returned when tasks do not become ready within the startup timeout.
+// The returned error names the tasks that never became ready.
+var ErrStartupTimeout = errors.New("startup timeout exceeded")
+
+// Task is a named application task with startup dependencies.
+type Task struct {
+	Name         string       // Task name used in logs, errors and DependsOn; managed task names such as "admin-server" are reserved
+	Run          goture.Task  // Task body
+	DependsOn    []string     // Tasks that must be ready before this task starts
+	ReportsReady bool         // The task calls MarkReady once initialized; otherwise it is ready once started
+	Validate     func() error // Checks the task configuration without connecting to anything, optional
+}
+
+// readinessKey is the context key of the readiness gate of the running task.
+type readinessKey struct{}
+
+// readinessGate tracks the readiness of a task; ready is closed when the task becomes ready
+// and exited when the task has returned or failed to start.
+type readinessGate struct {
+	name       string
+	dependsOn  []int // Indexes of the tasks that must be ready first
+	ready      chan struct{}
+	exited     chan struct{}
+	once       sync.Once
+	exitedOnce sync.Once
+	started    atomic.Bool // Dependencies are ready and the task is running
+}
+
+// markReady marks the task as ready. It is safe to call several times.
+func (g *readinessGate) markReady() {
+	g.once.Do(func() { close(g.ready) })
+}
+
+// markExited records that the task will never become ready if it is not ready yet,
+// so that its dependents fail instead of waiting forever. It is safe to call several times.
+func (g *readinessGate) markExited() {
+	g.exitedOnce.Do(func() { close(g.exited) })
+}
+
+// isReady reports whether the task is ready.
+func (g *readinessGate) isReady() bool {
+	select {
+	case <-g.ready:
+		return true
+	default:
+		return false
+	}
+}
+
+// readinessGates holds the readiness gates of all launched tasks, in launch order.
+type readinessGates []*readinessGate
+
+// newReadinessGates creates a gate per task. Dependencies must have been validated.
+func newReadinessGates(tasks []namedTask) readinessGates {
+	indexes := make(map[string]int, len(tasks))
+	for i, t := range tasks {
+		indexes[t.name] = i
+	}
+
+	gates := make(readinessGates, len(tasks))
+	for i, t := range tasks {
+		gates[i] = &readinessGate{name: t.name, ready: make(chan struct{}), exited: make(chan struct{})}
+		for _, dep := range t.dependsOn {
+			gates[i].dependsOn = append(gates[i].dependsOn, indexes[dep])
+		}
+	}
+	return gates
+}
+
+// waitDependencies blocks until all dependencies of the task are ready.
+// It returns false if ctx is done first, and false with an error if a dependency
+// exited before becoming ready.
+func (g readinessGates) waitDependencies(ctx context.Context, index int) (bool, error) {
+	for _, dep := range g[index].dependsOn {
+		select {
+		case <-ctx.Done():
+			return false, nil
+		case <-g[dep].ready:
+		case <-g[dep].exited:
+			// A task that became ready before returning does not block its dependents
+			if !g[dep].isReady() {
+				return false, fmt.Errorf("dependency %q exited before becoming ready", g[dep].name)
+			}
+		}
+	}
+	if ctx.Err() != nil {
+		return false, nil
+	}
+	g[index].started.Store(true)
+	return true, nil
+}
+
+// withGate returns the task context carrying the readiness gate of the task.
+func (g readinessGates) withGate(ctx context.Context, index int) context.Context {
+	return context.WithValue(ctx, readinessKey{}, g[index])
+}
+
+// allReady returns a channel closed once every task is ready.
+// The channel is never closed if ctx is done first.
+func (g readinessGates) allReady(ctx context.Context) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		for _, gate := range g {
+			select {
+			case <-ctx.Done():
+				return
+			case <-gate.ready:
+			}
+		}
+		close(done)
+	}()
+	return done
+}
+
+// notReady returns the names of the tasks that are running but not ready yet
+// and of the tasks still waiting for their dependencies.
+func (g readinessGates) notReady() (running, waiting []string) {
+	for _, gate := range g {
+		switch {
+		case gate.isReady():
+		case gate.started.Load():
+			running = append(running, gate.name)
+		default:
+			waiting = append(waiting, gate.name)
+		}
+	}
+	return running, waiting
+}
+
+// newStartupError builds the startup timeout error naming the tasks that never became ready.
+func newStartupError(timeout time.Duration, notReady []string) error {
+	quoted := make([]string, len(notReady))
+	for i, name := range notReady {
+		quoted[i] = fmt.Sprintf("%q", name)
+	}
+	noun := "task"
+	if len(notReady) > 1 {
+		noun = "tasks"
+	}
+	return fmt.Errorf("%w (%v): %s %s did not become ready", ErrStartupTimeout, timeout, noun, strings.Join(quoted, ", "))
+}
+
+// MarkReady reports that the task owning ctx has finished initializing.
+// Tasks that depend on it are started, and the launcher becomes ready (see AppLauncher.Ready)
+// once every task is ready. It is a no-op for contexts not created by the launcher
+// and for tasks that are not launched with ReportsReady, which are ready as soon as they start.
+func MarkReady(ctx context.Context) {
+	if gate, ok := ctx.Value(readinessKey{}).(*readinessGate); ok {
+		gate.markReady()
+	}
+}
+
+// WithStartupTimeout fails the launch if the tasks do not all become ready within timeout.
+// The tasks are then stopped and the result wraps ErrStartupTimeout, naming the tasks
+// that never became ready. Zero disables the timeout.
+// Returns the same launcher instance for method chaining (fluent API).
+func (a *AppLauncher) WithStartupTimeout(timeout time.Duration) *AppLauncher {
+	a.startupTimeout = max(timeout, 0)
+	return a
+}
+
+// WaitTasks launches named tasks and waits for their completion. A task is started
+// only after every task listed in its DependsOn is ready; tasks with ReportsReady are
+// ready once they call MarkReady, other tasks as soon as they start. If a task returns
+// before it is ready, its dependents fail without starting and the tasks are stopped.
+// The Validate function of every task is called before any task is started.
+//
+// Example:
+//
+//	launcher.NewAppLauncher().
+//		WithLoggerContext(logger).
+//		WithGrexitContext().
+//		WithStartupTimeout(30*time.Second).
+//		WaitTasks(
+//			launcher.Task{Name: "db-pool", Run: pool.Run, ReportsReady: true},
+//			launcher.Task{Name: "consumer", Run: consumer.Run, DependsOn: []string{"db-pool"}},
+//		)
+//
+// Returns AppResult which can be used to check for errors and log them if needed.
+func (a *AppLauncher) WaitTasks(tasks ...Task) *AppResult {
+	if len(tasks) == 0 {
+		return &AppResult{Err: errors.New("at least one task must be provided")}
+	}
+	if a.validateMode {
+		return a.validateTaskDefinitions(tasks)
+	}
+
+	named := make([]namedTask, len(tasks))
+	order := make([]string, len(tasks))
+	dependsOn := make(map[string][]string, len(tasks))
+	for i, task := range tasks {
+		switch {
+		case task.Name == "":
+			return &AppResult{Err: fmt.Errorf("task at index %d: name cannot be empty", i)}
+		case task.Run == nil:
+			return &AppResult{Err: fmt.Errorf("task %q cannot be nil", task.Name)}
+		}
+		if _, exists := dependsOn[task.Name]; exists {
+			return &AppResult{Err: fmt.Errorf("task %q is defined more than once", task.Name)}
+		}
+
+		order[i] = task.Name
+		dependsOn[task.Name] = task.DependsOn
+		named[i] = namedTask{
+			name:         task.Name,
+			task:         task.Run,
+			dependsOn:    task.DependsOn,
+			reportsReady: task.ReportsReady,
+		}
+	}
+
+	if _, err := dependencyOrder("task", order, dependsOn); err != nil {
+		return &AppResult{Err: err}
+	}
+
+	var errs []error
+	for _, task := range tasks {
+		if task.Validate == nil {
+			continue
+		}
+		if err := task.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("task %q is invalid: %w", task.Name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return &AppResult{Err: errors.Join(errs...)}
+	}
+	return a.runTasks(named)
+}
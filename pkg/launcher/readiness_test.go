@@ -0,0 +1,182 @@
+package launcher
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestWaitTasksReadinessGating tests that dependents start only after their prerequisites are ready
+func TestWaitTasksReadinessGating(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		events []string
+	)
+	record := func(event string) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	}
+
+	launcher := NewAppLauncher()
+	result := launcher.WaitTasks(
+		Task{
+			Name:      "consumer",
+			DependsOn: []string{"db-pool"},
+			Run: func(ctx context.Context) error {
+				record("consumer started")
+				waitUntil(t, time.Second, launcher.Ready)
+				launcher.Shutdown()
+				return nil
+			},
+		},
+		Task{
+			Name:         "db-pool",
+			ReportsReady: true,
+			Run: func(ctx context.Context) error {
+				time.Sleep(20 * time.Millisecond)
+				if launcher.Ready() {
+					t.Error("Launcher must not be ready before every task is ready")
+				}
+				record("db-pool warm")
+				MarkReady(ctx)
+				<-ctx.Done()
+				return nil
+			},
+		},
+	)
+
+	if result.Error() != nil {
+		t.Fatalf("Expected no error, got: %v", result.Error())
+	}
+	if strings.Join(events, ",") != "db-pool warm,consumer started" {
+		t.Errorf("Unexpected start order: %v", events)
+	}
+}
+
+// TestWaitTasksStartupTimeout tests that the launch fails naming the task that never became ready
+func TestWaitTasksStartupTimeout(t *testing.T) {
+	dependentStarted := false
+
+	start := time.Now()
+	result := NewAppLauncher().
+		WithStartupTimeout(30*time.Millisecond).
+		WaitTasks(
+			Task{Name: "db-pool", ReportsReady: true, Run: func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			}},
+			Task{Name: "consumer", DependsOn: []string{"db-pool"}, Run: func(ctx context.Context) error {
+				dependentStarted = true
+				return nil
+			}},
+		)
+
+	if !errors.Is(result.Error(), ErrStartupTimeout) {
+		t.Fatalf("Expected ErrStartupTimeout, got: %v", result.Error())
+	}
+	if !strings.Contains(result.Error().Error(), `task "db-pool" did not become ready`) {
+		t.Errorf("Expected error to name the task, got: %v", result.Error())
+	}
+	if strings.Contains(result.Error().Error(), `"consumer"`) {
+		t.Errorf("Only the task that never became ready must be named, got: %v", result.Error())
+	}
+	if dependentStarted {
+		t.Error("Dependent task must not start when its prerequisite never becomes ready")
+	}
+	if duration := time.Since(start); duration > 500*time.Millisecond {
+		t.Errorf("Startup timeout was not enforced, took %v", duration)
+	}
+}
+
+// TestWaitTasksDependencyExitedBeforeReady tests that dependents fail and the launch stops
+// when a prerequisite returns before MarkReady, even without a startup timeout
+func TestWaitTasksDependencyExitedBeforeReady(t *testing.T) {
+	var started sync.Map
+	done := make(chan *AppResult, 1)
+	go func() {
+		done <- NewAppLauncher().WaitTasks(
+			Task{Name: "db-pool", ReportsReady: true, Run: func(ctx context.Context) error {
+				return errors.New("connection refused")
+			}},
+			Task{Name: "consumer", DependsOn: []string{"db-pool"}, Run: func(ctx context.Context) error {
+				started.Store("consumer", true)
+				return nil
+			}},
+			Task{Name: "api", DependsOn: []string{"consumer"}, Run: func(ctx context.Context) error {
+				started.Store("api", true)
+				return nil
+			}},
+			Task{Name: "metrics", Run: func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			}},
+		)
+	}()
+
+	var result *AppResult
+	select {
+	case result = <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Launch hangs when a dependency exits before becoming ready")
+	}
+
+	err := result.Error()
+	for _, expected := range []string{"connection refused", `dependency "db-pool" exited before becoming ready`} {
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected error to contain %q, got: %v", expected, err)
+		}
+	}
+	started.Range(func(name, _ any) bool {
+		t.Errorf("Task %v must not start", name)
+		return true
+	})
+}
+
+// TestWaitTasksStartupTimeoutMet tests that ready tasks are not affected by the startup timeout
+func TestWaitTasksStartupTimeoutMet(t *testing.T) {
+	result := NewAppLauncher().
+		WithStartupTimeout(20 * time.Millisecond).
+		WaitTasks(Task{Name: "worker", ReportsReady: true, Run: func(ctx context.Context) error {
+			MarkReady(ctx)
+			time.Sleep(50 * time.Millisecond)
+			return nil
+		}})
+
+	if result.Error() != nil {
+		t.Errorf("Expected no error, got: %v", result.Error())
+	}
+}
+
+// TestWaitTasksValidation tests dependency validation
+func TestWaitTasksValidation(t *testing.T) {
+	run := func(ctx context.Context) error { return nil }
+
+	tests := map[string][]Task{
+		"no tasks":           nil,
+		"empty name":         {{Run: run}},
+		"nil run":            {{Name: "a"}},
+		"duplicate":          {{Name: "a", Run: run}, {Name: "a", Run: run}},
+		"unknown dependency": {{Name: "a", Run: run, DependsOn: []string{"b"}}},
+		"cycle":              {{Name: "a", Run: run, DependsOn: []string{"b"}}, {Name: "b", Run: run, DependsOn: []string{"a"}}},
+	}
+
+	for name, tasks := range tests {
+		if result := NewAppLauncher().WaitTasks(tasks...); result.Error() == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	err := NewAppLauncher().WaitTasks(Task{Name: "a", Run: run, DependsOn: []string{"a"}}).Error()
+	if !errors.Is(err, ErrDependencyCycle) {
+		t.Errorf("Expected ErrDependencyCycle, got: %v", err)
+	}
+}
+
+// TestMarkReadyOutsideLauncher tests that MarkReady is a no-op for foreign contexts
+func TestMarkReadyOutsideLauncher(t *testing.T) {
+	MarkReady(context.Background())
+}
@@ -0,0 +1,99 @@
+// Package launcher provides configuration reload for launched applications.
+// This file contains the reload hooks that are called on SIGHUP or on a Reload
+// call while the applications keep running.
+package launcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"syscall"
+	"time"
+
+	"github.com/goregion/goture"
+)
+
+// ReloadHook re-reads configuration or refreshes state of a running component,
+// for example re-parsing a YAML file, changing log levels or rotating credentials.
+// The context is canceled when the launched applications stop.
+type ReloadHook func(ctx context.Context) error
+
+// reloadHook is a reload hook together with the name used in logs.
+type reloadHook struct {
+	name string
+	hook ReloadHook
+}
+
+// OnReload registers a hook called on every reload. Hooks run one after another in
+// registration order; a failing hook is logged and does not prevent the others from running.
+// Hooks can also be registered while the applications are running.
+// Returns the same launcher instance for method chaining (fluent API).
+func (a *AppLauncher) OnReload(name string, hook ReloadHook) *AppLauncher {
+	if hook == nil {
+		return a
+	}
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+	a.reloadHooks = append(a.reloadHooks, reloadHook{name: name, hook: hook})
+	return a
+}
+
+// Reload triggers the reload hooks from code, exactly as if SIGHUP was received.
+// Reloads requested while hooks are running are coalesced into one.
+// It never blocks and is safe to call from any goroutine.
+func (a *AppLauncher) Reload() {
+	select {
+	case a.reloads <- syscall.SIGHUP:
+	default:
+	}
+}
+
+// handleReloads runs the reload hooks on every reload request until the context is done.
+func (a *AppLauncher) handleReloads(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sig := <-a.reloads:
+			if logger := a.logger(); logger != nil {
+				logger.Info("reload requested", "signal", sig.String())
+			}
+			_ = a.runReloadHooks(ctx)
+		}
+	}
+}
+
+// runReloadHooks calls every registered hook and logs its outcome.
+// It returns the joined errors of the failed hooks.
+func (a *AppLauncher) runReloadHooks(ctx context.Context) error {
+	a.reloadMu.Lock()
+	hooks := append([]reloadHook(nil), a.reloadHooks...)
+	a.reloadMu.Unlock()
+
+	var errs []error
+	for _, h := range hooks {
+		if ctx.Err() != nil {
+			break
+		}
+
+		startedAt := time.Now()
+		err := callTask(ctx, h.name, goture.Task(h.hook))
+		duration := time.Since(startedAt)
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("reload hook %q: %w", h.name, err))
+			a.handleTaskPanic(err)
+		}
+
+		logger := a.logger()
+		if logger == nil {
+			continue
+		}
+		if err != nil {
+			logger.Error("reload hook failed", "hook", h.name, "duration", duration, "error", err)
+		} else {
+			logger.Info("reload hook succeeded", "hook", h.name, "duration", duration)
+		}
+	}
+	return errors.Join(errs...)
+}
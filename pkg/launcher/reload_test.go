@@ -0,0 +1,102 @@
+package launcher
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/goregion/hexago/pkg/log"
+)
+
+// TestReloadHooks tests that a programmatic reload calls every hook and logs the outcomes
+func TestReloadHooks(t *testing.T) {
+	var logOutput bytes.Buffer
+	logger := log.NewLogger(log.NewJsonHandler(&logOutput))
+
+	var configReloads, credentialReloads int32
+	launcher := NewAppLauncher().
+		WithLoggerContext(logger).
+		OnReload("config", func(ctx context.Context) error {
+			atomic.AddInt32(&configReloads, 1)
+			return errors.New("invalid yaml")
+		}).
+		OnReload("credentials", func(ctx context.Context) error {
+			atomic.AddInt32(&credentialReloads, 1)
+			return nil
+		})
+
+	result := launcher.WaitApplication(func(ctx context.Context) error {
+		waitUntil(t, time.Second, launcher.Ready)
+		launcher.Reload()
+		waitUntil(t, time.Second, func() bool { return atomic.LoadInt32(&credentialReloads) == 1 })
+		return nil
+	})
+
+	if result.Error() != nil {
+		t.Fatalf("A failing reload hook must not stop the application, got: %v", result.Error())
+	}
+	if got := atomic.LoadInt32(&configReloads); got != 1 {
+		t.Errorf("Expected config hook to run once, got %d", got)
+	}
+
+	output := logOutput.String()
+	for _, expected := range []string{
+		`"msg":"reload hook failed","hook":"config"`,
+		`"error":"invalid yaml"`,
+		`"msg":"reload hook succeeded","hook":"credentials"`,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected log output to contain %s, got: %s", expected, output)
+		}
+	}
+}
+
+// TestReloadOnSIGHUP tests that SIGHUP triggers the hooks without stopping the tasks
+func TestReloadOnSIGHUP(t *testing.T) {
+	reloaded := make(chan struct{}, 1)
+	launcher := NewAppLauncher().
+		WithGracefulShutdown(0, time.Second)
+
+	result := launcher.WaitApplication(func(ctx context.Context) error {
+		// Hooks may be registered by running components
+		launcher.OnReload("component", func(ctx context.Context) error {
+			reloaded <- struct{}{}
+			return nil
+		})
+		waitUntil(t, time.Second, launcher.Ready)
+		syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
+
+		select {
+		case <-reloaded:
+		case <-time.After(time.Second):
+			t.Error("SIGHUP did not trigger the reload hook")
+		}
+		if ctx.Err() != nil {
+			t.Error("SIGHUP must not cancel the tasks")
+		}
+		return nil
+	})
+
+	if result.Error() != nil {
+		t.Errorf("Expected no error, got: %v", result.Error())
+	}
+}
+
+// TestReloadHookPanic tests that a panicking hook is reported as a failure
+func TestReloadHookPanic(t *testing.T) {
+	launcher := NewAppLauncher().
+		OnReload("broken", func(ctx context.Context) error { panic("boom") }).
+		OnReload("healthy", func(ctx context.Context) error { return nil })
+
+	err := launcher.runReloadHooks(context.Background())
+
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) || panicErr.Task != "broken" {
+		t.Errorf("Expected PanicError from the broken hook, got: %v", err)
+	}
+}
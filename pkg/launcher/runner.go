@@ -0,0 +1,383 @@
+// Package launcher provides the task runner used by all Wait* methods.
+// This file contains the code that starts tasks, tracks which of them are still
+// running and drives the shutdown sequence until every task has returned.
+package launcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/goregion/goture"
+	"github.com/goregion/hexago/pkg/log"
+)
+
+// namedTask is a task together with the name used in logs and errors.
+type namedTask struct {
+	name         string
+	task         goture.Task
+	managed      bool         // Started by the launcher itself and stopped once all application tasks return
+	dependsOn    []string     // Tasks that must be ready before this task starts
+	reportsReady bool         // The task calls MarkReady itself instead of being ready once started
+	validate     func() error // Checks the task definition before anything starts, optional
+}
+
+// taskExit is sent by a task goroutine when the task returns.
+type taskExit struct {
+	index            int
+	err              error
+	endedAt          time.Time
+	canceled         bool
+	dependencyFailed bool // A dependency exited before becoming ready, so the task never started
+}
+
+// shutdownPhase describes the progress of the shutdown sequence.
+type shutdownPhase int
+
+const (
+	phaseRunning  shutdownPhase = iota // Tasks are running normally
+	phaseDraining                      // Not ready, waiting for the drain period
+	phaseStopping                      // Task context canceled, waiting for tasks to return
+)
+
+// logger returns the logger stored by WithLoggerContext or nil if there is none.
+func (a *AppLauncher) logger() *log.Logger {
+	logger, err := log.GetLoggerFromContext(a.Context)
+	if err != nil {
+		return nil
+	}
+	return logger
+}
+
+// runTasks starts all tasks in parallel and waits until every task returns
+// or the shutdown sequence forces the exit. The result joins the errors of all
+// failed tasks; if all tasks succeed but the launcher context was canceled while
+// they were running, the cancellation cause is returned instead.
+func (a *AppLauncher) runTasks(tasks []namedTask) *AppResult {
+	if a.validateMode {
+		return a.validateNamedTasks(tasks)
+	}
+	if err := a.checkConfigs(); err != nil {
+		return &AppResult{Err: err}
+	}
+	if err := a.health.Err(); err != nil {
+		return &AppResult{Err: fmt.Errorf("invalid health check registration: %w", err)}
+	}
+	if err := a.validateTasks(tasks); err != nil {
+		return &AppResult{Err: err}
+	}
+
+	// Runs last, after the final shutdown records have been logged
+	defer a.flushLogs()
+
+	// Ensure cleanup if timeout was set
+	if a.cancelFunc != nil {
+		defer a.cancelFunc()
+	}
+
+	taskCtx, cancelTasks := context.WithCancel(a.Context)
+	defer cancelTasks()
+
+	if a.shutdown != nil {
+		signal.Notify(a.signals, syscall.SIGINT, syscall.SIGTERM)
+		defer signal.Stop(a.signals)
+	}
+
+	// SIGHUP reloads the configuration instead of terminating the process
+	signal.Notify(a.reloads, syscall.SIGHUP)
+	defer signal.Stop(a.reloads)
+	reloadsDone := make(chan struct{})
+	go func() {
+		defer close(reloadsDone)
+		a.handleReloads(taskCtx)
+	}()
+
+	tasks = append(tasks, a.managedTasks()...)
+
+	var (
+		running  = make(map[int]string, len(tasks))
+		outcomes = make([]TaskOutcome, len(tasks))
+		exits    = make(chan taskExit, len(tasks))
+	)
+
+	gates := newReadinessGates(tasks)
+
+	for i, t := range tasks {
+		running[i] = t.name
+		outcomes[i] = TaskOutcome{Name: t.name, Index: i, StartedAt: time.Now()}
+		go func(index int, t namedTask) {
+			// A task canceled while waiting for its dependencies never runs
+			started, err := gates.waitDependencies(taskCtx, index)
+			dependencyFailed := err != nil
+			if started {
+				if !t.reportsReady {
+					gates[index].markReady()
+				}
+				info := TaskInfo{Name: t.name, Index: index, StartedAt: time.Now()}
+				ctx, logger := withTaskContext(gates.withGate(taskCtx, index), info)
+				if a.repanic {
+					ctx = withPanicHandler(ctx, a.handlePanic)
+				}
+				if logger != nil {
+					logger.Info("task started", "task_index", index)
+				}
+				a.metrics.taskStarted()
+				err = callTask(ctx, t.name, t.task)
+				duration := time.Since(info.StartedAt)
+				a.metrics.taskFinished(t.name, duration, err)
+				logTaskStopped(logger, duration, err, taskCtx.Err() != nil)
+			}
+			a.handleTaskPanic(err)
+			gates[index].markExited()
+			exits <- taskExit{
+				index:            index,
+				err:              err,
+				endedAt:          time.Now(),
+				canceled:         taskCtx.Err() != nil,
+				dependencyFailed: dependencyFailed,
+			}
+		}(i, t)
+	}
+
+	defer a.ready.Store(false)
+
+	var (
+		parentErr     error
+		startupErr    error
+		phase         = phaseRunning
+		parentDone    = a.Context.Done()
+		allReady      = gates.allReady(taskCtx)
+		startupTimer  <-chan time.Time
+		drainTimer    <-chan time.Time
+		deadlineTimer <-chan time.Time
+		stopRequested time.Time // When the shutdown sequence began, zero while running
+	)
+
+	if a.startupTimeout > 0 {
+		startupTimer = time.After(a.startupTimeout)
+	}
+
+	requestStop := func() {
+		if stopRequested.IsZero() {
+			stopRequested = time.Now()
+		}
+	}
+
+	beginStop := func() {
+		requestStop()
+		phase = phaseStopping
+		parentDone = nil
+		cancelTasks()
+		if a.shutdown != nil && a.shutdown.deadline > 0 {
+			deadlineTimer = time.After(a.shutdown.deadline)
+		}
+	}
+
+	forceExit := func(reason string) *AppResult {
+		names := make([]string, 0, len(running))
+		for i := range tasks {
+			if name, ok := running[i]; ok {
+				names = append(names, name)
+				outcomes[i].Err = ErrShutdownDeadlineExceeded
+				outcomes[i].Canceled = true
+			}
+		}
+
+		if logger := a.logger(); logger != nil {
+			logger.Error("forcing exit with tasks still running",
+				"reason", reason,
+				"running_tasks", names,
+			)
+		}
+		a.metrics.shutdownFinished(time.Since(stopRequested))
+		deadlineErr := fmt.Errorf("%w (%s): tasks still running: %s",
+			ErrShutdownDeadlineExceeded, reason, strings.Join(names, ", "))
+		if startupErr != nil {
+			deadlineErr = errors.Join(startupErr, deadlineErr)
+		}
+		return newAppResult(outcomes, running, deadlineErr)
+	}
+
+	appRemaining := 0
+	for _, t := range tasks {
+		if !t.managed {
+			appRemaining++
+		}
+	}
+
+	for remaining := len(tasks); remaining > 0; {
+		select {
+		case exit := <-exits:
+			delete(running, exit.index)
+			remaining--
+			outcomes[exit.index].Err = exit.err
+			outcomes[exit.index].EndedAt = exit.endedAt
+			outcomes[exit.index].Canceled = exit.canceled
+
+			// Managed tasks only serve the application tasks and stop with them;
+			// a failing managed task stops the application tasks instead.
+			// A task whose dependency never became ready cannot run, so the launch fails.
+			if exit.dependencyFailed && phase != phaseStopping {
+				if logger := a.logger(); logger != nil {
+					logger.Error("task dependency exited before becoming ready, stopping tasks",
+						"task", tasks[exit.index].name,
+						"error", exit.err,
+					)
+				}
+				beginStop()
+			}
+			if tasks[exit.index].managed {
+				if exit.err != nil && phase != phaseStopping {
+					beginStop()
+				}
+			} else if appRemaining--; appRemaining == 0 && remaining > 0 && phase != phaseStopping {
+				beginStop()
+			}
+
+		case <-allReady:
+			allReady = nil
+			startupTimer = nil
+			if phase == phaseRunning {
+				a.ready.Store(true)
+			}
+
+		case <-startupTimer:
+			startupTimer = nil
+			if phase != phaseRunning {
+				break
+			}
+			// Tasks waiting for their dependencies are not to blame, unless
+			// every task is waiting.
+			notReady, waiting := gates.notReady()
+			if len(notReady) == 0 {
+				notReady = waiting
+			}
+			startupErr = newStartupError(a.startupTimeout, notReady)
+			if logger := a.logger(); logger != nil {
+				logger.Error("startup timeout exceeded, stopping tasks",
+					"timeout", a.startupTimeout,
+					"not_ready_tasks", notReady,
+					"waiting_tasks", waiting,
+				)
+			}
+			beginStop()
+
+		case sig := <-a.signals:
+			if phase != phaseRunning {
+				return forceExit("second signal " + sig.String())
+			}
+			a.ready.Store(false)
+			requestStop()
+			if logger := a.logger(); logger != nil {
+				logger.Info("shutdown signal received", "signal", sig.String())
+			}
+			if a.shutdown != nil && a.shutdown.drainPeriod > 0 {
+				phase = phaseDraining
+				drainTimer = time.After(a.shutdown.drainPeriod)
+			} else {
+				beginStop()
+			}
+
+		case <-drainTimer:
+			drainTimer = nil
+			beginStop()
+
+		case <-parentDone:
+			// The launcher context was canceled elsewhere (timeout, grexit, parent):
+			// tasks are already canceled, only the deadline is left to enforce.
+			parentErr = context.Cause(a.Context)
+			a.ready.Store(false)
+			drainTimer = nil
+			beginStop()
+
+		case <-deadlineTimer:
+			if a.stackDump {
+				a.logStackDump()
+			}
+			return forceExit("deadline " + a.shutdown.deadline.String())
+		}
+	}
+
+	// All tasks have returned: let a reload in progress finish before returning.
+	// A forced exit does not wait, since hooks are as likely to hang as tasks.
+	cancelTasks()
+	<-reloadsDone
+
+	if !stopRequested.IsZero() {
+		a.metrics.shutdownFinished(time.Since(stopRequested))
+	}
+
+	if startupErr != nil {
+		return newAppResult(outcomes, running, startupErr)
+	}
+	return newAppResult(outcomes, running, parentErr)
+}
+
+// newAppResult builds the result from task outcomes. Errors of finished tasks are
+// joined in launch order; tasks still running are represented by extraErr only.
+// If no finished task failed, extraErr alone becomes the result error; shutdown
+// deadline and startup timeout errors are always part of the result.
+func newAppResult(outcomes []TaskOutcome, running map[int]string, extraErr error) *AppResult {
+	var errs []error
+	for _, outcome := range outcomes {
+		if _, abandoned := running[outcome.Index]; !abandoned && outcome.Err != nil {
+			errs = append(errs, outcome.Err)
+		}
+	}
+	if extraErr != nil && (len(errs) == 0 ||
+		errors.Is(extraErr, ErrShutdownDeadlineExceeded) || errors.Is(extraErr, ErrStartupTimeout)) {
+		errs = append(errs, extraErr)
+	}
+
+	result := &AppResult{Tasks: outcomes}
+	switch len(errs) {
+	case 0:
+	case 1:
+		result.Err = errs[0]
+	default:
+		result.Err = errors.Join(errs...)
+	}
+	return result
+}
+
+// validateTasks checks the definitions of the tasks that can be validated before anything
+// starts and rejects tasks named after a managed task.
+func (a *AppLauncher) validateTasks(tasks []namedTask) error {
+	var errs []error
+	for _, t := range tasks {
+		if err := a.reservedTaskName(t.name); err != nil {
+			errs = append(errs, err)
+		}
+		if t.validate == nil {
+			continue
+		}
+		if err := t.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("task %q is invalid: %w", t.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// reservedTaskName returns an error if name is the name of a managed task, such as
+// the admin server. Tasks are identified by name in dependencies, logs and results.
+func (a *AppLauncher) reservedTaskName(name string) error {
+	for _, t := range a.managedTasks() {
+		if t.name == name {
+			return fmt.Errorf("task name %q is reserved for a task managed by the launcher", name)
+		}
+	}
+	return nil
+}
+
+// defaultTaskNames wraps anonymous tasks into named tasks using their index.
+func defaultTaskNames(tasks []goture.Task) []namedTask {
+	named := make([]namedTask, len(tasks))
+	for i, task := range tasks {
+		named[i] = namedTask{name: fmt.Sprintf("task-%d", i), task: task}
+	}
+	return named
+}
@@ -0,0 +1,272 @@
+// Package launcher provides periodic and cron-scheduled tasks.
+// This file contains the ScheduledTask type that runs a job on a fixed interval
+// or a cron schedule with an overlap policy, jitter and per-run structured logs.
+package launcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/goregion/goture"
+	"github.com/goregion/hexago/pkg/log"
+)
+
+// OverlapPolicy defines what happens when a scheduled run is due
+// while the previous run of the same task is still in progress.
+type OverlapPolicy int
+
+const (
+	// OverlapSkip drops the due run. This is the default.
+	OverlapSkip OverlapPolicy = iota
+	// OverlapQueue defers the due run until the previous runs have finished.
+	// Queued runs are executed one after another. At most MaxQueued runs wait,
+	// further due runs are dropped.
+	OverlapQueue
+	// OverlapConcurrent starts the due run next to the runs still in progress.
+	OverlapConcurrent
+)
+
+// String returns the policy name used in logs.
+func (p OverlapPolicy) String() string {
+	switch p {
+	case OverlapSkip:
+		return "skip"
+	case OverlapQueue:
+		return "queue"
+	case OverlapConcurrent:
+		return "concurrent"
+	default:
+		return fmt.Sprintf("OverlapPolicy(%d)", int(p))
+	}
+}
+
+// ScheduledTask describes a job that runs periodically, either every Interval or
+// according to a Cron expression. Exactly one of them must be set.
+//
+// Failed runs are logged and do not stop the schedule; Run returns only when its
+// context is done, after the runs in progress have finished.
+//
+// Example:
+//
+//	rollup := launcher.ScheduledTask{
+//		Name:       "ohlc-rollup",
+//		Cron:       "* * * * *",
+//		Location:   time.UTC,
+//		Overlap:    launcher.OverlapSkip,
+//		Jitter:     5 * time.Second,
+//		RunOnStart: true,
+//		Job:        generator.RollUp,
+//	}
+//
+//	launcher.NewAppLauncher().
+//		WithLoggerContext(logger).
+//		WithGrexitContext().
+//		WaitApplications(consumer.Run, rollup.Run).
+//		LogIfError(logger, "Application stopped")
+type ScheduledTask struct {
+	Name       string         // Task name used in logs and errors
+	Interval   time.Duration  // Fixed interval between runs
+	Cron       string         // Five-field cron expression, see ParseCron
+	Location   *time.Location // Timezone of the cron expression, nil means time.Local
+	Overlap    OverlapPolicy  // What to do when a run is due while another is in progress
+	MaxQueued  int            // Runs queued by OverlapQueue at most, zero means one
+	Jitter     time.Duration  // Maximum random delay added to every run
+	RunOnStart bool           // Run once immediately when the task starts
+	Job        goture.Task    // Work executed on every run
+}
+
+// Validate checks the task configuration, including the cron expression.
+func (s ScheduledTask) Validate() error {
+	_, err := s.schedule()
+	return err
+}
+
+// Task returns the scheduled task as a Task for WaitTasks, so that its configuration,
+// including the cron expression, is checked before anything starts and in validate mode.
+// Passing Run to WaitApplications instead defers these errors until the task starts.
+func (s ScheduledTask) Task() Task {
+	return Task{Name: s.Name, Run: s.Run, Validate: s.Validate}
+}
+
+// schedule validates the configuration and builds the activation schedule.
+func (s ScheduledTask) schedule() (Schedule, error) {
+	if s.Name == "" {
+		return nil, errors.New("scheduled task name cannot be empty")
+	}
+	if s.Job == nil {
+		return nil, fmt.Errorf("scheduled task %q: job cannot be nil", s.Name)
+	}
+	if s.Jitter < 0 {
+		return nil, fmt.Errorf("scheduled task %q: jitter cannot be negative", s.Name)
+	}
+	if s.Overlap < OverlapSkip || s.Overlap > OverlapConcurrent {
+		return nil, fmt.Errorf("scheduled task %q: unknown overlap policy %v", s.Name, s.Overlap)
+	}
+	if s.MaxQueued < 0 {
+		return nil, fmt.Errorf("scheduled task %q: max queued runs cannot be negative", s.Name)
+	}
+
+	switch {
+	case s.Interval != 0 && s.Cron != "":
+		return nil, fmt.Errorf("scheduled task %q: interval and cron are mutually exclusive", s.Name)
+	case s.Interval < 0:
+		return nil, fmt.Errorf("scheduled task %q: interval must be positive", s.Name)
+	case s.Interval > 0:
+		return Every(s.Interval), nil
+	case s.Cron != "":
+		schedule, err := ParseCron(s.Cron, s.Location)
+		if err != nil {
+			return nil, fmt.Errorf("scheduled task %q: %w", s.Name, err)
+		}
+		if schedule.Next(time.Now()).IsZero() {
+			return nil, fmt.Errorf("scheduled task %q: cron expression %q never fires", s.Name, s.Cron)
+		}
+		return schedule, nil
+	default:
+		return nil, fmt.Errorf("scheduled task %q: either interval or cron must be set", s.Name)
+	}
+}
+
+// Run executes the job on schedule until the context is done.
+// Run has the goture.Task signature, so a scheduled task can be passed
+// anywhere a task is expected. It returns an error only for an invalid configuration.
+func (s ScheduledTask) Run(ctx context.Context) error {
+	schedule, err := s.schedule()
+	if err != nil {
+		return err
+	}
+
+	runner := &scheduleRunner{task: s}
+	defer runner.wg.Wait()
+
+	if s.RunOnStart {
+		runner.trigger(ctx, time.Now())
+	}
+
+	next := schedule.Next(time.Now())
+	for !next.IsZero() {
+		timer := time.NewTimer(time.Until(next) + s.jitter())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+
+		runner.trigger(ctx, next)
+
+		// Activations missed while the process was busy or suspended are not replayed.
+		now := time.Now()
+		if next = schedule.Next(next); !next.IsZero() && next.Before(now) {
+			next = schedule.Next(now)
+		}
+	}
+
+	<-ctx.Done()
+	return nil
+}
+
+// jitter returns a random delay in [0, Jitter).
+func (s ScheduledTask) jitter() time.Duration {
+	if s.Jitter <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(s.Jitter)))
+}
+
+// scheduleRunner tracks the runs of a single scheduled task.
+type scheduleRunner struct {
+	task    ScheduledTask
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	runs    int // Number of started runs, used as the run number in logs
+	running int // Runs in progress
+	pending int // Runs queued by OverlapQueue
+}
+
+// trigger starts a due run or applies the overlap policy if a run is in progress.
+func (r *scheduleRunner) trigger(ctx context.Context, scheduledAt time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.running == 0 || r.task.Overlap == OverlapConcurrent {
+		r.start(ctx, scheduledAt)
+		return
+	}
+
+	dropped := r.task.Overlap != OverlapQueue || r.pending >= r.task.maxQueued()
+	if !dropped {
+		r.pending++
+	}
+
+	if logger, err := log.GetLoggerFromContext(ctx); err == nil {
+		logger.Warn("scheduled run overlaps with a run in progress",
+			"task", r.task.Name,
+			"scheduled_at", scheduledAt,
+			"overlap", r.task.Overlap.String(),
+			"queued_runs", r.pending,
+			"dropped", dropped,
+		)
+	}
+}
+
+// maxQueued returns the number of runs OverlapQueue keeps waiting at most.
+func (s ScheduledTask) maxQueued() int {
+	if s.MaxQueued <= 0 {
+		return 1
+	}
+	return s.MaxQueued
+}
+
+// start runs the job in a new goroutine. The caller must hold r.mu.
+func (r *scheduleRunner) start(ctx context.Context, scheduledAt time.Time) {
+	r.runs++
+	r.running++
+	r.wg.Add(1)
+
+	go func(run int) {
+		defer r.wg.Done()
+		r.execute(ctx, run, scheduledAt)
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.running--
+		if r.pending > 0 && ctx.Err() == nil {
+			r.pending--
+			r.start(ctx, time.Now())
+		}
+	}(r.runs)
+}
+
+// execute runs the job once and logs its duration and outcome.
+func (r *scheduleRunner) execute(ctx context.Context, run int, scheduledAt time.Time) {
+	startedAt := time.Now()
+	err := callTask(ctx, r.task.Name, r.task.Job)
+	// Runs after the run has been logged
+	defer handleContextPanic(ctx, err)
+
+	logger, logErr := log.GetLoggerFromContext(ctx)
+	if logErr != nil {
+		return
+	}
+
+	attrs := []any{
+		"task", r.task.Name,
+		"run", run,
+		"scheduled_at", scheduledAt,
+		"duration", time.Since(startedAt),
+	}
+	if err != nil {
+		var panicErr *PanicError
+		if errors.As(err, &panicErr) {
+			attrs = append(attrs, "stack", string(panicErr.Stack))
+		}
+		logger.Error("scheduled run failed", append(attrs, "error", err)...)
+		return
+	}
+	logger.Info("scheduled run finished", attrs...)
+}
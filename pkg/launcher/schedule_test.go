@@ -0,0 +1,234 @@
+package launcher
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/goregion/hexago/pkg/log"
+)
+
+// TestScheduledTaskInterval tests that the job runs on every interval and stops with the context
+func TestScheduledTaskInterval(t *testing.T) {
+	var runs int32
+	task := ScheduledTask{
+		Name:     "ticker",
+		Interval: 10 * time.Millisecond,
+		Job: func(ctx context.Context) error {
+			atomic.AddInt32(&runs, 1)
+			return nil
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 55*time.Millisecond)
+	defer cancel()
+
+	if err := task.Run(ctx); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if got := atomic.LoadInt32(&runs); got < 3 || got > 6 {
+		t.Errorf("Expected about 5 runs, got %d", got)
+	}
+}
+
+// TestScheduledTaskRunOnStart tests the run-immediately flag
+func TestScheduledTaskRunOnStart(t *testing.T) {
+	started := make(chan time.Time, 1)
+	task := ScheduledTask{
+		Name:       "warmup",
+		Interval:   time.Hour,
+		RunOnStart: true,
+		Job: func(ctx context.Context) error {
+			started <- time.Now()
+			return nil
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go task.Run(ctx)
+
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the job to run immediately")
+	}
+}
+
+// TestScheduledTaskOverlap tests skip, queue and concurrent overlap policies
+func TestScheduledTaskOverlap(t *testing.T) {
+	tests := []struct {
+		policy        OverlapPolicy
+		maxQueued     int
+		minRuns       int32
+		maxRuns       int32
+		maxConcurrent int32
+	}{
+		{OverlapSkip, 0, 2, 4, 1},
+		{OverlapQueue, 10, 6, 10, 1},
+		{OverlapConcurrent, 0, 6, 10, 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy.String(), func(t *testing.T) {
+			var runs, active, maxActive int32
+			task := ScheduledTask{
+				Name:       "slow",
+				Interval:   10 * time.Millisecond,
+				Overlap:    tt.policy,
+				MaxQueued:  tt.maxQueued,
+				RunOnStart: true,
+				Job: func(ctx context.Context) error {
+					run := atomic.AddInt32(&runs, 1)
+					current := atomic.AddInt32(&active, 1)
+					defer atomic.AddInt32(&active, -1)
+					for {
+						observed := atomic.LoadInt32(&maxActive)
+						if current <= observed || atomic.CompareAndSwapInt32(&maxActive, observed, current) {
+							break
+						}
+					}
+					// Only the first run is slow, so the overlap policy decides what
+					// happens with the activations that fire while it is in progress
+					if run == 1 {
+						time.Sleep(60 * time.Millisecond)
+					}
+					return nil
+				},
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 85*time.Millisecond)
+			defer cancel()
+
+			start := time.Now()
+			if err := task.Run(ctx); err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if elapsed := time.Since(start); elapsed > 300*time.Millisecond {
+				t.Errorf("Run did not return promptly after cancellation: %v", elapsed)
+			}
+
+			if got := atomic.LoadInt32(&runs); got < tt.minRuns || got > tt.maxRuns {
+				t.Errorf("Expected %d-%d runs, got %d", tt.minRuns, tt.maxRuns, got)
+			}
+			if got := atomic.LoadInt32(&maxActive); got > tt.maxConcurrent || (tt.policy == OverlapConcurrent && got < 2) {
+				t.Errorf("Unexpected concurrency %d for policy %v", got, tt.policy)
+			}
+		})
+	}
+}
+
+// TestScheduledTaskQueueBounded tests that OverlapQueue keeps at most MaxQueued runs waiting
+func TestScheduledTaskQueueBounded(t *testing.T) {
+	for _, maxQueued := range []int{0, 3} {
+		release := make(chan struct{})
+		var runs atomic.Int32
+		runner := &scheduleRunner{task: ScheduledTask{
+			Name:      "slow",
+			Overlap:   OverlapQueue,
+			MaxQueued: maxQueued,
+			Job: func(ctx context.Context) error {
+				runs.Add(1)
+				<-release
+				return nil
+			},
+		}}
+
+		for i := 0; i < 20; i++ {
+			runner.trigger(context.Background(), time.Now())
+		}
+		runner.mu.Lock()
+		pending := runner.pending
+		runner.mu.Unlock()
+		if expected := max(maxQueued, 1); pending != expected {
+			t.Errorf("MaxQueued %d: expected %d queued runs, got %d", maxQueued, expected, pending)
+		}
+
+		close(release)
+		runner.wg.Wait()
+		if expected := int32(max(maxQueued, 1) + 1); runs.Load() != expected {
+			t.Errorf("MaxQueued %d: expected %d runs, got %d", maxQueued, expected, runs.Load())
+		}
+	}
+
+	if err := (ScheduledTask{Name: "bad", Interval: time.Second, MaxQueued: -1, Job: func(context.Context) error { return nil }}).Validate(); err == nil {
+		t.Error("Expected an error for negative MaxQueued")
+	}
+}
+
+// TestScheduledTaskRunLogs tests that every run is logged with its duration and error
+func TestScheduledTaskRunLogs(t *testing.T) {
+	var logOutput bytes.Buffer
+	logger := log.NewLogger(log.NewJsonHandler(&logOutput))
+
+	var runs int32
+	task := ScheduledTask{
+		Name:       "rollup",
+		Interval:   20 * time.Millisecond,
+		RunOnStart: true,
+		Job: func(ctx context.Context) error {
+			if atomic.AddInt32(&runs, 1) == 1 {
+				return errors.New("upstream unavailable")
+			}
+			return nil
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(log.WithLoggerContext(context.Background(), logger), 30*time.Millisecond)
+	defer cancel()
+	if err := task.Run(ctx); err != nil {
+		t.Fatalf("A failed run must not stop the schedule, got: %v", err)
+	}
+
+	output := logOutput.String()
+	for _, expected := range []string{
+		`"msg":"scheduled run failed"`,
+		`"error":"upstream unavailable"`,
+		`"msg":"scheduled run finished"`,
+		`"task":"rollup"`,
+		`"duration":`,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected log output to contain %s, got: %s", expected, output)
+		}
+	}
+}
+
+// TestScheduledTaskValidate tests configuration validation
+func TestScheduledTaskValidate(t *testing.T) {
+	job := func(ctx context.Context) error { return nil }
+
+	valid := []ScheduledTask{
+		{Name: "interval", Interval: time.Second, Job: job},
+		{Name: "cron", Cron: "*/5 * * * *", Location: time.UTC, Job: job},
+	}
+	for _, task := range valid {
+		if err := task.Validate(); err != nil {
+			t.Errorf("%s: unexpected error: %v", task.Name, err)
+		}
+	}
+
+	invalid := map[string]ScheduledTask{
+		"empty name":     {Interval: time.Second, Job: job},
+		"nil job":        {Name: "x", Interval: time.Second},
+		"no schedule":    {Name: "x", Job: job},
+		"both schedules": {Name: "x", Interval: time.Second, Cron: "* * * * *", Job: job},
+		"negative":       {Name: "x", Interval: -time.Second, Job: job},
+		"bad cron":       {Name: "x", Cron: "61 * * * *", Job: job},
+		"never fires":    {Name: "x", Cron: "0 0 31 apr *", Job: job},
+		"bad jitter":     {Name: "x", Interval: time.Second, Jitter: -1, Job: job},
+		"bad overlap":    {Name: "x", Interval: time.Second, Overlap: OverlapPolicy(9), Job: job},
+	}
+	for name, task := range invalid {
+		if err := task.Validate(); err == nil {
+			t.Errorf("%s: expected validation error", name)
+		}
+		if err := task.Run(context.Background()); err == nil {
+			t.Errorf("%s: expected Run to fail", name)
+		}
+	}
+}
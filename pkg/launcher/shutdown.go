@@ -0,0 +1,74 @@
+// Package launcher provides the graceful shutdown sequence for launched applications.
+// This file contains the shutdown configuration and the signal handling that
+// drains in-flight work before canceling tasks, bounds the total shutdown time and
+// flushes buffered log records once the tasks have stopped.
+package launcher
+
+import (
+	"context"
+	"errors"
+	"syscall"
+	"time"
+)
+
+// logFlushTimeout bounds the flush of buffered log records when the tasks have stopped.
+const logFlushTimeout = 5 * time.Second
+
+// ErrShutdownDeadlineExceeded is returned when tasks are still running after the
+// hard shutdown deadline, or when a second termination signal forces the exit.
+var ErrShutdownDeadlineExceeded = errors.New("shutdown deadline exceeded")
+
+// shutdownConfig holds the parameters of the graceful shutdown sequence.
+type shutdownConfig struct {
+	drainPeriod time.Duration // Time between the not-ready mark and task cancellation
+	deadline    time.Duration // Time tasks have to finish after cancellation, zero means no limit
+}
+
+// WithGracefulShutdown enables the graceful shutdown sequence for launched applications.
+// On SIGINT or SIGTERM (or a Shutdown call) the launcher:
+//  1. marks the application as not ready (see Ready),
+//  2. waits for drainPeriod so that in-flight work can finish,
+//  3. cancels the task context,
+//  4. waits up to deadline for the tasks to return and then stops waiting,
+//     logging the names of the tasks that were still running.
+//
+// A second signal skips the remaining steps and forces the exit immediately.
+// The sequence handles termination signals itself, so it should be used instead of
+// WithGrexitContext: a grexit context is canceled on the first signal and skips the drain phase.
+// Returns the same launcher instance for method chaining (fluent API).
+func (a *AppLauncher) WithGracefulShutdown(drainPeriod, deadline time.Duration) *AppLauncher {
+	a.shutdown = &shutdownConfig{
+		drainPeriod: max(drainPeriod, 0),
+		deadline:    max(deadline, 0),
+	}
+	return a
+}
+
+// Shutdown triggers the shutdown sequence of the running applications from code,
+// exactly as if SIGTERM was received. Calling it twice forces the exit.
+// It never blocks and is safe to call from any goroutine.
+func (a *AppLauncher) Shutdown() {
+	select {
+	case a.signals <- syscall.SIGTERM:
+	default:
+	}
+}
+
+// Ready reports whether the launched applications are running and not shutting down.
+// It becomes true once every task is ready (see MarkReady) and false as soon as the shutdown sequence begins.
+func (a *AppLauncher) Ready() bool {
+	return a.ready.Load()
+}
+
+// flushLogs waits for the buffering handlers of the context logger, such as
+// log.AsyncHandler, to write the records logged so far. The handlers are flushed
+// rather than closed because the caller usually logs the result after the launch.
+func (a *AppLauncher) flushLogs() {
+	logger := a.logger()
+	if logger == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(a.Context), logFlushTimeout)
+	defer cancel()
+	_ = logger.Flush(ctx) // Nowhere to report a failed flush, the logger itself is affected
+}
@@ -0,0 +1,210 @@
+package launcher
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/goregion/hexago/pkg/log"
+)
+
+// waitUntil polls the condition until it is true or the timeout expires
+func waitUntil(t *testing.T, timeout time.Duration, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// TestGracefulShutdownDrain tests that tasks keep running during the drain period
+func TestGracefulShutdownDrain(t *testing.T) {
+	launcher := NewAppLauncher().WithGracefulShutdown(50*time.Millisecond, time.Second)
+
+	var canceledAt atomic.Int64
+	task := func(ctx context.Context) error {
+		<-ctx.Done()
+		canceledAt.Store(time.Now().UnixNano())
+		return ctx.Err()
+	}
+
+	done := make(chan *AppResult, 1)
+	go func() { done <- launcher.WaitApplication(task) }()
+
+	waitUntil(t, time.Second, launcher.Ready)
+	shutdownAt := time.Now()
+	launcher.Shutdown()
+
+	waitUntil(t, time.Second, func() bool { return !launcher.Ready() })
+	if canceledAt.Load() != 0 {
+		t.Error("Task was canceled before the drain period elapsed")
+	}
+
+	result := <-done
+	if !errors.Is(result.Error(), context.Canceled) {
+		t.Errorf("Expected task cancellation error, got: %v", result.Error())
+	}
+	if drained := time.Unix(0, canceledAt.Load()).Sub(shutdownAt); drained < 50*time.Millisecond {
+		t.Errorf("Task was canceled after %v, expected at least the drain period", drained)
+	}
+}
+
+// TestGracefulShutdownDeadline tests that stuck tasks are abandoned after the deadline
+func TestGracefulShutdownDeadline(t *testing.T) {
+	var logOutput strings.Builder
+	logger := log.NewLogger(log.NewTextHandler(&logOutput))
+
+	launcher := NewAppLauncher().
+		WithLoggerContext(logger).
+		WithGracefulShutdown(0, 30*time.Millisecond)
+
+	stuck := func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	}
+	polite := func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	}
+
+	time.AfterFunc(10*time.Millisecond, launcher.Shutdown)
+
+	start := time.Now()
+	result := launcher.WaitApplications(polite, stuck)
+	duration := time.Since(start)
+
+	if !errors.Is(result.Error(), ErrShutdownDeadlineExceeded) {
+		t.Fatalf("Expected ErrShutdownDeadlineExceeded, got: %v", result.Error())
+	}
+	if !strings.Contains(result.Error().Error(), "task-1") || strings.Contains(result.Error().Error(), "task-0") {
+		t.Errorf("Expected only the stuck task to be reported, got: %v", result.Error())
+	}
+	if duration > 200*time.Millisecond {
+		t.Errorf("Deadline was not enforced, took %v", duration)
+	}
+	if !strings.Contains(logOutput.String(), "running_tasks=[task-1]") {
+		t.Errorf("Expected running tasks in log output, got: %s", logOutput.String())
+	}
+}
+
+// TestGracefulShutdownSecondSignal tests that a second signal skips the drain period
+func TestGracefulShutdownSecondSignal(t *testing.T) {
+	launcher := NewAppLauncher().WithGracefulShutdown(time.Second, time.Second)
+
+	task := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	go func() {
+		waitUntil(t, time.Second, launcher.Ready)
+		launcher.Shutdown()
+		time.Sleep(10 * time.Millisecond)
+		launcher.Shutdown()
+	}()
+
+	start := time.Now()
+	result := launcher.WaitApplication(task)
+
+	if !errors.Is(result.Error(), ErrShutdownDeadlineExceeded) {
+		t.Errorf("Expected forced exit, got: %v", result.Error())
+	}
+	if duration := time.Since(start); duration > 500*time.Millisecond {
+		t.Errorf("Second signal did not force the exit, took %v", duration)
+	}
+}
+
+// TestGracefulShutdownOnSignal tests the sequence triggered by a real SIGTERM
+func TestGracefulShutdownOnSignal(t *testing.T) {
+	launcher := NewAppLauncher().WithGracefulShutdown(0, time.Second)
+
+	task := func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	}
+
+	go func() {
+		waitUntil(t, time.Second, launcher.Ready)
+		syscall.Kill(syscall.Getpid(), syscall.SIGTERM)
+	}()
+
+	result := launcher.WaitApplication(task)
+	if result.Error() != nil {
+		t.Errorf("Expected clean shutdown, got: %v", result.Error())
+	}
+	if launcher.Ready() {
+		t.Error("Launcher must not be ready after shutdown")
+	}
+}
+
+// TestGracefulShutdownParentCancel tests that the deadline applies to external cancellation
+func TestGracefulShutdownParentCancel(t *testing.T) {
+	stuck := func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	}
+
+	start := time.Now()
+	result := NewAppLauncher().
+		WithGracefulShutdown(time.Second, 20*time.Millisecond).
+		WithTimeout(10 * time.Millisecond).
+		WaitApplication(stuck)
+
+	if !errors.Is(result.Error(), ErrShutdownDeadlineExceeded) {
+		t.Errorf("Expected ErrShutdownDeadlineExceeded, got: %v", result.Error())
+	}
+	if duration := time.Since(start); duration > 200*time.Millisecond {
+		t.Errorf("Deadline was not enforced, took %v", duration)
+	}
+}
+
+// slowWriter is a concurrency-safe writer that takes a while for every write
+type slowWriter struct {
+	mu     sync.Mutex
+	output strings.Builder
+}
+
+func (w *slowWriter) Write(p []byte) (int, error) {
+	time.Sleep(5 * time.Millisecond)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.output.Write(p)
+}
+
+func (w *slowWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.output.String()
+}
+
+// TestShutdownFlushesAsyncLogs tests that buffered log records are written before the launch returns
+func TestShutdownFlushesAsyncLogs(t *testing.T) {
+	writer := &slowWriter{}
+	logger := log.NewLogger(log.NewAsyncHandler(log.NewJsonHandler(writer), log.AsyncOptions{}))
+	defer logger.Close()
+
+	result := NewAppLauncher().
+		WithLoggerContext(logger).
+		WaitApplication(func(ctx context.Context) error {
+			for i := 0; i < 5; i++ {
+				log.MustGetLoggerFromContext(ctx).Info("working", "step", i)
+			}
+			return nil
+		})
+	if result.Error() != nil {
+		t.Fatalf("Expected no error, got: %v", result.Error())
+	}
+
+	output := writer.String()
+	if strings.Count(output, `"msg":"working"`) != 5 || !strings.Contains(output, `"msg":"task stopped"`) {
+		t.Errorf("Expected every record to be written when the launch returns, got:\n%s", output)
+	}
+}
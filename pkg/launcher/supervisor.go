@@ -0,0 +1,362 @@
+// Package launcher provides supervision primitives for long-running tasks.
+// This file contains the Supervisor type that restarts failed tasks according
+// to per-task restart policies and escalates to the launcher only when a task
+// exhausts its restart budget.
+package launcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/goregion/goture"
+	"github.com/goregion/hexago/pkg/log"
+)
+
+// ErrRestartBudgetExceeded is returned by Supervisor.Run when a supervised task
+// fails more often than its restart policy allows. The error returned by the
+// supervisor wraps both this sentinel and the last task error.
+var ErrRestartBudgetExceeded = errors.New("restart budget exceeded")
+
+// RestartMode defines when a supervised task is restarted after it returns.
+type RestartMode int
+
+const (
+	// RestartNever never restarts the task. A failure escalates immediately.
+	RestartNever RestartMode = iota
+	// RestartOnFailure restarts the task only when it returns a non-nil error.
+	RestartOnFailure
+	// RestartAlways restarts the task whenever it returns, even without an error.
+	RestartAlways
+)
+
+// String returns a human-readable name of the restart mode for logging.
+func (m RestartMode) String() string {
+	switch m {
+	case RestartNever:
+		return "never"
+	case RestartOnFailure:
+		return "on-failure"
+	case RestartAlways:
+		return "always"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(m))
+	}
+}
+
+// maxBackoffDelay caps the delay of a Backoff without Max.
+const maxBackoffDelay = time.Hour
+
+// maxTrackedRestarts bounds the restart timestamps kept for policies without MaxRestarts.
+// The backoff reaches its cap long before that many attempts.
+const maxTrackedRestarts = 64
+
+// Backoff describes an exponential backoff with jitter.
+// The delay before the n-th retry (starting from zero) is
+// Initial * Multiplier^n, capped at Max and randomized by ±Jitter.
+type Backoff struct {
+	Initial    time.Duration // Delay before the first retry
+	Max        time.Duration // Upper bound for a single delay, zero means maxBackoffDelay
+	Multiplier float64       // Growth factor between retries, values below 1 are treated as 1
+	Jitter     float64       // Randomization factor in range [0, 1]
+}
+
+// DefaultBackoff returns a backoff suitable for most network-bound tasks:
+// 100ms doubling up to 30s with 20% jitter.
+func DefaultBackoff() Backoff {
+	return Backoff{
+		Initial:    100 * time.Millisecond,
+		Max:        30 * time.Second,
+		Multiplier: 2,
+		Jitter:     0.2,
+	}
+}
+
+// Delay calculates the delay before the retry with the given zero-based attempt number.
+func (b Backoff) Delay(attempt int) time.Duration {
+	if b.Initial <= 0 {
+		return 0
+	}
+
+	multiplier := b.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	limit := b.Max
+	if limit <= 0 {
+		limit = maxBackoffDelay
+	}
+
+	// The power overflows to +Inf after enough attempts, so clamp before converting
+	delay := float64(b.Initial) * math.Pow(multiplier, float64(attempt))
+	if delay > float64(limit) {
+		delay = float64(limit)
+	}
+
+	if jitter := math.Min(math.Max(b.Jitter, 0), 1); jitter > 0 {
+		delay += delay * jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(delay)
+}
+
+// RestartPolicy configures how a supervised task is restarted and
+// how many restarts are tolerated before escalating to the launcher.
+type RestartPolicy struct {
+	Mode        RestartMode   // When to restart the task
+	Backoff     Backoff       // Delay between restarts
+	MaxRestarts int           // Maximum restarts within Window, zero means unlimited
+	Window      time.Duration // Sliding window for MaxRestarts, zero means the whole lifetime
+}
+
+// Validate checks the policy: a known mode and no negative limits or delays.
+func (p RestartPolicy) Validate() error {
+	var errs []error
+	if p.Mode < RestartNever || p.Mode > RestartAlways {
+		errs = append(errs, fmt.Errorf("unknown restart mode %v", p.Mode))
+	}
+	if p.MaxRestarts < 0 {
+		errs = append(errs, errors.New("max restarts cannot be negative"))
+	}
+	if p.Window < 0 {
+		errs = append(errs, errors.New("restart window cannot be negative"))
+	}
+	if p.Backoff.Initial < 0 || p.Backoff.Max < 0 {
+		errs = append(errs, errors.New("backoff delays cannot be negative"))
+	}
+	if p.Backoff.Jitter < 0 || p.Backoff.Jitter > 1 {
+		errs = append(errs, fmt.Errorf("backoff jitter %v is out of range [0, 1]", p.Backoff.Jitter))
+	}
+	return errors.Join(errs...)
+}
+
+// DefaultRestartPolicy returns a policy that restarts failed tasks with
+// the default backoff and escalates after 5 restarts within one minute.
+func DefaultRestartPolicy() RestartPolicy {
+	return RestartPolicy{
+		Mode:        RestartOnFailure,
+		Backoff:     DefaultBackoff(),
+		MaxRestarts: 5,
+		Window:      time.Minute,
+	}
+}
+
+// supervisedTask holds a task together with its restart policy.
+type supervisedTask struct {
+	name   string
+	task   goture.Task
+	policy RestartPolicy
+}
+
+// Supervisor runs a set of named tasks and restarts them according to their
+// restart policies. A task that exhausts its restart budget escalates: all other
+// supervised tasks are canceled and Run returns the escalation error.
+//
+// Example:
+//
+//	supervisor := launcher.NewSupervisor().
+//		Add("redis-consumer", consumer.Run, launcher.DefaultRestartPolicy()).
+//		Add("http-server", server.Run, launcher.RestartPolicy{Mode: launcher.RestartNever})
+//
+//	launcher.NewAppLauncher().
+//		WithLoggerContext(logger).
+//		WithGrexitContext().
+//		WaitSupervisor(supervisor).
+//		LogIfError(logger, "Application stopped")
+type Supervisor struct {
+	tasks []supervisedTask
+	names map[string]struct{}
+	err   error // First configuration error, reported by Run
+}
+
+// NewSupervisor creates an empty supervisor.
+func NewSupervisor() *Supervisor {
+	return &Supervisor{
+		names: make(map[string]struct{}),
+	}
+}
+
+// Add registers a named task with its restart policy.
+// Configuration errors (nil task, empty or duplicate name) are reported by Run.
+// Returns the same supervisor instance for method chaining (fluent API).
+func (s *Supervisor) Add(name string, task goture.Task, policy RestartPolicy) *Supervisor {
+	if s.err != nil {
+		return s
+	}
+
+	switch {
+	case name == "":
+		s.err = errors.New("supervised task name cannot be empty")
+	case task == nil:
+		s.err = fmt.Errorf("supervised task %q cannot be nil", name)
+	default:
+		if _, exists := s.names[name]; exists {
+			s.err = fmt.Errorf("supervised task %q is already registered", name)
+			break
+		}
+		s.names[name] = struct{}{}
+		s.tasks = append(s.tasks, supervisedTask{name: name, task: task, policy: policy})
+	}
+	return s
+}
+
+// Validate checks the registered tasks and their restart policies without starting anything.
+func (s *Supervisor) Validate() error {
+	if s.err != nil {
+		return s.err
+	}
+	if len(s.tasks) == 0 {
+		return errors.New("at least one supervised task must be provided")
+	}
+	var errs []error
+	for _, st := range s.tasks {
+		if err := st.policy.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("supervised task %q: invalid restart policy: %w", st.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Run starts all supervised tasks and blocks until every task has finished
+// or one of them escalates. Run has the goture.Task signature, so a supervisor
+// can be passed anywhere a task is expected.
+func (s *Supervisor) Run(ctx context.Context) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	var (
+		wg         sync.WaitGroup
+		escalateMu sync.Mutex
+		escalation error
+	)
+
+	for _, st := range s.tasks {
+		wg.Add(1)
+		go func(st supervisedTask) {
+			defer wg.Done()
+			if err := st.supervise(ctx); err != nil {
+				escalateMu.Lock()
+				if escalation == nil {
+					escalation = err
+					cancel(err)
+				}
+				escalateMu.Unlock()
+			}
+		}(st)
+	}
+	wg.Wait()
+
+	if escalation != nil {
+		return escalation
+	}
+	return context.Cause(ctx)
+}
+
+// supervise runs the task in a loop according to its policy.
+// It returns nil when the task stops normally (or the context is done)
+// and an escalation error when the restart budget is exhausted.
+func (st supervisedTask) supervise(ctx context.Context) error {
+	var restarts []time.Time
+
+	for {
+		// A panic is a failure like any other and follows the restart policy,
+		// unless the launcher re-panics.
+		err := callTask(ctx, st.name, st.task)
+		handleContextPanic(ctx, err)
+
+		// Context cancellation is a normal shutdown, never a reason to restart.
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if !st.shouldRestart(err) {
+			if err != nil {
+				return fmt.Errorf("task %q failed: %w", st.name, err)
+			}
+			return nil
+		}
+
+		now := time.Now()
+		restarts = st.recentRestarts(restarts, now)
+		if st.policy.MaxRestarts > 0 && len(restarts) >= st.policy.MaxRestarts {
+			return fmt.Errorf("task %q: %w (%d restarts within %v): %w",
+				st.name, ErrRestartBudgetExceeded, len(restarts), st.policy.Window, err)
+		}
+
+		delay := st.policy.Backoff.Delay(len(restarts))
+		restarts = append(restarts, now)
+		metricsFromContext(ctx).taskRestarted(st.name)
+
+		if logger, logErr := log.GetLoggerFromContext(ctx); logErr == nil {
+			attrs := []any{
+				"task", st.name,
+				"error", err,
+				"restart", len(restarts),
+				"backoff", delay,
+			}
+			var panicErr *PanicError
+			if errors.As(err, &panicErr) {
+				attrs = append(attrs, "stack", string(panicErr.Stack))
+			}
+			logger.Warn("restarting supervised task", attrs...)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// shouldRestart reports whether the task must be restarted after returning err.
+func (st supervisedTask) shouldRestart(err error) bool {
+	switch st.policy.Mode {
+	case RestartAlways:
+		return true
+	case RestartOnFailure:
+		return err != nil
+	default:
+		return false
+	}
+}
+
+// recentRestarts drops restarts that fell out of the policy window and keeps at most
+// the restarts that count towards the budget, so the slice does not grow forever.
+func (st supervisedTask) recentRestarts(restarts []time.Time, now time.Time) []time.Time {
+	if st.policy.Window > 0 {
+		cutoff := now.Add(-st.policy.Window)
+		i := 0
+		for i < len(restarts) && restarts[i].Before(cutoff) {
+			i++
+		}
+		restarts = restarts[i:]
+	}
+	if limit := max(st.policy.MaxRestarts, maxTrackedRestarts); len(restarts) > limit {
+		restarts = restarts[len(restarts)-limit:]
+	}
+	return restarts
+}
+
+// WaitSupervisor runs the supervisor with the enriched launcher context and waits for its completion.
+// The launcher stops only when a supervised task exhausts its restart budget or the context is canceled.
+// The supervisor configuration is validated before anything starts.
+// Returns AppResult which can be used to check for errors and log them if needed.
+func (a *AppLauncher) WaitSupervisor(supervisor *Supervisor) *AppResult {
+	if supervisor == nil {
+		return &AppResult{Err: errors.New("supervisor cannot be nil")}
+	}
+	tasks := defaultTaskNames([]goture.Task{supervisor.Run})
+	tasks[0].validate = supervisor.Validate
+	return a.runTasks(tasks)
+}
@@ -0,0 +1,225 @@
+package launcher
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fastPolicy returns a restart policy with tiny delays for tests
+func fastPolicy(mode RestartMode, maxRestarts int) RestartPolicy {
+	return RestartPolicy{
+		Mode:        mode,
+		Backoff:     Backoff{Initial: time.Millisecond, Max: 5 * time.Millisecond, Multiplier: 2},
+		MaxRestarts: maxRestarts,
+		Window:      time.Minute,
+	}
+}
+
+// TestBackoffDelay tests exponential growth, capping and jitter bounds
+func TestBackoffDelay(t *testing.T) {
+	b := Backoff{Initial: 10 * time.Millisecond, Max: 50 * time.Millisecond, Multiplier: 2}
+
+	expected := []time.Duration{10, 20, 40, 50, 50}
+	for attempt, want := range expected {
+		if got := b.Delay(attempt); got != want*time.Millisecond {
+			t.Errorf("attempt %d: expected %v, got %v", attempt, want*time.Millisecond, got)
+		}
+	}
+
+	b.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		got := b.Delay(0)
+		if got < 5*time.Millisecond || got > 15*time.Millisecond {
+			t.Fatalf("jittered delay %v out of bounds", got)
+		}
+	}
+
+	if got := (Backoff{}).Delay(3); got != 0 {
+		t.Errorf("Expected zero delay for empty backoff, got %v", got)
+	}
+
+	unbounded := Backoff{Initial: time.Second, Multiplier: 10, Jitter: 1}
+	for _, attempt := range []int{30, 400, 5000} {
+		if got := unbounded.Delay(attempt); got <= 0 || got > 2*maxBackoffDelay {
+			t.Errorf("attempt %d: expected a delay capped at %v, got %v", attempt, maxBackoffDelay, got)
+		}
+	}
+}
+
+// TestRecentRestartsBounded tests that restart timestamps are trimmed without a window or budget
+func TestRecentRestartsBounded(t *testing.T) {
+	st := supervisedTask{policy: RestartPolicy{Mode: RestartAlways}}
+	var restarts []time.Time
+	now := time.Now()
+	for i := 0; i < 1000; i++ {
+		restarts = append(st.recentRestarts(restarts, now), now)
+	}
+	if len(restarts) > maxTrackedRestarts+1 {
+		t.Errorf("Expected at most %d restarts to be kept, got %d", maxTrackedRestarts+1, len(restarts))
+	}
+
+	st.policy.MaxRestarts = 100
+	st.policy.Window = time.Minute
+	restarts = make([]time.Time, 150)
+	for i := range restarts {
+		restarts[i] = now
+	}
+	if got := st.recentRestarts(restarts, now); len(got) != 100 {
+		t.Errorf("Expected the budget of 100 restarts to be kept, got %d", len(got))
+	}
+	if got := st.recentRestarts(restarts, now.Add(2*time.Minute)); len(got) != 0 {
+		t.Errorf("Expected restarts outside the window to be dropped, got %d", len(got))
+	}
+}
+
+// TestSupervisorRestartsOnFailure tests that failing tasks are restarted until they succeed
+func TestSupervisorRestartsOnFailure(t *testing.T) {
+	var attempts int32
+	task := func(ctx context.Context) error {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			return errors.New("transient error")
+		}
+		return nil
+	}
+
+	result := NewAppLauncher().WaitSupervisor(
+		NewSupervisor().Add("flaky", task, fastPolicy(RestartOnFailure, 5)),
+	)
+
+	if result.Error() != nil {
+		t.Errorf("Expected no error, got: %v", result.Error())
+	}
+	if got := atomic.LoadInt32(&attempts); got != 3 {
+		t.Errorf("Expected 3 attempts, got %d", got)
+	}
+}
+
+// TestSupervisorEscalation tests that exhausting the restart budget stops all tasks
+func TestSupervisorEscalation(t *testing.T) {
+	taskErr := errors.New("permanent error")
+	var attempts int32
+	failing := func(ctx context.Context) error {
+		atomic.AddInt32(&attempts, 1)
+		return taskErr
+	}
+
+	var siblingCanceled atomic.Bool
+	sibling := func(ctx context.Context) error {
+		<-ctx.Done()
+		siblingCanceled.Store(true)
+		return ctx.Err()
+	}
+
+	result := NewAppLauncher().
+		WithTimeout(time.Second).
+		WaitSupervisor(
+			NewSupervisor().
+				Add("failing", failing, fastPolicy(RestartOnFailure, 2)).
+				Add("sibling", sibling, fastPolicy(RestartOnFailure, 2)),
+		)
+
+	if !errors.Is(result.Error(), ErrRestartBudgetExceeded) {
+		t.Errorf("Expected ErrRestartBudgetExceeded, got: %v", result.Error())
+	}
+	if !errors.Is(result.Error(), taskErr) {
+		t.Errorf("Expected escalation to wrap task error, got: %v", result.Error())
+	}
+	if got := atomic.LoadInt32(&attempts); got != 3 {
+		t.Errorf("Expected 1 run + 2 restarts, got %d runs", got)
+	}
+	if !siblingCanceled.Load() {
+		t.Error("Expected sibling task to be canceled on escalation")
+	}
+}
+
+// TestSupervisorRestartModes tests never and always restart modes
+func TestSupervisorRestartModes(t *testing.T) {
+	t.Run("never", func(t *testing.T) {
+		var attempts int32
+		task := func(ctx context.Context) error {
+			atomic.AddInt32(&attempts, 1)
+			return errors.New("boom")
+		}
+
+		result := NewAppLauncher().WaitSupervisor(
+			NewSupervisor().Add("once", task, fastPolicy(RestartNever, 5)),
+		)
+
+		if result.Error() == nil {
+			t.Error("Expected error to escalate immediately")
+		}
+		if got := atomic.LoadInt32(&attempts); got != 1 {
+			t.Errorf("Expected exactly one attempt, got %d", got)
+		}
+	})
+
+	t.Run("always", func(t *testing.T) {
+		var attempts int32
+		task := func(ctx context.Context) error {
+			atomic.AddInt32(&attempts, 1)
+			return nil
+		}
+
+		result := NewAppLauncher().WaitSupervisor(
+			NewSupervisor().Add("loop", task, fastPolicy(RestartAlways, 3)),
+		)
+
+		if !errors.Is(result.Error(), ErrRestartBudgetExceeded) {
+			t.Errorf("Expected budget to run out, got: %v", result.Error())
+		}
+		if got := atomic.LoadInt32(&attempts); got != 4 {
+			t.Errorf("Expected 4 attempts, got %d", got)
+		}
+	})
+}
+
+// TestSupervisorStopsOnCancel tests that cancellation is not treated as a failure
+func TestSupervisorStopsOnCancel(t *testing.T) {
+	var attempts int32
+	task := func(ctx context.Context) error {
+		atomic.AddInt32(&attempts, 1)
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	result := NewAppLauncher().
+		WithTimeout(20 * time.Millisecond).
+		WaitSupervisor(NewSupervisor().Add("worker", task, fastPolicy(RestartAlways, 0)))
+
+	if errors.Is(result.Error(), ErrRestartBudgetExceeded) {
+		t.Errorf("Cancellation must not escalate, got: %v", result.Error())
+	}
+	if got := atomic.LoadInt32(&attempts); got != 1 {
+		t.Errorf("Expected no restarts after cancellation, got %d attempts", got)
+	}
+}
+
+// TestSupervisorValidation tests configuration errors
+func TestSupervisorValidation(t *testing.T) {
+	task := func(ctx context.Context) error { return nil }
+
+	tests := []struct {
+		name       string
+		supervisor *Supervisor
+	}{
+		{"empty", NewSupervisor()},
+		{"empty name", NewSupervisor().Add("", task, DefaultRestartPolicy())},
+		{"nil task", NewSupervisor().Add("nil", nil, DefaultRestartPolicy())},
+		{"duplicate", NewSupervisor().Add("a", task, DefaultRestartPolicy()).Add("a", task, DefaultRestartPolicy())},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.supervisor.Run(context.Background()); err == nil {
+				t.Error("Expected configuration error")
+			}
+		})
+	}
+
+	if result := NewAppLauncher().WaitSupervisor(nil); result.Error() == nil {
+		t.Error("Expected error for nil supervisor")
+	}
+}
@@ -0,0 +1,78 @@
+// Package launcher provides task-scoped context enrichment.
+// This file contains the task identity stored in every task context, the
+// per-task logger and the start and stop records logged for each task.
+package launcher
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/goregion/hexago/pkg/log"
+)
+
+// Exit reasons reported in the "task stopped" record.
+const (
+	exitCompleted = "completed" // Returned nil before being canceled
+	exitCanceled  = "canceled"  // Returned after its context was canceled, without a failure
+	exitFailed    = "failed"    // Returned an error
+	exitPanicked  = "panicked"  // Panicked, see PanicError
+)
+
+// TaskInfo identifies the task a context belongs to.
+type TaskInfo struct {
+	Name      string    // Task name, "task-<index>" for anonymous tasks
+	Index     int       // Position of the task in the launch call
+	StartedAt time.Time // When the task was started
+}
+
+// taskInfoKey is the context key of the task identity.
+type taskInfoKey struct{}
+
+// TaskFromContext returns the identity of the task the context was passed to.
+// It reports false for contexts that do not belong to a launched task.
+//
+// Example:
+//
+//	func (c *Consumer) Run(ctx context.Context) error {
+//		task, _ := launcher.TaskFromContext(ctx)
+//		return c.subscribe(ctx, "consumer-group", task.Name)
+//	}
+func TaskFromContext(ctx context.Context) (TaskInfo, bool) {
+	info, ok := ctx.Value(taskInfoKey{}).(TaskInfo)
+	return info, ok
+}
+
+// withTaskContext stores the task identity in the context and replaces the context
+// logger, if any, with a logger that adds the task=<name> attribute to every record.
+// The derived logger is returned, or nil if the context has no logger.
+func withTaskContext(ctx context.Context, info TaskInfo) (context.Context, *log.Logger) {
+	ctx = context.WithValue(ctx, taskInfoKey{}, info)
+
+	logger, err := log.GetLoggerFromContext(ctx)
+	if err != nil {
+		return ctx, nil
+	}
+	taskLogger := &log.Logger{Logger: logger.With("task", info.Name)}
+	return log.WithLoggerContext(ctx, taskLogger), taskLogger
+}
+
+// logTaskStopped logs how and after how long the task returned.
+// Failures are logged at the error level, other exits at the info level.
+func logTaskStopped(logger *log.Logger, duration time.Duration, err error, canceled bool) {
+	if logger == nil {
+		return
+	}
+
+	var panicErr *PanicError
+	switch {
+	case errors.As(err, &panicErr):
+		logger.Error("task stopped", "reason", exitPanicked, "duration", duration, "error", err)
+	case err != nil && !(canceled && errors.Is(err, context.Canceled)):
+		logger.Error("task stopped", "reason", exitFailed, "duration", duration, "error", err)
+	case canceled:
+		logger.Info("task stopped", "reason", exitCanceled, "duration", duration)
+	default:
+		logger.Info("task stopped", "reason", exitCompleted, "duration", duration)
+	}
+}
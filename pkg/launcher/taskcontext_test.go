@@ -0,0 +1,137 @@
+package launcher
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goregion/hexago/pkg/log"
+)
+
+// decodeRecords decodes JSON log lines into maps
+func decodeRecords(t *testing.T, output string) []map[string]any {
+	t.Helper()
+
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Failed to decode log line %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// findRecord returns the record with the message and task attribute, or nil
+func findRecord(records []map[string]any, message, task string) map[string]any {
+	for _, record := range records {
+		if record["msg"] == message && record["task"] == task {
+			return record
+		}
+	}
+	return nil
+}
+
+// TestTaskContext tests the per-task logger, the task identity and the start and stop records
+func TestTaskContext(t *testing.T) {
+	var logOutput bytes.Buffer
+	logger := log.NewLogger(log.NewJsonHandler(&logOutput))
+
+	identities := make(chan TaskInfo, 2)
+	report := func(ctx context.Context) {
+		info, ok := TaskFromContext(ctx)
+		if !ok {
+			t.Error("Expected task identity in context")
+		}
+		identities <- info
+		log.MustGetLoggerFromContext(ctx).Info("working")
+	}
+
+	launcher := NewAppLauncher().WithLoggerContext(logger)
+	time.AfterFunc(20*time.Millisecond, launcher.Shutdown)
+
+	launcher.WaitTasks(
+		Task{Name: "consumer", Run: func(ctx context.Context) error {
+			report(ctx)
+			<-ctx.Done()
+			return ctx.Err()
+		}},
+		Task{Name: "migrator", Run: func(ctx context.Context) error {
+			report(ctx)
+			return nil
+		}},
+		Task{Name: "broken", Run: func(ctx context.Context) error {
+			return errors.New("connection refused")
+		}},
+		Task{Name: "crashing", Run: func(ctx context.Context) error {
+			panic("nil map")
+		}},
+	)
+
+	close(identities)
+	names := map[string]int{}
+	for info := range identities {
+		names[info.Name] = info.Index
+		if info.StartedAt.IsZero() {
+			t.Errorf("Expected start time for task %q", info.Name)
+		}
+	}
+	if names["consumer"] != 0 || names["migrator"] != 1 || len(names) != 2 {
+		t.Errorf("Unexpected task identities: %v", names)
+	}
+
+	records := decodeRecords(t, logOutput.String())
+	for _, task := range []string{"consumer", "migrator"} {
+		if findRecord(records, "working", task) == nil {
+			t.Errorf("Expected task logger of %q to add the task attribute", task)
+		}
+	}
+	for task, reason := range map[string]string{
+		"consumer": exitCanceled,
+		"migrator": exitCompleted,
+		"broken":   exitFailed,
+		"crashing": exitPanicked,
+	} {
+		if findRecord(records, "task started", task) == nil {
+			t.Errorf("Expected start record for task %q", task)
+		}
+		stopped := findRecord(records, "task stopped", task)
+		if stopped == nil {
+			t.Errorf("Expected stop record for task %q", task)
+			continue
+		}
+		if stopped["reason"] != reason {
+			t.Errorf("Expected task %q to stop with reason %q, got %v", task, reason, stopped["reason"])
+		}
+		if _, ok := stopped["duration"]; !ok {
+			t.Errorf("Expected duration in stop record of task %q", task)
+		}
+	}
+	if stopped := findRecord(records, "task stopped", "broken"); stopped != nil &&
+		(stopped["level"] != "ERROR" || stopped["error"] != "connection refused") {
+		t.Errorf("Expected failure to be logged as an error, got %v", stopped)
+	}
+}
+
+// TestTaskFromContextOutsideTask tests that plain contexts carry no task identity
+func TestTaskFromContextOutsideTask(t *testing.T) {
+	if _, ok := TaskFromContext(context.Background()); ok {
+		t.Error("Expected no task identity in a background context")
+	}
+
+	// Without a logger tasks run normally and nothing is logged
+	result := NewAppLauncher().WaitApplication(func(ctx context.Context) error {
+		if info, ok := TaskFromContext(ctx); !ok || info.Name != "task-0" {
+			t.Errorf("Expected default task name, got %+v", info)
+		}
+		return nil
+	})
+	if result.Error() != nil {
+		t.Errorf("Expected no error, got: %v", result.Error())
+	}
+}
@@ -0,0 +1,341 @@
+// Package launcher provides the validate mode of the launcher.
+// This file contains the configuration checks, the validation report and the
+// mode that checks the launcher wiring and exits without starting any task.
+package launcher
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ValidateFlag is the command line flag that selects the validate mode, e.g. --validate.
+	ValidateFlag = "validate"
+	// ValidateEnv is the environment variable that selects the validate mode, e.g. APP_VALIDATE=true.
+	// It is read by NewAppLauncherWithContext; WithValidateMode overrides it.
+	ValidateEnv = "APP_VALIDATE"
+)
+
+// ErrValidationFailed is returned in validate mode when at least one check failed.
+var ErrValidationFailed = errors.New("validation failed")
+
+// Validator is implemented by configurations that can check themselves without
+// connecting to anything, e.g. ScheduledTask.
+type Validator interface {
+	Validate() error
+}
+
+// ValidateFunc adapts a function to the Validator interface.
+type ValidateFunc func() error
+
+// Validate calls f.
+func (f ValidateFunc) Validate() error {
+	return f()
+}
+
+// ValidationCheck is the outcome of a single check of the validate mode.
+type ValidationCheck struct {
+	Kind  string `json:"kind"`            // What was checked: "task", "component", "command", "config" or "health"
+	Name  string `json:"name"`            // Name of the checked item
+	OK    bool   `json:"ok"`              // Whether the check passed
+	Error string `json:"error,omitempty"` // Problem found by the check
+}
+
+// ValidationReport is the structured report printed by the validate mode.
+type ValidationReport struct {
+	Valid    bool              `json:"valid"`    // Whether every check passed
+	Problems int               `json:"problems"` // Number of failed checks
+	Tasks    []string          `json:"tasks"`    // Tasks that would be started
+	Checks   []ValidationCheck `json:"checks"`   // Every check that was run
+}
+
+// namedValidator is a configuration check registered with ValidateConfig.
+type namedValidator struct {
+	name      string
+	validator Validator
+}
+
+// validation collects the checks of a validate mode run.
+type validation struct {
+	report ValidationReport
+}
+
+// check records the outcome of a check. Several errors are joined into one problem.
+func (v *validation) check(kind, name string, errs ...error) {
+	check := ValidationCheck{Kind: kind, Name: name, OK: true}
+	if err := errors.Join(errs...); err != nil {
+		check.OK = false
+		check.Error = strings.ReplaceAll(err.Error(), "\n", "; ")
+		v.report.Problems++
+	}
+	v.report.Checks = append(v.report.Checks, check)
+}
+
+// WithValidateMode enables the validate mode if enabled is true, overriding the
+// APP_VALIDATE environment variable read when the launcher is created. In validate mode the
+// Wait* methods check the launcher wiring instead of starting tasks: task and component
+// definitions, their dependencies (missing or cyclic), the Validate function of every
+// task, component and command, supervisor restart policies, health check registrations
+// and the configurations registered with ValidateConfig. Tasks passed as plain functions
+// cannot be checked; use ScheduledTask.Task with WaitTasks to check a cron expression.
+// The outcome is printed as a single JSON report (see ValidationReport) and the result
+// wraps ErrValidationFailed if any check failed, so the process can exit non-zero.
+// No task, component Start or command Tasks function is ever called.
+// Returns the same launcher instance for method chaining (fluent API).
+//
+// Example:
+//
+//	launcher.NewAppLauncher().
+//		WithLoggerContext(logger).
+//		WithValidateMode(launcher.ValidateModeRequested(os.Args[1:])).
+//		ValidateConfig("config", cfg).
+//		WaitComponents(components).
+//		LogIfError(logger, "Application stopped")
+func (a *AppLauncher) WithValidateMode(enabled bool) *AppLauncher {
+	a.validateMode = enabled
+	return a
+}
+
+// WithValidateOutput sets the writer of the validation report, os.Stdout by default.
+// Returns the same launcher instance for method chaining (fluent API).
+func (a *AppLauncher) WithValidateOutput(output io.Writer) *AppLauncher {
+	a.validateOutput = output
+	return a
+}
+
+// ValidateConfig registers a configuration check, for example the parsed application
+// config or a ScheduledTask. Registered checks run before any task is started: in
+// validate mode they are part of the report, otherwise a failing check fails the launch.
+// Returns the same launcher instance for method chaining (fluent API).
+func (a *AppLauncher) ValidateConfig(name string, validator Validator) *AppLauncher {
+	if validator != nil {
+		a.validators = append(a.validators, namedValidator{name: name, validator: validator})
+	}
+	return a
+}
+
+// ValidateModeRequested reports whether the validate mode is requested by the
+// --validate flag (also -validate and --validate=<bool>) in args or, if the flag
+// is absent, by the APP_VALIDATE environment variable. Arguments after "--" are ignored.
+func ValidateModeRequested(args []string) bool {
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if name != ValidateFlag {
+			continue
+		}
+		if !hasValue {
+			return true
+		}
+		enabled, err := strconv.ParseBool(value)
+		return err == nil && enabled
+	}
+	return validateEnvEnabled()
+}
+
+// validateEnvEnabled reports whether APP_VALIDATE is set to a true value.
+func validateEnvEnabled() bool {
+	enabled, err := strconv.ParseBool(os.Getenv(ValidateEnv))
+	return err == nil && enabled
+}
+
+// checkConfigs runs the checks registered with ValidateConfig and joins their errors.
+func (a *AppLauncher) checkConfigs() error {
+	var errs []error
+	for _, v := range a.validators {
+		if err := v.validator.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("config %q is invalid: %w", v.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// finishValidation adds the registered configuration checks, the health check
+// registration and the managed tasks, prints the report and returns the result
+// of the validate mode run.
+func (a *AppLauncher) finishValidation(v *validation, tasks []string) *AppResult {
+	for _, t := range a.managedTasks() {
+		tasks = append(tasks, t.name)
+	}
+	v.report.Tasks = tasks
+
+	for _, validator := range a.validators {
+		v.check("config", validator.name, validator.validator.Validate())
+	}
+	if err := a.health.Err(); err != nil || a.health.Len() > 0 {
+		v.check("health", "registration", err)
+	}
+	v.report.Valid = v.report.Problems == 0
+
+	output := a.validateOutput
+	if output == nil {
+		output = os.Stdout
+	}
+	encoder := json.NewEncoder(output)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v.report); err != nil {
+		return &AppResult{Err: fmt.Errorf("failed to write validation report: %w", err)}
+	}
+
+	if logger := a.logger(); logger != nil {
+		logger.Info("validation finished",
+			"valid", v.report.Valid,
+			"problems", v.report.Problems,
+			"checks", len(v.report.Checks),
+		)
+	}
+
+	if !v.report.Valid {
+		return &AppResult{Err: fmt.Errorf("%w: %d of %d checks failed", ErrValidationFailed, v.report.Problems, len(v.report.Checks))}
+	}
+	return &AppResult{}
+}
+
+// validateNamedTasks checks tasks passed by WaitApplications, WaitSupervisor and similar
+// methods. Plain task functions cannot be inspected, so only tasks with a definition
+// check, such as a supervisor, or with a reserved name are reported as checks; all tasks
+// are listed in the report.
+func (a *AppLauncher) validateNamedTasks(tasks []namedTask) *AppResult {
+	v := &validation{}
+	names := make([]string, len(tasks))
+	for i, t := range tasks {
+		names[i] = t.name
+		reserved := a.reservedTaskName(t.name)
+		switch {
+		case t.validate != nil:
+			v.check("task", t.name, reserved, t.validate())
+		case reserved != nil:
+			v.check("task", t.name, reserved)
+		}
+	}
+	return a.finishValidation(v, names)
+}
+
+// validateTaskDefinitions checks task definitions passed to WaitTasks, reporting every
+// problem instead of the first one.
+func (a *AppLauncher) validateTaskDefinitions(tasks []Task) *AppResult {
+	v := &validation{}
+	names := make([]string, 0, len(tasks))
+	dependsOn := make(map[string][]string, len(tasks))
+	for _, task := range tasks {
+		if task.Name != "" {
+			dependsOn[task.Name] = task.DependsOn
+		}
+	}
+
+	// Only explicit names are tracked, so that the report name of an unnamed
+	// task cannot be mistaken for a duplicate of a task with that name.
+	seen := make(map[string]bool, len(tasks))
+	for i, task := range tasks {
+		name := task.Name
+		var errs []error
+		switch {
+		case name == "":
+			name = fmt.Sprintf("task-%d", i)
+			errs = append(errs, errors.New("name cannot be empty"))
+		case seen[name]:
+			errs = append(errs, errors.New("task is defined more than once"))
+		default:
+			seen[name] = true
+			errs = append(errs, a.reservedTaskName(name))
+		}
+		if task.Run == nil {
+			errs = append(errs, errors.New("task cannot be nil"))
+		}
+		errs = append(errs, unknownDependencies("task", task.DependsOn, dependsOn)...)
+		if task.Validate != nil {
+			errs = append(errs, task.Validate())
+		}
+		names = append(names, name)
+		v.check("task", name, errs...)
+	}
+
+	v.check("task", "dependency graph", dependencyCycle("task", names, dependsOn))
+	return a.finishValidation(v, names)
+}
+
+// unknownDependencies returns an error for every dependency that is not defined.
+func unknownDependencies(kind string, deps []string, dependsOn map[string][]string) []error {
+	var errs []error
+	for _, dep := range deps {
+		if _, exists := dependsOn[dep]; !exists {
+			errs = append(errs, fmt.Errorf("depends on unknown %s %q", kind, dep))
+		}
+	}
+	return errs
+}
+
+// dependencyCycle returns the dependency cycle error, if any, ignoring unknown
+// dependencies, which are reported per item.
+func dependencyCycle(kind string, order []string, dependsOn map[string][]string) error {
+	known := make(map[string][]string, len(dependsOn))
+	for name, deps := range dependsOn {
+		for _, dep := range deps {
+			if _, exists := dependsOn[dep]; exists {
+				known[name] = append(known[name], dep)
+			}
+		}
+		if _, ok := known[name]; !ok {
+			known[name] = nil
+		}
+	}
+	var unique []string
+	for _, name := range order {
+		if _, ok := known[name]; ok {
+			unique = append(unique, name)
+		}
+	}
+	_, err := dependencyOrder(kind, unique, known)
+	return err
+}
+
+// validateComponents checks the components of the registry passed to WaitComponents,
+// reporting every problem instead of the first one.
+func (a *AppLauncher) validateComponents(registry *ComponentRegistry) *AppResult {
+	v := &validation{}
+	if registry.err != nil {
+		v.check("component", "registration", registry.err)
+	}
+
+	dependsOn := make(map[string][]string, len(registry.components))
+	for name, component := range registry.components {
+		dependsOn[name] = component.DependsOn
+	}
+
+	for _, name := range registry.order {
+		component := registry.components[name]
+		errs := unknownDependencies("component", component.DependsOn, dependsOn)
+		if component.Validate != nil {
+			errs = append(errs, component.Validate())
+		}
+		v.check("component", name, errs...)
+	}
+
+	v.check("component", "dependency graph", dependencyCycle("component", registry.order, dependsOn))
+	return a.finishValidation(v, []string{"components"})
+}
+
+// validateCommands checks the commands selected by WaitCommand with their positional arguments.
+func (a *AppLauncher) validateCommands(selected []Command, args []string) *AppResult {
+	v := &validation{}
+	names := make([]string, len(selected))
+	for i, command := range selected {
+		names[i] = command.Name
+		var err error
+		if command.Validate != nil {
+			err = command.Validate(args)
+		}
+		v.check("command", command.Name, err)
+	}
+	return a.finishValidation(v, names)
+}
@@ -0,0 +1,364 @@
+package launcher
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/goregion/goture"
+)
+
+// decodeReport decodes the validation report written by the validate mode
+func decodeReport(t *testing.T, output *bytes.Buffer) ValidationReport {
+	t.Helper()
+
+	var report ValidationReport
+	if err := json.Unmarshal(output.Bytes(), &report); err != nil {
+		t.Fatalf("Failed to decode report %q: %v", output.String(), err)
+	}
+	return report
+}
+
+// failedChecks returns the failed checks by name
+func failedChecks(report ValidationReport) map[string]string {
+	failed := make(map[string]string)
+	for _, check := range report.Checks {
+		if !check.OK {
+			failed[check.Kind+" "+check.Name] = check.Error
+		}
+	}
+	return failed
+}
+
+// TestValidateModeTasks tests that every task wiring problem is reported and nothing is started
+func TestValidateModeTasks(t *testing.T) {
+	var started atomic.Int32
+	run := func(ctx context.Context) error {
+		started.Add(1)
+		return nil
+	}
+	rollup := ScheduledTask{Name: "rollup", Cron: "0 25 * * *", Job: run}
+
+	var output bytes.Buffer
+	result := NewAppLauncher().
+		WithValidateMode(true).
+		WithValidateOutput(&output).
+		ValidateConfig("config", ValidateFunc(func() error { return errors.New("REDIS_URL is required") })).
+		WaitTasks(
+			Task{Name: "db-pool", Run: run},
+			Task{Name: "consumer", Run: run, DependsOn: []string{"db-pool", "cache"}},
+			Task{Name: "rollup", Run: rollup.Run, Validate: rollup.Validate},
+			Task{Name: "a", Run: run, DependsOn: []string{"b"}},
+			Task{Name: "b", Run: run, DependsOn: []string{"a"}},
+		)
+
+	if !errors.Is(result.Error(), ErrValidationFailed) {
+		t.Fatalf("Expected ErrValidationFailed, got: %v", result.Error())
+	}
+	if started.Load() != 0 || len(result.Tasks) != 0 {
+		t.Errorf("Expected no task to be started, got %d", started.Load())
+	}
+
+	report := decodeReport(t, &output)
+	failed := failedChecks(report)
+	for check, expected := range map[string]string{
+		"task consumer":         `depends on unknown task "cache"`,
+		"task rollup":           "out of range",
+		"task dependency graph": "task dependency cycle: a -> b -> a",
+		"config config":         "REDIS_URL is required",
+	} {
+		if !strings.Contains(failed[check], expected) {
+			t.Errorf("Expected %s check to fail with %q, got %q", check, expected, failed[check])
+		}
+	}
+	if report.Valid || report.Problems != 4 || len(failed) != 4 {
+		t.Errorf("Expected 4 problems, got %d: %v", report.Problems, failed)
+	}
+	if len(report.Tasks) != 5 || report.Tasks[0] != "db-pool" {
+		t.Errorf("Unexpected tasks in report: %v", report.Tasks)
+	}
+}
+
+// TestValidateModeValid tests a valid wiring: a successful result without started tasks
+func TestValidateModeValid(t *testing.T) {
+	var output bytes.Buffer
+	result := NewAppLauncher().
+		WithValidateMode(true).
+		WithValidateOutput(&output).
+		WithAdminServer("127.0.0.1:0").
+		ValidateConfig("schedule", ScheduledTask{Name: "tick", Interval: time.Second, Job: func(ctx context.Context) error { return nil }}).
+		WaitApplications(func(ctx context.Context) error {
+			t.Error("Task must not be started in validate mode")
+			return nil
+		})
+
+	if result.Error() != nil {
+		t.Fatalf("Expected no error, got: %v", result.Error())
+	}
+	report := decodeReport(t, &output)
+	if !report.Valid || report.Problems != 0 || len(report.Checks) != 1 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+	if strings.Join(report.Tasks, ",") != "task-0,"+adminServerTaskName {
+		t.Errorf("Expected the application and admin server tasks, got %v", report.Tasks)
+	}
+}
+
+// TestValidateModeComponents tests the component graph and per-component validation
+func TestValidateModeComponents(t *testing.T) {
+	start := func(ctx context.Context) error {
+		t.Error("Component must not be started in validate mode")
+		return nil
+	}
+	registry := NewComponentRegistry().
+		Register(Component{Name: "db", Start: start, Validate: func() error { return errors.New("DB_HOST is required") }}).
+		Register(Component{Name: "consumer", Start: start, DependsOn: []string{"db", "redis"}}).
+		Register(Component{Name: "http", Start: start, DependsOn: []string{"consumer"}})
+
+	var output bytes.Buffer
+	result := NewAppLauncher().
+		WithValidateMode(true).
+		WithValidateOutput(&output).
+		WaitComponents(registry)
+
+	if !errors.Is(result.Error(), ErrValidationFailed) {
+		t.Fatalf("Expected ErrValidationFailed, got: %v", result.Error())
+	}
+	failed := failedChecks(decodeReport(t, &output))
+	if failed["component db"] != "DB_HOST is required" ||
+		failed["component consumer"] != `depends on unknown component "redis"` || len(failed) != 2 {
+		t.Errorf("Unexpected failed checks: %v", failed)
+	}
+}
+
+// TestValidateModeCommand tests the built-in --validate flag of commands
+func TestValidateModeCommand(t *testing.T) {
+	var validatedArgs []string
+	registry := NewCommandRegistry("app").
+		WithOutput(io.Discard).
+		Register(Command{
+			Name: "consume",
+			Tasks: func(ctx context.Context, args []string) ([]goture.Task, error) {
+				t.Error("Command tasks must not be built in validate mode")
+				return nil, nil
+			},
+			Validate: func(args []string) error {
+				validatedArgs = args
+				return nil
+			},
+		})
+
+	var output bytes.Buffer
+	result := NewAppLauncher().
+		WithValidateOutput(&output).
+		WaitCommand(registry, []string{"consume", "--validate", "ticks"})
+
+	if result.Error() != nil {
+		t.Fatalf("Expected no error, got: %v", result.Error())
+	}
+	if len(validatedArgs) != 1 || validatedArgs[0] != "ticks" {
+		t.Errorf("Expected Validate to receive the positional arguments, got %v", validatedArgs)
+	}
+	if report := decodeReport(t, &output); !report.Valid || report.Tasks[0] != "consume" {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+}
+
+// TestValidateModeRequested tests flag and environment variable selection
+func TestValidateModeRequested(t *testing.T) {
+	tests := []struct {
+		args []string
+		env  string
+		want bool
+	}{
+		{args: nil, want: false},
+		{args: []string{"serve", "--validate"}, want: true},
+		{args: []string{"-validate"}, want: true},
+		{args: []string{"--validate=false"}, env: "true", want: false},
+		{args: []string{"--", "--validate"}, want: false},
+		{args: []string{"--validate-all"}, want: false},
+		{args: nil, env: "true", want: true},
+		{args: nil, env: "not-a-bool", want: false},
+	}
+	for _, test := range tests {
+		t.Setenv(ValidateEnv, test.env)
+		if got := ValidateModeRequested(test.args); got != test.want {
+			t.Errorf("ValidateModeRequested(%v) with %s=%q: expected %v, got %v", test.args, ValidateEnv, test.env, test.want, got)
+		}
+	}
+}
+
+// TestValidateModeEnv tests that APP_VALIDATE selects the validate mode without explicit wiring
+func TestValidateModeEnv(t *testing.T) {
+	t.Setenv(ValidateEnv, "true")
+
+	run := func(ctx context.Context) error {
+		t.Error("Task must not be started in validate mode")
+		return nil
+	}
+
+	var output bytes.Buffer
+	result := NewAppLauncher().WithValidateOutput(&output).WaitApplication(run)
+	if result.Error() != nil {
+		t.Fatalf("Expected no error, got: %v", result.Error())
+	}
+	if report := decodeReport(t, &output); !report.Valid {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+
+	var started atomic.Bool
+	result = NewAppLauncher().WithValidateMode(false).WaitApplication(func(ctx context.Context) error {
+		started.Store(true)
+		return nil
+	})
+	if result.Error() != nil || !started.Load() {
+		t.Errorf("Expected WithValidateMode(false) to start the task, got: %v", result.Error())
+	}
+}
+
+// TestValidateModeUnnamedTask tests that an unnamed task is not reported as a duplicate of a
+// task whose name equals its report name
+func TestValidateModeUnnamedTask(t *testing.T) {
+	run := func(ctx context.Context) error { return nil }
+
+	var output bytes.Buffer
+	result := NewAppLauncher().
+		WithValidateMode(true).
+		WithValidateOutput(&output).
+		WaitTasks(Task{Run: run}, Task{Name: "task-0", Run: run}, Task{Name: "task-1", Run: run}, Task{Run: run})
+	if !errors.Is(result.Error(), ErrValidationFailed) {
+		t.Fatalf("Expected ErrValidationFailed, got: %v", result.Error())
+	}
+
+	for _, check := range decodeReport(t, &output).Checks {
+		if strings.Contains(check.Error, "defined more than once") {
+			t.Errorf("Unexpected duplicate check %q: %s", check.Name, check.Error)
+		}
+	}
+}
+
+// TestValidationBeforeLaunch tests that failing checks prevent the launch outside validate mode
+func TestValidationBeforeLaunch(t *testing.T) {
+	run := func(ctx context.Context) error {
+		t.Error("Task must not be started when validation fails")
+		return nil
+	}
+
+	result := NewAppLauncher().
+		ValidateConfig("config", ValidateFunc(func() error { return errors.New("missing DSN") })).
+		WaitApplication(run)
+	if result.Error() == nil || !strings.Contains(result.Error().Error(), `config "config" is invalid: missing DSN`) {
+		t.Errorf("Expected config error, got: %v", result.Error())
+	}
+
+	result = NewAppLauncher().WaitTasks(Task{Name: "rollup", Run: run, Validate: func() error { return errors.New("bad cron") }})
+	if result.Error() == nil || !strings.Contains(result.Error().Error(), `task "rollup" is invalid: bad cron`) {
+		t.Errorf("Expected task error, got: %v", result.Error())
+	}
+
+	result = NewAppLauncher().WaitComponents(NewComponentRegistry().
+		Register(Component{Name: "db", Start: run, Validate: func() error { return errors.New("no host") }}))
+	if result.Error() == nil || !strings.Contains(result.Error().Error(), `component "db" is invalid: no host`) {
+		t.Errorf("Expected component error, got: %v", result.Error())
+	}
+}
+
+// TestValidateModeHealth tests that health check registration errors are reported
+func TestValidateModeHealth(t *testing.T) {
+	var output bytes.Buffer
+	result := NewAppLauncher().
+		WithValidateMode(true).
+		WithValidateOutput(&output).
+		WithHealthCheck("", HealthCheckFunc(func(ctx context.Context) error { return nil }), HealthCheckOptions{}).
+		WaitApplication(func(ctx context.Context) error { return nil })
+
+	if !errors.Is(result.Error(), ErrValidationFailed) {
+		t.Fatalf("Expected ErrValidationFailed, got: %v", result.Error())
+	}
+	failed := failedChecks(decodeReport(t, &output))
+	if !strings.Contains(failed["health registration"], "name cannot be empty") {
+		t.Errorf("Expected the health registration check to fail, got %v", failed)
+	}
+}
+
+// TestValidateModeDefinitions tests that supervisor and schedule definition errors are
+// reported and that plain task functions are not reported as checks
+func TestValidateModeDefinitions(t *testing.T) {
+	run := func(ctx context.Context) error {
+		t.Error("Task must not be started in validate mode")
+		return nil
+	}
+
+	var output bytes.Buffer
+	result := NewAppLauncher().
+		WithValidateMode(true).
+		WithValidateOutput(&output).
+		WaitSupervisor(NewSupervisor().
+			Add("consumer", run, RestartPolicy{Mode: RestartOnFailure, MaxRestarts: -1}).
+			Add("", run, DefaultRestartPolicy()))
+	if !errors.Is(result.Error(), ErrValidationFailed) {
+		t.Fatalf("Expected ErrValidationFailed, got: %v", result.Error())
+	}
+	failed := failedChecks(decodeReport(t, &output))
+	if len(failed) != 1 || !strings.Contains(failed["task task-0"], "supervised task name cannot be empty") {
+		t.Errorf("Expected the supervisor registration error, got %v", failed)
+	}
+
+	output.Reset()
+	result = NewAppLauncher().
+		WithValidateMode(true).
+		WithValidateOutput(&output).
+		WaitSupervisor(NewSupervisor().Add("consumer", run, RestartPolicy{Mode: RestartOnFailure, MaxRestarts: -1}))
+	if !errors.Is(result.Error(), ErrValidationFailed) {
+		t.Fatalf("Expected ErrValidationFailed, got: %v", result.Error())
+	}
+	failed = failedChecks(decodeReport(t, &output))
+	if !strings.Contains(failed["task task-0"], `supervised task "consumer": invalid restart policy: max restarts cannot be negative`) {
+		t.Errorf("Expected the restart policy to be reported, got %v", failed)
+	}
+
+	output.Reset()
+	rollup := ScheduledTask{Name: "rollup", Cron: "0 25 * * *", Job: run}
+	result = NewAppLauncher().
+		WithValidateMode(true).
+		WithValidateOutput(&output).
+		WaitTasks(rollup.Task())
+	if !errors.Is(result.Error(), ErrValidationFailed) {
+		t.Fatalf("Expected ErrValidationFailed, got: %v", result.Error())
+	}
+	if failed := failedChecks(decodeReport(t, &output)); !strings.Contains(failed["task rollup"], "out of range") {
+		t.Errorf("Expected the cron expression to be reported, got %v", failed)
+	}
+
+	output.Reset()
+	result = NewAppLauncher().
+		WithValidateMode(true).
+		WithValidateOutput(&output).
+		WaitApplications(run, run)
+	if result.Error() != nil {
+		t.Fatalf("Expected no error, got: %v", result.Error())
+	}
+	if report := decodeReport(t, &output); len(report.Checks) != 0 || len(report.Tasks) != 2 {
+		t.Errorf("Expected the tasks to be listed without checks, got %+v", report)
+	}
+}
+
+// TestInvalidSupervisorFailsLaunch tests that an invalid restart policy fails the launch before anything starts
+func TestInvalidSupervisorFailsLaunch(t *testing.T) {
+	var started atomic.Bool
+	result := NewAppLauncher().WaitSupervisor(NewSupervisor().
+		Add("ok", func(ctx context.Context) error { started.Store(true); return nil }, DefaultRestartPolicy()).
+		Add("bad", func(ctx context.Context) error { return nil }, RestartPolicy{Backoff: Backoff{Jitter: 2}}))
+	if result.Error() == nil || !strings.Contains(result.Error().Error(), `task "task-0" is invalid: supervised task "bad"`) {
+		t.Errorf("Expected the restart policy error, got: %v", result.Error())
+	}
+	if started.Load() || len(result.Tasks) != 0 {
+		t.Error("Expected no task to be started")
+	}
+}
@@ -0,0 +1,323 @@
+// Package log provides an asynchronous handler wrapper for slog.
+// This file contains the AsyncHandler type that moves the work of a slow handler
+// off the logging goroutine through a bounded ring buffer and a background flusher.
+package log
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultAsyncBufferSize is the default capacity of the AsyncHandler ring buffer.
+	DefaultAsyncBufferSize = 4096
+	// DefaultDropReportInterval is the default interval between "log records dropped" records.
+	DefaultDropReportInterval = 10 * time.Second
+)
+
+// OverflowPolicy defines what AsyncHandler does with a record when the buffer is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock makes the logging call wait for free space in the buffer. This is the default.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropOldest discards the oldest buffered record to make room for the new one.
+	OverflowDropOldest
+	// OverflowDropNewest discards the new record.
+	OverflowDropNewest
+)
+
+// String returns the policy name used in logs.
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowBlock:
+		return "block"
+	case OverflowDropOldest:
+		return "drop-oldest"
+	case OverflowDropNewest:
+		return "drop-newest"
+	default:
+		return fmt.Sprintf("OverflowPolicy(%d)", int(p))
+	}
+}
+
+// AsyncOptions configures an AsyncHandler.
+type AsyncOptions struct {
+	BufferSize         int            // Capacity of the ring buffer, zero means DefaultAsyncBufferSize
+	Policy             OverflowPolicy // What happens to records when the buffer is full
+	DropReportInterval time.Duration  // Interval between drop reports, zero means DefaultDropReportInterval, negative disables them
+}
+
+// asyncEntry is a buffered record together with the handler that writes it.
+type asyncEntry struct {
+	handler slog.Handler
+	ctx     context.Context
+	record  slog.Record
+}
+
+// asyncCore is the buffer and the background goroutines shared by an AsyncHandler
+// and all handlers derived from it with WithAttrs and WithGroup.
+type asyncCore struct {
+	root     slog.Handler // Handler that receives the drop reports
+	options  AsyncOptions
+	mu       sync.Mutex
+	notEmpty *sync.Cond   // Signaled when a record is buffered or the handler is closed
+	notFull  *sync.Cond   // Signaled when the flusher takes records out of the buffer
+	progress *sync.Cond   // Signaled when written advances
+	buf      []asyncEntry // Ring buffer
+	head     int          // Index of the oldest record
+	count    int          // Number of buffered records
+	enqueued uint64       // Sequence number of the last buffered record
+	taken    uint64       // Sequence number of the last record taken out of the buffer, written or dropped
+	written  uint64       // Every record up to this sequence number has been written or dropped
+	busy     bool         // Whether the flusher is writing a batch
+	closed   bool         // Whether Close was called
+	dropped  atomic.Int64 // Records dropped since the handler was created
+	reported int64        // Dropped records already reported, owned by the reporter
+	flushed  chan struct{}
+	stop     chan struct{}
+	stopped  chan struct{}
+	once     sync.Once
+	closeErr error // Error of closing the wrapped handler, set once by Close
+}
+
+// AsyncHandler wraps a handler so that records are written by a background flusher
+// instead of the logging goroutine. Records wait in a bounded ring buffer; when it is
+// full the overflow policy decides whether the logging call blocks or a record is
+// dropped. Dropped records are counted and reported periodically with a
+// "log records dropped" warning written directly to the wrapped handler.
+//
+// Close must be called before the process exits to write the buffered records.
+// The launcher flushes the handlers of its context logger when the tasks have stopped,
+// and Logger.Close flushes and closes them.
+//
+// Example:
+//
+//	handler := log.NewAsyncHandler(log.NewJsonHandler(file), log.AsyncOptions{
+//		BufferSize: 8192,
+//		Policy:     log.OverflowDropOldest,
+//	})
+//	logger := log.NewLogger(handler)
+//	defer logger.Close()
+type AsyncHandler struct {
+	core    *asyncCore
+	handler slog.Handler
+}
+
+// NewAsyncHandler wraps handler and starts the background flusher.
+func NewAsyncHandler(handler slog.Handler, options AsyncOptions) *AsyncHandler {
+	if options.BufferSize <= 0 {
+		options.BufferSize = DefaultAsyncBufferSize
+	}
+	if options.DropReportInterval == 0 {
+		options.DropReportInterval = DefaultDropReportInterval
+	}
+
+	core := &asyncCore{
+		root:    handler,
+		options: options,
+		buf:     make([]asyncEntry, options.BufferSize),
+		flushed: make(chan struct{}),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	core.notEmpty = sync.NewCond(&core.mu)
+	core.notFull = sync.NewCond(&core.mu)
+	core.progress = sync.NewCond(&core.mu)
+
+	go core.flush()
+	go core.reportDrops()
+	return &AsyncHandler{core: core, handler: handler}
+}
+
+// Enabled reports whether the wrapped handler handles records at the level.
+func (h *AsyncHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+// Handle buffers a copy of the record. Once the handler is closed records are
+// written synchronously, so late records such as the final error are not lost.
+func (h *AsyncHandler) Handle(ctx context.Context, record slog.Record) error {
+	c := h.core
+	entry := asyncEntry{handler: h.handler, ctx: context.WithoutCancel(ctx), record: record.Clone()}
+
+	c.mu.Lock()
+	for c.count == len(c.buf) && !c.closed {
+		switch c.options.Policy {
+		case OverflowDropNewest:
+			c.mu.Unlock()
+			c.dropped.Add(1)
+			return nil
+		case OverflowDropOldest:
+			c.buf[c.head] = asyncEntry{}
+			c.head = (c.head + 1) % len(c.buf)
+			c.count--
+			c.taken++
+			if !c.busy {
+				c.written = c.taken
+				c.progress.Broadcast()
+			}
+			c.dropped.Add(1)
+		default:
+			c.notFull.Wait()
+		}
+	}
+	if c.closed {
+		c.mu.Unlock()
+		return h.handler.Handle(ctx, record)
+	}
+
+	c.buf[(c.head+c.count)%len(c.buf)] = entry
+	c.count++
+	c.enqueued++
+	c.notEmpty.Signal()
+	c.mu.Unlock()
+	return nil
+}
+
+// WithAttrs returns a handler that shares the buffer and adds the attributes.
+func (h *AsyncHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &AsyncHandler{core: h.core, handler: h.handler.WithAttrs(attrs)}
+}
+
+// WithGroup returns a handler that shares the buffer and opens the group.
+func (h *AsyncHandler) WithGroup(name string) slog.Handler {
+	return &AsyncHandler{core: h.core, handler: h.handler.WithGroup(name)}
+}
+
+// Dropped returns the number of records dropped since the handler was created.
+func (h *AsyncHandler) Dropped() int64 {
+	return h.core.dropped.Load()
+}
+
+// Flush waits until every record buffered before the call has been written or dropped.
+// Records buffered while Flush waits are not waited for.
+// It returns the context error if ctx is done first.
+func (h *AsyncHandler) Flush(ctx context.Context) error {
+	c := h.core
+	stop := context.AfterFunc(ctx, func() {
+		c.mu.Lock()
+		c.progress.Broadcast()
+		c.mu.Unlock()
+	})
+	defer stop()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	target := c.enqueued
+	for c.written < target {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		c.progress.Wait()
+	}
+	return nil
+}
+
+// Close writes the buffered records, stops the background goroutines, reports the
+// records dropped since the last report and closes the wrapped handler if it holds
+// resources. Records handled after Close are written synchronously. Close is safe to
+// call more than once and from derived handlers; the wrapped handler is closed once.
+func (h *AsyncHandler) Close() error {
+	c := h.core
+	c.once.Do(func() {
+		c.mu.Lock()
+		c.closed = true
+		c.notEmpty.Broadcast()
+		c.notFull.Broadcast()
+		c.mu.Unlock()
+
+		<-c.flushed
+		close(c.stop)
+		<-c.stopped
+		c.report()
+		if closer, ok := c.root.(io.Closer); ok {
+			c.closeErr = closer.Close()
+		}
+	})
+	<-c.stopped
+	return c.closeErr
+}
+
+// flush writes buffered records in batches until the handler is closed and the buffer is empty.
+func (c *asyncCore) flush() {
+	defer close(c.flushed)
+
+	var batch []asyncEntry
+	for {
+		c.mu.Lock()
+		for c.count == 0 && !c.closed {
+			c.notEmpty.Wait()
+		}
+		if c.count == 0 {
+			c.mu.Unlock()
+			return
+		}
+		batch = batch[:0]
+		for ; c.count > 0; c.count-- {
+			batch = append(batch, c.buf[c.head])
+			c.buf[c.head] = asyncEntry{}
+			c.head = (c.head + 1) % len(c.buf)
+			c.taken++
+		}
+		c.busy = true
+		c.notFull.Broadcast()
+		c.mu.Unlock()
+
+		for _, entry := range batch {
+			// Write errors cannot be returned to the logging call, the record is lost
+			_ = entry.handler.Handle(entry.ctx, entry.record)
+		}
+
+		// Records taken after the batch were dropped while it was written
+		c.mu.Lock()
+		c.busy = false
+		c.written = c.taken
+		c.progress.Broadcast()
+		c.mu.Unlock()
+	}
+}
+
+// reportDrops reports dropped records periodically until the handler is closed.
+func (c *asyncCore) reportDrops() {
+	defer close(c.stopped)
+	if c.options.DropReportInterval < 0 {
+		<-c.stop
+		return
+	}
+
+	ticker := time.NewTicker(c.options.DropReportInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			c.report()
+		}
+	}
+}
+
+// report writes a warning with the number of records dropped since the last report, if any.
+func (c *asyncCore) report() {
+	total := c.dropped.Load()
+	dropped := total - c.reported
+	if dropped <= 0 {
+		return
+	}
+	c.reported = total
+
+	record := slog.NewRecord(time.Now(), slog.LevelWarn, "log records dropped", 0)
+	record.AddAttrs(
+		slog.Int64("dropped", dropped),
+		slog.Int64("dropped_total", total),
+		slog.String("policy", c.options.Policy.String()),
+		slog.Int("buffer_size", len(c.buf)),
+	)
+	_ = c.root.Handle(context.Background(), record)
+}
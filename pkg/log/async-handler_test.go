@@ -0,0 +1,252 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// gatedHandler records messages and blocks every Handle call until released, by closing
+// release or by sending to it once per call
+type gatedHandler struct {
+	mu       sync.Mutex
+	messages []string
+	records  []slog.Record
+	entered  chan string
+	release  chan struct{}
+}
+
+func newGatedHandler() *gatedHandler {
+	return &gatedHandler{entered: make(chan string, 100), release: make(chan struct{})}
+}
+
+func (h *gatedHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *gatedHandler) Handle(ctx context.Context, record slog.Record) error {
+	h.entered <- record.Message
+	<-h.release
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.messages = append(h.messages, record.Message)
+	h.records = append(h.records, record)
+	return nil
+}
+
+func (h *gatedHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h *gatedHandler) WithGroup(string) slog.Handler { return h }
+
+func (h *gatedHandler) written() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return strings.Join(h.messages, ",")
+}
+
+// fillBehindSlowWriter logs r0, waits until the flusher is stuck writing it, then logs r1..r4
+// into a buffer of two records
+func fillBehindSlowWriter(t *testing.T, handler *gatedHandler, policy OverflowPolicy) *AsyncHandler {
+	t.Helper()
+
+	async := NewAsyncHandler(handler, AsyncOptions{BufferSize: 2, Policy: policy, DropReportInterval: -1})
+	logger := slog.New(async)
+	logger.Info("r0")
+	if message := <-handler.entered; message != "r0" {
+		t.Fatalf("Expected r0 to be written first, got %s", message)
+	}
+	for _, message := range []string{"r1", "r2", "r3", "r4"} {
+		logger.Info(message)
+	}
+	return async
+}
+
+// TestAsyncHandlerDropNewest verifies that new records are dropped when the buffer is full
+func TestAsyncHandlerDropNewest(t *testing.T) {
+	handler := newGatedHandler()
+	async := fillBehindSlowWriter(t, handler, OverflowDropNewest)
+
+	close(handler.release)
+	async.Close()
+
+	if written := handler.written(); written != "r0,r1,r2,log records dropped" {
+		t.Errorf("Unexpected records: %s", written)
+	}
+	if async.Dropped() != 2 {
+		t.Errorf("Expected 2 dropped records, got %d", async.Dropped())
+	}
+
+	report := handler.records[len(handler.records)-1]
+	attrs := map[string]string{}
+	report.Attrs(func(attr slog.Attr) bool {
+		attrs[attr.Key] = attr.Value.String()
+		return true
+	})
+	if report.Level != slog.LevelWarn || attrs["dropped"] != "2" || attrs["policy"] != "drop-newest" {
+		t.Errorf("Unexpected drop report: %v %v", report.Level, attrs)
+	}
+}
+
+// TestAsyncHandlerDropOldest verifies that the oldest buffered records make room for new ones
+func TestAsyncHandlerDropOldest(t *testing.T) {
+	handler := newGatedHandler()
+	async := fillBehindSlowWriter(t, handler, OverflowDropOldest)
+
+	close(handler.release)
+	async.Close()
+
+	if written := handler.written(); written != "r0,r3,r4,log records dropped" {
+		t.Errorf("Unexpected records: %s", written)
+	}
+}
+
+// TestAsyncHandlerBlock verifies that logging blocks while the buffer is full and nothing is lost
+func TestAsyncHandlerBlock(t *testing.T) {
+	handler := newGatedHandler()
+	async := NewAsyncHandler(handler, AsyncOptions{BufferSize: 2})
+	logger := slog.New(async)
+
+	logger.Info("r0")
+	<-handler.entered
+	logger.Info("r1")
+	logger.Info("r2")
+
+	done := make(chan struct{})
+	go func() {
+		logger.Info("r3")
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("Expected the logging call to block while the buffer is full")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(handler.release)
+	<-done
+	if err := async.Flush(context.Background()); err != nil {
+		t.Fatalf("Unexpected flush error: %v", err)
+	}
+	if written := handler.written(); written != "r0,r1,r2,r3" || async.Dropped() != 0 {
+		t.Errorf("Unexpected records %s with %d dropped", written, async.Dropped())
+	}
+	async.Close()
+}
+
+// TestAsyncHandlerFlushDoesNotWaitForLaterRecords verifies that Flush returns once the
+// records buffered before the call are written, even if later records are still pending
+func TestAsyncHandlerFlushDoesNotWaitForLaterRecords(t *testing.T) {
+	handler := newGatedHandler()
+	async := NewAsyncHandler(handler, AsyncOptions{DropReportInterval: -1})
+	defer async.Close()
+	defer close(handler.release)
+	logger := slog.New(async)
+
+	logger.Info("r0")
+	<-handler.entered
+	done := make(chan error, 1)
+	go func() { done <- async.Flush(context.Background()) }()
+
+	// r1 is logged after Flush has started and stays stuck in the writer
+	time.Sleep(20 * time.Millisecond)
+	logger.Info("r1")
+	handler.release <- struct{}{}
+	if message := <-handler.entered; message != "r1" {
+		t.Fatalf("Expected r1 to be written next, got %s", message)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Unexpected flush error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected Flush to return without waiting for r1")
+	}
+	if written := handler.written(); written != "r0" {
+		t.Errorf("Unexpected records: %s", written)
+	}
+}
+
+// TestAsyncHandlerClosesWrapped verifies that Close writes the buffered records and then
+// closes the wrapped file handler
+func TestAsyncHandlerClosesWrapped(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	handler, err := NewJsonFileHandler(path, RotationOptions{})
+	if err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	logger := NewLogger(NewAsyncHandler(handler, AsyncOptions{DropReportInterval: -1}))
+	logger.Info("buffered")
+
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Unexpected close error: %v", err)
+	}
+	if _, err := handler.File.Write([]byte("late\n")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Expected the wrapped file to be closed, got: %v", err)
+	}
+	if data, _ := os.ReadFile(path); !strings.Contains(string(data), "buffered") {
+		t.Errorf("Expected the buffered record in the file, got %q", data)
+	}
+}
+
+// TestAsyncHandlerPeriodicReport verifies that drops are reported without waiting for Close
+func TestAsyncHandlerPeriodicReport(t *testing.T) {
+	handler := newGatedHandler()
+	async := NewAsyncHandler(handler, AsyncOptions{BufferSize: 1, Policy: OverflowDropNewest, DropReportInterval: 5 * time.Millisecond})
+	defer async.Close()
+
+	logger := slog.New(async)
+	logger.Info("r0")
+	<-handler.entered
+	logger.Info("r1")
+	logger.Info("r2")
+	close(handler.release)
+
+	deadline := time.Now().Add(time.Second)
+	for !strings.Contains(handler.written(), "log records dropped") {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected a periodic drop report, got: %s", handler.written())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// TestAsyncHandlerLogger verifies attributes, flushing through the logger and writes after Close
+func TestAsyncHandlerLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(NewAsyncHandler(NewJsonHandler(&buf), AsyncOptions{}))
+	serviceLogger, _ := logger.StartService("ohlc-generator")
+
+	serviceLogger.WithGroup("tick").Info("processed", "symbol", "BTCUSDT")
+	if err := logger.Flush(context.Background()); err != nil {
+		t.Fatalf("Unexpected flush error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 log lines after flush, got %d: %s", len(lines), buf.String())
+	}
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[1]), &entry); err != nil {
+		t.Fatalf("Failed to parse JSON output: %v", err)
+	}
+	if entry["service"] != "ohlc-generator" || entry["tick"].(map[string]any)["symbol"] != "BTCUSDT" {
+		t.Errorf("Expected derived attributes, got %v", entry)
+	}
+
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Unexpected close error: %v", err)
+	}
+	logger.Info("after close")
+	if !strings.Contains(buf.String(), "after close") {
+		t.Errorf("Expected records after Close to be written synchronously, got: %s", buf.String())
+	}
+}
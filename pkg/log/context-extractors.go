@@ -0,0 +1,286 @@
+// Package log provides correlation of log records with the request context.
+// This file contains the context extractors that add attributes such as trace_id,
+// span_id, request_id and tenant_id from the context passed to InfoContext and the
+// other *Context methods, the W3C traceparent parsing and the request ID helpers.
+package log
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync/atomic"
+)
+
+// Attribute keys added by the built-in context extractors.
+const (
+	TraceIDKey   = "trace_id"
+	SpanIDKey    = "span_id"
+	RequestIDKey = "request_id"
+	TenantIDKey  = "tenant_id"
+)
+
+// HTTP headers read and written by the middlewares.
+const (
+	RequestIDHeader   = "X-Request-ID"
+	TraceparentHeader = "traceparent"
+)
+
+// ContextExtractor returns the attributes to add to a record logged with the context.
+// It is called for every record, so it must be cheap and return nil when the context
+// carries nothing of interest.
+//
+// Example of an extractor for a tracing SDK:
+//
+//	log.AddContextExtractor(func(ctx context.Context) []slog.Attr {
+//		span := trace.SpanContextFromContext(ctx)
+//		if !span.IsValid() {
+//			return nil
+//		}
+//		return []slog.Attr{
+//			slog.String(log.TraceIDKey, span.TraceID().String()),
+//			slog.String(log.SpanIDKey, span.SpanID().String()),
+//		}
+//	})
+type ContextExtractor func(ctx context.Context) []slog.Attr
+
+// contextExtractors are the extractors applied by all loggers created with NewLogger.
+var contextExtractors atomic.Pointer[[]ContextExtractor]
+
+func init() {
+	SetContextExtractors(TraceContextExtractor, RequestIDExtractor, TenantIDExtractor)
+}
+
+// SetContextExtractors replaces the extractors applied by all loggers created with
+// NewLogger. By default the trace context, request ID and tenant ID set with
+// WithTraceContext, WithRequestID and WithTenantID are extracted. No arguments
+// disable extraction.
+func SetContextExtractors(extractors ...ContextExtractor) {
+	extractors = append([]ContextExtractor(nil), extractors...)
+	contextExtractors.Store(&extractors)
+}
+
+// AddContextExtractor adds extractors to the ones applied by all loggers created with NewLogger.
+func AddContextExtractor(extractors ...ContextExtractor) {
+	SetContextExtractors(append(GetContextExtractors(), extractors...)...)
+}
+
+// GetContextExtractors returns the extractors applied by all loggers created with NewLogger.
+func GetContextExtractors() []ContextExtractor {
+	return append([]ContextExtractor(nil), *contextExtractors.Load()...)
+}
+
+// extractContext returns the attributes of the context extractors for a record. Keys
+// already present in the top-level logger attributes are skipped, and so are keys of
+// the record attributes unless the record belongs to a group.
+func extractContext(ctx context.Context, record slog.Record, present map[string]string, grouped bool) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	extractors := *contextExtractors.Load()
+	if len(extractors) == 0 {
+		return nil
+	}
+
+	var attrs []slog.Attr
+	for _, extract := range extractors {
+		for _, attr := range extract(ctx) {
+			if _, ok := present[attr.Key]; !ok {
+				attrs = append(attrs, attr)
+			}
+		}
+	}
+	if len(attrs) == 0 || grouped || record.NumAttrs() == 0 {
+		return attrs
+	}
+
+	own := make(map[string]bool, record.NumAttrs())
+	record.Attrs(func(attr slog.Attr) bool {
+		own[attr.Key] = true
+		return true
+	})
+	return slices.DeleteFunc(attrs, func(attr slog.Attr) bool { return own[attr.Key] })
+}
+
+// contextKey is the type of the context keys of this file.
+type contextKey int
+
+const (
+	traceContextKey contextKey = iota
+	requestIDKey
+	tenantIDKey
+)
+
+// TraceContext identifies the trace and the span of an operation, as carried by the
+// W3C traceparent header.
+type TraceContext struct {
+	TraceID string // 32 lowercase hex characters
+	SpanID  string // 16 lowercase hex characters
+	Flags   byte   // Trace flags, bit 0 means sampled
+}
+
+// ErrInvalidTraceparent is returned by ParseTraceparent for malformed headers.
+var ErrInvalidTraceparent = errors.New("invalid traceparent")
+
+// NewTraceContext starts a new sampled trace with random IDs.
+func NewTraceContext() TraceContext {
+	return TraceContext{TraceID: randomHex(16), SpanID: randomHex(8), Flags: 1}
+}
+
+// ParseTraceparent parses a W3C traceparent header, e.g.
+// "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01".
+// Headers of future versions are accepted as long as their first four fields are valid.
+func ParseTraceparent(header string) (TraceContext, error) {
+	header = strings.TrimSpace(header)
+	parts := strings.SplitN(header, "-", 5)
+	if len(parts) < 4 {
+		return TraceContext{}, fmt.Errorf("%w %q", ErrInvalidTraceparent, header)
+	}
+	version, traceID, spanID, flags := parts[0], parts[1], parts[2], parts[3]
+	if !isHex(version, 2) || version == "ff" || (version == "00" && len(parts) != 4) {
+		return TraceContext{}, fmt.Errorf("%w version in %q", ErrInvalidTraceparent, header)
+	}
+	if !isHex(traceID, 32) || traceID == strings.Repeat("0", 32) {
+		return TraceContext{}, fmt.Errorf("%w trace ID in %q", ErrInvalidTraceparent, header)
+	}
+	if !isHex(spanID, 16) || spanID == strings.Repeat("0", 16) {
+		return TraceContext{}, fmt.Errorf("%w span ID in %q", ErrInvalidTraceparent, header)
+	}
+	if !isHex(flags, 2) {
+		return TraceContext{}, fmt.Errorf("%w flags in %q", ErrInvalidTraceparent, header)
+	}
+	decoded, _ := hex.DecodeString(flags)
+	return TraceContext{TraceID: traceID, SpanID: spanID, Flags: decoded[0]}, nil
+}
+
+// Traceparent formats the trace context as a version 00 W3C traceparent header.
+func (tc TraceContext) Traceparent() string {
+	return fmt.Sprintf("00-%s-%s-%02x", tc.TraceID, tc.SpanID, tc.Flags)
+}
+
+// Sampled reports whether the sampled flag is set.
+func (tc TraceContext) Sampled() bool {
+	return tc.Flags&1 == 1
+}
+
+// ChildSpan returns the trace context of a new span in the same trace.
+func (tc TraceContext) ChildSpan() TraceContext {
+	tc.SpanID = randomHex(8)
+	return tc
+}
+
+// WithTraceContext adds the trace context to the context, so that records logged
+// with it carry trace_id and span_id.
+func WithTraceContext(ctx context.Context, tc TraceContext) context.Context {
+	return context.WithValue(ctx, traceContextKey, tc)
+}
+
+// TraceContextFromContext returns the trace context added with WithTraceContext.
+func TraceContextFromContext(ctx context.Context) (TraceContext, bool) {
+	tc, ok := ctx.Value(traceContextKey).(TraceContext)
+	return tc, ok
+}
+
+// TraceContextExtractor adds trace_id and span_id from the trace context of the context.
+func TraceContextExtractor(ctx context.Context) []slog.Attr {
+	tc, ok := TraceContextFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	return []slog.Attr{slog.String(TraceIDKey, tc.TraceID), slog.String(SpanIDKey, tc.SpanID)}
+}
+
+// NewRequestID returns a random request ID of 32 hex characters.
+func NewRequestID() string {
+	return randomHex(16)
+}
+
+// WithRequestID adds the request ID to the context, so that records logged with it
+// carry request_id.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestIDFromContext returns the request ID added with WithRequestID.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey).(string)
+	return id, ok && id != ""
+}
+
+// RequestIDExtractor adds request_id from the request ID of the context.
+func RequestIDExtractor(ctx context.Context) []slog.Attr {
+	if id, ok := RequestIDFromContext(ctx); ok {
+		return []slog.Attr{slog.String(RequestIDKey, id)}
+	}
+	return nil
+}
+
+// WithTenantID adds the tenant ID to the context, so that records logged with it
+// carry tenant_id.
+func WithTenantID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, tenantIDKey, id)
+}
+
+// TenantIDFromContext returns the tenant ID added with WithTenantID.
+func TenantIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(tenantIDKey).(string)
+	return id, ok && id != ""
+}
+
+// TenantIDExtractor adds tenant_id from the tenant ID of the context.
+func TenantIDExtractor(ctx context.Context) []slog.Attr {
+	if id, ok := TenantIDFromContext(ctx); ok {
+		return []slog.Attr{slog.String(TenantIDKey, id)}
+	}
+	return nil
+}
+
+// RequestIDMiddleware adds the request ID from the X-Request-ID header, or a new one,
+// to the request context and the response headers. A valid traceparent header is
+// added to the context as well, with a new span ID for this service.
+//
+// Example:
+//
+//	http.ListenAndServe(":8080", log.RequestIDMiddleware(mux))
+//	// In a handler: logger.InfoContext(r.Context(), "order created")
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		id := r.Header.Get(RequestIDHeader)
+		if id == "" || len(id) > 128 {
+			id = NewRequestID()
+		}
+		ctx = WithRequestID(ctx, id)
+		if tc, err := ParseTraceparent(r.Header.Get(TraceparentHeader)); err == nil {
+			ctx = WithTraceContext(ctx, tc.ChildSpan())
+		}
+
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// isHex reports whether s has n lowercase hex characters.
+func isHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// randomHex returns n random bytes encoded as hex.
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,236 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// setContextExtractors changes the context extractors for the duration of the test
+func setContextExtractors(t *testing.T, extractors ...ContextExtractor) {
+	t.Helper()
+
+	previous := GetContextExtractors()
+	SetContextExtractors(extractors...)
+	t.Cleanup(func() { SetContextExtractors(previous...) })
+}
+
+// decodeRecord decodes a single JSON record
+func decodeRecord(t *testing.T, line string) map[string]any {
+	t.Helper()
+
+	var record map[string]any
+	if err := json.Unmarshal([]byte(line), &record); err != nil {
+		t.Fatalf("Failed to decode record %q: %v", line, err)
+	}
+	return record
+}
+
+// TestParseTraceparent verifies the W3C traceparent parsing and formatting
+func TestParseTraceparent(t *testing.T) {
+	header := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	tc, err := ParseTraceparent(header)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if tc.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || tc.SpanID != "00f067aa0ba902b7" || !tc.Sampled() {
+		t.Errorf("Unexpected trace context: %+v", tc)
+	}
+	if tc.Traceparent() != header {
+		t.Errorf("Expected %s, got %s", header, tc.Traceparent())
+	}
+	if _, err := ParseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-future"); err != nil {
+		t.Errorf("Expected future versions to be accepted, got %v", err)
+	}
+
+	for _, invalid := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+	} {
+		if _, err := ParseTraceparent(invalid); !errors.Is(err, ErrInvalidTraceparent) {
+			t.Errorf("ParseTraceparent(%q): expected ErrInvalidTraceparent, got %v", invalid, err)
+		}
+	}
+
+	generated := NewTraceContext()
+	if parsed, err := ParseTraceparent(generated.Traceparent()); err != nil || parsed != generated {
+		t.Errorf("Expected generated trace context to round-trip, got %+v, %v", parsed, err)
+	}
+	if child := generated.ChildSpan(); child.TraceID != generated.TraceID || child.SpanID == generated.SpanID {
+		t.Errorf("Expected a new span in the same trace, got %+v", child)
+	}
+}
+
+// TestContextExtractors verifies that the default extractors add the context attributes to records
+func TestContextExtractors(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(NewJsonHandler(&buf))
+
+	tc, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := WithTraceContext(context.Background(), tc)
+	ctx = WithRequestID(ctx, "req-1")
+	ctx = WithTenantID(ctx, "tenant-abc")
+
+	logger.InfoContext(ctx, "order created", "order_id", 7)
+	record := decodeRecord(t, buf.String())
+	expected := map[string]any{
+		TraceIDKey:   "4bf92f3577b34da6a3ce929d0e0e4736",
+		SpanIDKey:    "00f067aa0ba902b7",
+		RequestIDKey: "req-1",
+		TenantIDKey:  "tenant-abc",
+		"order_id":   float64(7),
+	}
+	for key, value := range expected {
+		if record[key] != value {
+			t.Errorf("Expected %s=%v, got %v", key, value, record[key])
+		}
+	}
+
+	buf.Reset()
+	logger.Info("without context")
+	if strings.Contains(buf.String(), RequestIDKey) {
+		t.Errorf("Expected no context attributes, got: %s", buf.String())
+	}
+
+	buf.Reset()
+	logger.With(TenantIDKey, "tenant-fixed").InfoContext(ctx, "fixed tenant")
+	if strings.Count(buf.String(), TenantIDKey) != 1 || !strings.Contains(buf.String(), "tenant-fixed") {
+		t.Errorf("Expected the logger attribute to win, got: %s", buf.String())
+	}
+}
+
+// TestContextExtractorsTopLevel verifies that context attributes stay top-level inside
+// groups and are not added again when the record has them
+func TestContextExtractorsTopLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(NewJsonHandler(&buf))
+	ctx := WithRequestID(context.Background(), "req-1")
+
+	logger.With("service", "gateway").WithGroup("http").With("method", "GET").WithGroup("response").
+		InfoContext(ctx, "request served", "status", 200)
+	record := decodeRecord(t, buf.String())
+	if record[RequestIDKey] != "req-1" || record["service"] != "gateway" {
+		t.Errorf("Expected top-level request_id and service, got %v", record)
+	}
+	http, _ := record["http"].(map[string]any)
+	response, _ := http["response"].(map[string]any)
+	if http["method"] != "GET" || response["status"] != float64(200) || http[RequestIDKey] != nil {
+		t.Errorf("Expected the grouped attributes without request_id, got %v", record)
+	}
+
+	buf.Reset()
+	logger.InfoContext(ctx, "explicit request", RequestIDKey, "req-override")
+	if strings.Count(buf.String(), RequestIDKey) != 1 || !strings.Contains(buf.String(), "req-override") {
+		t.Errorf("Expected the record attribute to win, got: %s", buf.String())
+	}
+}
+
+// derivingHandler counts the handlers derived from it with WithAttrs and WithGroup
+type derivingHandler struct {
+	slog.Handler
+	derived *atomic.Int32
+}
+
+func (h derivingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h.derived.Add(1)
+	return derivingHandler{Handler: h.Handler.WithAttrs(attrs), derived: h.derived}
+}
+
+func (h derivingHandler) WithGroup(name string) slog.Handler {
+	h.derived.Add(1)
+	return derivingHandler{Handler: h.Handler.WithGroup(name), derived: h.derived}
+}
+
+// TestContextExtractorsGroupedLogger verifies that records of a grouped logger get the
+// context attributes without deriving handlers per record and keep the grouped layout
+func TestContextExtractorsGroupedLogger(t *testing.T) {
+	var buf bytes.Buffer
+	derived := &atomic.Int32{}
+	logger := NewLogger(derivingHandler{Handler: NewJsonHandler(&buf), derived: derived}).
+		WithGroup("http").With("method", "GET")
+	created := derived.Load()
+
+	ctx := WithRequestID(context.Background(), "req-1")
+	for i := 0; i < 3; i++ {
+		logger.InfoContext(ctx, "request served", slog.Group("response", "status", 200))
+	}
+	if derived.Load() != created {
+		t.Errorf("Expected no handlers derived per record, got %d", derived.Load()-created)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	withContext := decodeRecord(t, lines[0])
+	buf.Reset()
+	logger.Info("request served", slog.Group("response", "status", 200))
+	withoutContext := decodeRecord(t, buf.String())
+	if withContext[RequestIDKey] != "req-1" ||
+		fmt.Sprint(withContext["http"]) != fmt.Sprint(withoutContext["http"]) {
+		t.Errorf("Expected the same grouped attributes, got %v and %v", withContext, withoutContext)
+	}
+}
+
+// TestCustomContextExtractor verifies custom extractors and that their values are redacted
+func TestCustomContextExtractor(t *testing.T) {
+	type userKey struct{}
+	setContextExtractors(t, func(ctx context.Context) []slog.Attr {
+		if email, ok := ctx.Value(userKey{}).(string); ok {
+			return []slog.Attr{slog.String("user", email)}
+		}
+		return nil
+	})
+	setRedactor(t, NewRedactor(DefaultRedactionOptions()))
+
+	var buf bytes.Buffer
+	logger := NewLogger(NewJsonHandler(&buf))
+	ctx := WithRequestID(context.WithValue(context.Background(), userKey{}, "bob@example.com"), "req-1")
+	logger.InfoContext(ctx, "custom")
+
+	output := buf.String()
+	if !strings.Contains(output, `"user":"[REDACTED]"`) || strings.Contains(output, RequestIDKey) {
+		t.Errorf("Expected only the redacted custom attribute, got: %s", output)
+	}
+}
+
+// TestRequestIDMiddleware verifies that the middleware propagates the request ID and trace context
+func TestRequestIDMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(NewJsonHandler(&buf))
+	handler := RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger.InfoContext(r.Context(), "handled")
+	}))
+
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request.Header.Set(RequestIDHeader, "req-42")
+	request.Header.Set(TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	record := decodeRecord(t, buf.String())
+	if record[RequestIDKey] != "req-42" || recorder.Header().Get(RequestIDHeader) != "req-42" {
+		t.Errorf("Expected request ID req-42, got %v and %s", record[RequestIDKey], recorder.Header().Get(RequestIDHeader))
+	}
+	if record[TraceIDKey] != "4bf92f3577b34da6a3ce929d0e0e4736" || record[SpanIDKey] == "00f067aa0ba902b7" {
+		t.Errorf("Expected the trace with a new span, got %v", record)
+	}
+
+	buf.Reset()
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	generated := recorder.Header().Get(RequestIDHeader)
+	if len(generated) != 32 || decodeRecord(t, buf.String())[RequestIDKey] != generated {
+		t.Errorf("Expected a generated request ID, got %q: %s", generated, buf.String())
+	}
+}
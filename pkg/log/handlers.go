@@ -9,19 +9,13 @@ import (
 	"os"
 )
 
-// envEnableDebugLogLevel is the environment variable name that controls debug logging.
-// Set this to "true" to enable DEBUG level logging, otherwise INFO level is used.
-const envEnableDebugLogLevel = "ENABLE_DEBUG_LOG_LEVEL"
-
-// makeOptions creates slog.HandlerOptions with appropriate log level based on environment.
-// It checks the ENABLE_DEBUG_LOG_LEVEL environment variable to determine the log level.
+// makeOptions creates slog.HandlerOptions that follow the shared runtime-adjustable level
+// (see SetLevel) and the level rules (see SetLevelRules), and render the custom TRACE
+// and FATAL levels by name.
 func makeOptions() *slog.HandlerOptions {
-	var logLevel = slog.LevelInfo
-	if os.Getenv(envEnableDebugLogLevel) == "true" {
-		logLevel = slog.LevelDebug
-	}
 	return &slog.HandlerOptions{
-		Level: logLevel,
+		Level:       handlerLevel{},
+		ReplaceAttr: replaceLevelName,
 	}
 }
 
@@ -6,7 +6,6 @@ import (
 	"encoding/json"
 	"errors"
 	"log/slog"
-	"os"
 	"strings"
 	"testing"
 	"time"
@@ -55,35 +54,40 @@ func TestNewJsonHandler(t *testing.T) {
 	}
 }
 
-// TestMakeOptions verifies log level configuration based on environment
+// TestMakeOptions verifies the initial log level configuration based on environment
+// and that all handlers share the runtime-adjustable level
 func TestMakeOptions(t *testing.T) {
 	tests := []struct {
-		name     string
-		envValue string
-		expected slog.Level
+		name       string
+		levelValue string
+		debugValue string
+		expected   slog.Level
 	}{
-		{"default level", "", slog.LevelInfo},
-		{"debug enabled", "true", slog.LevelDebug},
-		{"debug disabled", "false", slog.LevelInfo},
-		{"invalid value", "invalid", slog.LevelInfo},
+		{"default level", "", "", slog.LevelInfo},
+		{"debug enabled", "", "true", slog.LevelDebug},
+		{"debug disabled", "", "false", slog.LevelInfo},
+		{"invalid value", "", "invalid", slog.LevelInfo},
+		{"app log level", "warn", "", slog.LevelWarn},
+		{"custom level", "trace", "", LevelTrace},
+		{"app log level wins", "error", "true", slog.LevelError},
+		{"invalid app log level", "verbose", "true", slog.LevelDebug},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Set environment variable
-			if tt.envValue != "" {
-				os.Setenv(envEnableDebugLogLevel, tt.envValue)
-			} else {
-				os.Unsetenv(envEnableDebugLogLevel)
-			}
-			defer os.Unsetenv(envEnableDebugLogLevel)
+			t.Setenv(envLogLevel, tt.levelValue)
+			t.Setenv(envEnableDebugLogLevel, tt.debugValue)
 
-			options := makeOptions()
-			if options.Level != tt.expected {
-				t.Errorf("Expected level %v, got %v", tt.expected, options.Level)
+			if got := levelFromEnv(); got != tt.expected {
+				t.Errorf("Expected level %v, got %v", tt.expected, got)
 			}
 		})
 	}
+
+	options := makeOptions()
+	if _, ok := options.Level.(handlerLevel); !ok || options.Level.Level() != Level() {
+		t.Errorf("Expected handlers to follow the shared level, got %v", options.Level)
+	}
 }
 
 // TestCustomLevelHandler verifies custom level configuration works correctly
@@ -0,0 +1,199 @@
+// Package log provides level overrides for loggers with matching attributes.
+// This file contains the level rules, e.g. "service=ohlc-generator:debug,*:info",
+// their parsing and the matching against the attributes of a derived logger.
+package log
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// envLogLevelRules is the environment variable name that sets the initial level rules,
+// e.g. APP_LOG_LEVEL_RULES=service=ohlc-generator:debug,*:info.
+const envLogLevelRules = "APP_LOG_LEVEL_RULES"
+
+// LevelRule sets the level of loggers whose attributes match all Match entries.
+// A rule without Match entries is the wildcard rule "*" that matches every logger.
+type LevelRule struct {
+	Match map[string]string // Attribute key to value, e.g. service=ohlc-generator
+	Level slog.Level        // Minimum level of matching loggers
+}
+
+// LevelRules is an ordered list of level rules. For every logger the most specific
+// matching rule wins, i.e. the rule with the most Match entries; between equally
+// specific rules the first one wins. Loggers matching no rule use the shared level.
+//
+// A rule can only lower the level of a logger as far as its handlers let records
+// through: the handlers created by this package follow the rules, while a handler with
+// its own slog.HandlerOptions.Level, such as LevelVar, still drops the records below
+// that level. Use HandlerLeveler as the level of such handlers.
+type LevelRules []LevelRule
+
+// compiledRules holds the active rules with their lowest level.
+// A new value is stored on every change, so its address identifies the rules version.
+type compiledRules struct {
+	rules    LevelRules
+	minLevel slog.Level
+}
+
+// levelRules holds the active level rules, nil if there are none.
+var levelRules atomic.Pointer[compiledRules]
+
+func init() {
+	if spec := strings.TrimSpace(os.Getenv(envLogLevelRules)); spec != "" {
+		if rules, err := ParseLevelRules(spec); err == nil {
+			SetLevelRules(rules)
+		}
+	}
+}
+
+// ParseLevelRules parses a comma-separated list of rules. A rule is a selector and
+// a level (see ParseLevel) separated by the last colon. The selector is "*" or one or
+// more key=value conditions joined by "&".
+//
+// Example:
+//
+//	rules, err := log.ParseLevelRules("service=ohlc-generator:debug,service=redis-consumer&task=reader:trace,*:info")
+func ParseLevelRules(spec string) (LevelRules, error) {
+	var rules LevelRules
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		i := strings.LastIndex(part, ":")
+		if i < 0 {
+			return nil, fmt.Errorf("level rule %q: missing level, expected <selector>:<level>", part)
+		}
+		level, err := ParseLevel(part[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("level rule %q: %w", part, err)
+		}
+
+		rule := LevelRule{Level: level}
+		if selector := strings.TrimSpace(part[:i]); selector != "*" {
+			rule.Match = make(map[string]string)
+			for _, condition := range strings.Split(selector, "&") {
+				key, value, ok := strings.Cut(condition, "=")
+				key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+				if !ok || key == "" {
+					return nil, fmt.Errorf("level rule %q: invalid condition %q, expected key=value", part, condition)
+				}
+				rule.Match[key] = value
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// String formats the rules in the ParseLevelRules syntax with level names and sorted conditions.
+func (r LevelRules) String() string {
+	parts := make([]string, len(r))
+	for i, rule := range r {
+		parts[i] = rule.selector() + ":" + LevelName(rule.Level)
+	}
+	return strings.Join(parts, ",")
+}
+
+// selector formats the rule conditions.
+func (r LevelRule) selector() string {
+	if len(r.Match) == 0 {
+		return "*"
+	}
+	conditions := make([]string, 0, len(r.Match))
+	for key, value := range r.Match {
+		conditions = append(conditions, key+"="+value)
+	}
+	sort.Strings(conditions)
+	return strings.Join(conditions, "&")
+}
+
+// SetLevelRules replaces the active level rules of all loggers created with NewLogger,
+// including loggers created before the call. Nil or empty rules remove the overrides.
+// Rules lowering the level only reach handlers that follow them (see LevelRules).
+func SetLevelRules(rules LevelRules) {
+	if len(rules) == 0 {
+		levelRules.Store(nil)
+		return
+	}
+	compiled := &compiledRules{rules: append(LevelRules(nil), rules...), minLevel: rules[0].Level}
+	for _, rule := range rules[1:] {
+		compiled.minLevel = min(compiled.minLevel, rule.Level)
+	}
+	levelRules.Store(compiled)
+}
+
+// SetLevelRulesString parses the rules with ParseLevelRules and replaces the active rules.
+func SetLevelRulesString(spec string) error {
+	rules, err := ParseLevelRules(spec)
+	if err != nil {
+		return err
+	}
+	SetLevelRules(rules)
+	return nil
+}
+
+// GetLevelRules returns a copy of the active level rules, nil if there are none.
+func GetLevelRules() LevelRules {
+	compiled := levelRules.Load()
+	if compiled == nil {
+		return nil
+	}
+	return append(LevelRules(nil), compiled.rules...)
+}
+
+// match returns the level of the most specific rule matching the attributes.
+func (c *compiledRules) match(attrs map[string]string) (slog.Level, bool) {
+	best := -1
+	for i, rule := range c.rules {
+		if best >= 0 && len(rule.Match) <= len(c.rules[best].Match) {
+			continue
+		}
+		matched := true
+		for key, value := range rule.Match {
+			if v, ok := attrs[key]; !ok || v != value {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			best = i
+		}
+	}
+	if best < 0 {
+		return 0, false
+	}
+	return c.rules[best].Level, true
+}
+
+// handlerLevel is the slog.Leveler of the handlers created by this package. It reports
+// the shared level, lowered to the lowest rule level while rules are active, so that
+// records a rule lets through reach the handlers; the exact filtering is done by the
+// multiHandler of the logger.
+type handlerLevel struct{}
+
+// HandlerLeveler returns the level of the handlers created by this package, for handlers
+// created outside this package that must follow the runtime level and the level rules.
+// The filtering by rule is done by the logger, so the handler must be used with NewLogger.
+//
+// Example:
+//
+//	handler := slog.NewJSONHandler(file, &slog.HandlerOptions{Level: log.HandlerLeveler()})
+func HandlerLeveler() slog.Leveler {
+	return handlerLevel{}
+}
+
+// Level returns the lowest level any logger may currently log at.
+func (handlerLevel) Level() slog.Level {
+	l := level.Level()
+	if compiled := levelRules.Load(); compiled != nil {
+		l = min(l, compiled.minLevel)
+	}
+	return l
+}
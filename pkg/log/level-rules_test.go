@@ -0,0 +1,181 @@
+package log
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// setLevelRules changes the active rules for the duration of the test
+func setLevelRules(t *testing.T, spec string) {
+	t.Helper()
+
+	previous := GetLevelRules()
+	if err := SetLevelRulesString(spec); err != nil {
+		t.Fatalf("Failed to set rules %q: %v", spec, err)
+	}
+	t.Cleanup(func() { SetLevelRules(previous) })
+}
+
+// TestParseLevelRules verifies the rule syntax and its canonical formatting
+func TestParseLevelRules(t *testing.T) {
+	rules, err := ParseLevelRules(" service=ohlc-generator:debug, task=reader&service=redis-consumer:trace+1 ,*:info")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(rules) != 3 || rules[0].Match["service"] != "ohlc-generator" || rules[0].Level != slog.LevelDebug {
+		t.Errorf("Unexpected rules: %+v", rules)
+	}
+	expected := "service=ohlc-generator:DEBUG,service=redis-consumer&task=reader:TRACE+1,*:INFO"
+	if rules.String() != expected {
+		t.Errorf("Expected %s, got %s", expected, rules.String())
+	}
+
+	for _, spec := range []string{"debug", "service=x:loud", "service:debug", "=x:debug"} {
+		if _, err := ParseLevelRules(spec); err == nil {
+			t.Errorf("ParseLevelRules(%q): expected an error", spec)
+		}
+	}
+}
+
+// TestLevelRulesFiltering verifies that records are filtered by the most specific matching rule
+func TestLevelRulesFiltering(t *testing.T) {
+	setLevel(t, slog.LevelInfo)
+	setLevelRules(t, "service=ohlc-generator:debug,service=redis-consumer&task=reader:trace,service=redis-consumer:warn")
+
+	var buf bytes.Buffer
+	logger := NewLogger(NewJsonHandler(&buf))
+	generator, _ := logger.StartService("ohlc-generator")
+	consumer, _ := logger.StartService("redis-consumer")
+	reader := &Logger{Logger: consumer.With("task", "reader")}
+	grouped := &Logger{Logger: logger.WithGroup("request").With("service", "ohlc-generator")}
+
+	buf.Reset()
+	generator.Debug("generator debug")
+	logger.Debug("root debug")
+	logger.Info("root info")
+	consumer.Info("consumer info")
+	consumer.Warn("consumer warn")
+	reader.Trace("reader trace")
+	grouped.Debug("grouped debug")
+
+	output := buf.String()
+	for _, message := range []string{"generator debug", "root info", "consumer warn", "reader trace"} {
+		if !strings.Contains(output, message) {
+			t.Errorf("Expected %q to be logged, got:\n%s", message, output)
+		}
+	}
+	for _, message := range []string{"root debug", "consumer info", "grouped debug"} {
+		if strings.Contains(output, message) {
+			t.Errorf("Expected %q to be filtered, got:\n%s", message, output)
+		}
+	}
+}
+
+// TestLevelRulesWildcard verifies that the wildcard rule overrides the shared level
+func TestLevelRulesWildcard(t *testing.T) {
+	setLevel(t, slog.LevelDebug)
+	setLevelRules(t, "service=ohlc-generator:debug,*:warn")
+
+	var buf bytes.Buffer
+	logger := NewLogger(NewJsonHandler(&buf))
+	generator := &Logger{Logger: logger.With("service", "ohlc-generator")}
+
+	logger.Info("root info")
+	generator.Debug("generator debug")
+
+	if strings.Contains(buf.String(), "root info") || !strings.Contains(buf.String(), "generator debug") {
+		t.Errorf("Unexpected output:\n%s", buf.String())
+	}
+}
+
+// TestLevelRulesChange verifies that cached decisions follow rule changes at runtime
+func TestLevelRulesChange(t *testing.T) {
+	setLevel(t, slog.LevelInfo)
+	setLevelRules(t, "service=ohlc-generator:warn")
+
+	var buf bytes.Buffer
+	generator := &Logger{Logger: NewLogger(NewJsonHandler(&buf)).With("service", "ohlc-generator")}
+
+	generator.Info("first info")
+	if err := SetLevelRulesString("service=ohlc-generator:debug"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	generator.Debug("second debug")
+	SetLevelRules(nil)
+	generator.Debug("third debug")
+	generator.Info("third info")
+
+	output := buf.String()
+	if strings.Contains(output, "first info") || !strings.Contains(output, "second debug") ||
+		strings.Contains(output, "third debug") || !strings.Contains(output, "third info") {
+		t.Errorf("Unexpected output:\n%s", output)
+	}
+}
+
+// TestLevelRulesExternalHandler verifies that rules lower the level of handlers created
+// with HandlerLeveler but not of handlers bound to the shared level variable
+func TestLevelRulesExternalHandler(t *testing.T) {
+	setLevel(t, slog.LevelInfo)
+	setLevelRules(t, "service=ohlc-generator:debug")
+
+	var following, shared bytes.Buffer
+	for _, handler := range []slog.Handler{
+		slog.NewJSONHandler(&following, &slog.HandlerOptions{Level: HandlerLeveler()}),
+		slog.NewJSONHandler(&shared, &slog.HandlerOptions{Level: LevelVar()}),
+	} {
+		logger := NewLogger(handler)
+		logger.Debug("root debug")
+		logger.With("service", "ohlc-generator").Debug("generator debug")
+	}
+
+	if output := following.String(); strings.Contains(output, "root debug") || !strings.Contains(output, "generator debug") {
+		t.Errorf("Expected only the generator record in the following handler, got:\n%s", output)
+	}
+	if output := shared.String(); output != "" {
+		t.Errorf("Expected the shared level handler to drop debug records, got:\n%s", output)
+	}
+}
+
+// TestLevelHandlerRules verifies changing the rules over HTTP
+func TestLevelHandlerRules(t *testing.T) {
+	setLevel(t, slog.LevelInfo)
+	setLevelRules(t, "")
+	handler := LevelHandler()
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"rules":"service=ohlc-generator:debug"}`)))
+	expected := `{"level":"INFO","rules":"service=ohlc-generator:DEBUG"}`
+	if body := strings.TrimSpace(recorder.Body.String()); recorder.Code != http.StatusOK || body != expected {
+		t.Errorf("Expected %s, got %d: %s", expected, recorder.Code, body)
+	}
+
+	// An invalid rule leaves both settings unchanged
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPut, "/?level=debug&rules=service:debug", nil))
+	if recorder.Code != http.StatusBadRequest || Level() != slog.LevelInfo || GetLevelRules().String() != "service=ohlc-generator:DEBUG" {
+		t.Errorf("Expected the request to be rejected, got %d, level %v, rules %s", recorder.Code, Level(), GetLevelRules())
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPut, "/?rules=", nil))
+	if recorder.Code != http.StatusOK || GetLevelRules() != nil {
+		t.Errorf("Expected the rules to be removed, got %d, rules %s", recorder.Code, GetLevelRules())
+	}
+}
+
+// BenchmarkLevelRules_Disabled measures filtering a disabled record with active rules
+func BenchmarkLevelRules_Disabled(b *testing.B) {
+	previous := GetLevelRules()
+	SetLevelRulesString("service=ohlc-generator:debug,service=redis-consumer:warn")
+	defer SetLevelRules(previous)
+
+	logger, _ := NewLogger(NewJsonHandler(&bytes.Buffer{})).StartService("redis-consumer")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Debug("message", "key", "value")
+	}
+}
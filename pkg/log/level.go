@@ -0,0 +1,266 @@
+// Package log provides the runtime-adjustable log level shared by the handler constructors.
+// This file contains the custom TRACE and FATAL levels, level parsing from config and
+// environment, and the HTTP handler that reads and changes the level and the level
+// rules at runtime.
+package log
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Custom levels in addition to the slog levels.
+const (
+	LevelTrace = slog.Level(-8) // More verbose than DEBUG, e.g. every received message
+	LevelFatal = slog.Level(12) // More severe than ERROR, the application cannot continue
+)
+
+// envLogLevel is the environment variable name that sets the initial log level,
+// e.g. APP_LOG_LEVEL=debug. It takes precedence over ENABLE_DEBUG_LOG_LEVEL.
+const envLogLevel = "APP_LOG_LEVEL"
+
+// envEnableDebugLogLevel is the legacy environment variable name that controls debug logging.
+// Set this to "true" to enable DEBUG level logging when APP_LOG_LEVEL is not set.
+const envEnableDebugLogLevel = "ENABLE_DEBUG_LOG_LEVEL"
+
+// level is the log level shared by all handlers created by this package.
+var level = newLevelVar(levelFromEnv())
+
+// newLevelVar creates a level variable set to l.
+func newLevelVar(l slog.Level) *slog.LevelVar {
+	v := new(slog.LevelVar)
+	v.Set(l)
+	return v
+}
+
+// levelFromEnv returns the initial log level from APP_LOG_LEVEL or, if it is not set
+// or invalid, from ENABLE_DEBUG_LOG_LEVEL. The default level is INFO.
+func levelFromEnv() slog.Level {
+	if value := os.Getenv(envLogLevel); value != "" {
+		if l, err := ParseLevel(value); err == nil {
+			return l
+		}
+	}
+	if os.Getenv(envEnableDebugLogLevel) == "true" {
+		return slog.LevelDebug
+	}
+	return slog.LevelInfo
+}
+
+// LevelConfig holds the log level of an application config.
+// It can be parsed from the environment with config.ParseEnv or embedded in an
+// application config.
+type LevelConfig struct {
+	Level string `env:"APP_LOG_LEVEL" yaml:"log_level"`             // Level name, e.g. "debug" or "warn"
+	Rules string `env:"APP_LOG_LEVEL_RULES" yaml:"log_level_rules"` // Level rules, e.g. "service=ohlc-generator:debug,*:info"
+}
+
+// Apply sets the shared log level and the level rules from the config.
+// Empty fields leave the current settings unchanged.
+func (c LevelConfig) Apply() error {
+	if c.Level != "" {
+		if err := SetLevelString(c.Level); err != nil {
+			return err
+		}
+	}
+	if c.Rules != "" {
+		return SetLevelRulesString(c.Rules)
+	}
+	return nil
+}
+
+// Level returns the current shared log level.
+func Level() slog.Level {
+	return level.Level()
+}
+
+// SetLevel changes the shared log level of all handlers created by this package,
+// including handlers created before the call.
+func SetLevel(l slog.Level) {
+	level.Set(l)
+}
+
+// SetLevelString parses the level name with ParseLevel and changes the shared log level.
+func SetLevelString(name string) error {
+	l, err := ParseLevel(name)
+	if err != nil {
+		return err
+	}
+	SetLevel(l)
+	return nil
+}
+
+// LevelVar returns the shared level variable, for handlers created outside this package
+// that must follow the runtime level. Such handlers drop the records of loggers whose
+// level rule is below the shared level; use HandlerLeveler to follow the rules as well.
+//
+// Example:
+//
+//	handler := slog.NewJSONHandler(file, &slog.HandlerOptions{Level: log.LevelVar()})
+func LevelVar() *slog.LevelVar {
+	return level
+}
+
+// ParseLevel parses a case-insensitive level name: trace, debug, info, warn (or warning),
+// error or fatal, optionally with an offset such as "debug+2", or a numeric slog level.
+func ParseLevel(name string) (slog.Level, error) {
+	name = strings.TrimSpace(name)
+	if n, err := strconv.Atoi(name); err == nil {
+		return slog.Level(n), nil
+	}
+
+	base, offset, hasOffset := name, "", false
+	if i := strings.IndexAny(name, "+-"); i > 0 {
+		base, offset, hasOffset = name[:i], name[i:], true
+	}
+
+	var l slog.Level
+	switch strings.ToUpper(base) {
+	case "TRACE":
+		l = LevelTrace
+	case "DEBUG":
+		l = slog.LevelDebug
+	case "INFO":
+		l = slog.LevelInfo
+	case "WARN", "WARNING":
+		l = slog.LevelWarn
+	case "ERROR":
+		l = slog.LevelError
+	case "FATAL":
+		l = LevelFatal
+	default:
+		return 0, fmt.Errorf("unknown log level %q", name)
+	}
+
+	if hasOffset {
+		n, err := strconv.Atoi(offset)
+		if err != nil {
+			return 0, fmt.Errorf("invalid log level offset in %q: %w", name, err)
+		}
+		l += slog.Level(n)
+	}
+	return l, nil
+}
+
+// LevelName returns the level name, using TRACE and FATAL for the custom levels,
+// e.g. "TRACE", "DEBUG+2" or "FATAL".
+func LevelName(l slog.Level) string {
+	switch {
+	case l < slog.LevelDebug:
+		return levelWithOffset("TRACE", l-LevelTrace)
+	case l >= LevelFatal:
+		return levelWithOffset("FATAL", l-LevelFatal)
+	default:
+		return l.String()
+	}
+}
+
+// levelWithOffset formats a level name with an optional offset.
+func levelWithOffset(name string, offset slog.Level) string {
+	if offset == 0 {
+		return name
+	}
+	return fmt.Sprintf("%s%+d", name, int(offset))
+}
+
+// replaceLevelName renders the custom levels by name in handler output.
+func replaceLevelName(groups []string, attr slog.Attr) slog.Attr {
+	if len(groups) == 0 && attr.Key == slog.LevelKey {
+		if l, ok := attr.Value.Any().(slog.Level); ok {
+			attr.Value = slog.StringValue(LevelName(l))
+		}
+	}
+	return attr
+}
+
+// levelReport is the body of the level handler responses.
+type levelReport struct {
+	Level string `json:"level"`
+	Rules string `json:"rules,omitempty"`
+}
+
+// levelRequest is the body of the level handler PUT and POST requests.
+// Absent fields leave the current settings unchanged, empty rules remove them.
+type levelRequest struct {
+	Level *string `json:"level"`
+	Rules *string `json:"rules"`
+}
+
+// LevelHandler returns an HTTP handler that reads and changes the shared log level
+// and the level rules:
+//   - GET returns the current settings, e.g. {"level":"INFO","rules":"service=ohlc-generator:DEBUG"},
+//   - PUT or POST changes them from a JSON body {"level":"debug","rules":"*:info"} or the
+//     level and rules query parameters and returns the new settings.
+//
+// The handler has no authentication, so it must only be mounted on an internal
+// listener such as the launcher admin server.
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+		case http.MethodPut, http.MethodPost:
+			var body levelRequest
+			query := r.URL.Query()
+			if query.Has("level") || query.Has("rules") {
+				if query.Has("level") {
+					body.Level = new(string)
+					*body.Level = query.Get("level")
+				}
+				if query.Has("rules") {
+					body.Rules = new(string)
+					*body.Rules = query.Get("rules")
+				}
+			} else if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+				return
+			}
+			if err := applyLevelRequest(body); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, HEAD, PUT, POST")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(levelReport{Level: LevelName(Level()), Rules: GetLevelRules().String()})
+	})
+}
+
+// applyLevelRequest validates both settings of the request before changing any of them.
+func applyLevelRequest(request levelRequest) error {
+	var (
+		l     slog.Level
+		rules LevelRules
+		err   error
+	)
+	if request.Level != nil {
+		if l, err = ParseLevel(*request.Level); err != nil {
+			return err
+		}
+	}
+	if request.Rules != nil {
+		if rules, err = ParseLevelRules(*request.Rules); err != nil {
+			return err
+		}
+	}
+	if request.Level == nil && request.Rules == nil {
+		return errors.New("level or rules must be provided")
+	}
+
+	if request.Level != nil {
+		SetLevel(l)
+	}
+	if request.Rules != nil {
+		SetLevelRules(rules)
+	}
+	return nil
+}
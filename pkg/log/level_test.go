@@ -0,0 +1,153 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// setLevel changes the shared level for the duration of the test
+func setLevel(t *testing.T, l slog.Level) {
+	t.Helper()
+
+	previous := Level()
+	SetLevel(l)
+	t.Cleanup(func() { SetLevel(previous) })
+}
+
+// TestParseLevel verifies level names, offsets and numeric levels
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected slog.Level
+	}{
+		{"trace", LevelTrace},
+		{"DEBUG", slog.LevelDebug},
+		{" info ", slog.LevelInfo},
+		{"warning", slog.LevelWarn},
+		{"Error", slog.LevelError},
+		{"fatal", LevelFatal},
+		{"debug+2", slog.LevelDebug + 2},
+		{"error-1", slog.LevelError - 1},
+		{"-4", slog.LevelDebug},
+	}
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.name)
+		if err != nil || got != tt.expected {
+			t.Errorf("ParseLevel(%q): expected %v, got %v, %v", tt.name, tt.expected, got, err)
+		}
+	}
+
+	for _, name := range []string{"", "verbose", "debug+x"} {
+		if _, err := ParseLevel(name); err == nil {
+			t.Errorf("ParseLevel(%q): expected an error", name)
+		}
+	}
+}
+
+// TestLevelName verifies that custom levels are named and round-trip through ParseLevel
+func TestLevelName(t *testing.T) {
+	for level, expected := range map[slog.Level]string{
+		LevelTrace:          "TRACE",
+		LevelTrace + 1:      "TRACE+1",
+		slog.LevelDebug:     "DEBUG",
+		slog.LevelError + 2: "ERROR+2",
+		LevelFatal:          "FATAL",
+		LevelFatal + 4:      "FATAL+4",
+	} {
+		name := LevelName(level)
+		if name != expected {
+			t.Errorf("LevelName(%v): expected %q, got %q", int(level), expected, name)
+		}
+		if parsed, err := ParseLevel(name); err != nil || parsed != level {
+			t.Errorf("ParseLevel(%q): expected %v, got %v, %v", name, int(level), int(parsed), err)
+		}
+	}
+}
+
+// TestSetLevel verifies that existing handlers follow the runtime level and render custom levels
+func TestSetLevel(t *testing.T) {
+	setLevel(t, slog.LevelInfo)
+
+	var buf bytes.Buffer
+	logger := NewLogger(NewJsonHandler(&buf))
+
+	logger.Trace("hidden trace")
+	logger.Debug("hidden debug")
+
+	SetLevel(LevelTrace)
+	logger.Trace("visible trace")
+	logger.Log(context.Background(), LevelFatal, "visible fatal")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 log lines, got %d: %s", len(lines), buf.String())
+	}
+	for i, expected := range []string{"TRACE", "FATAL"} {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(lines[i]), &entry); err != nil {
+			t.Fatalf("Failed to parse JSON output: %v", err)
+		}
+		if entry["level"] != expected {
+			t.Errorf("Expected level %s, got %v", expected, entry["level"])
+		}
+	}
+}
+
+// TestLevelConfig verifies applying the level from an application config
+func TestLevelConfig(t *testing.T) {
+	setLevel(t, slog.LevelInfo)
+
+	if err := (LevelConfig{}).Apply(); err != nil || Level() != slog.LevelInfo {
+		t.Errorf("Expected an empty config to keep the level, got %v, %v", Level(), err)
+	}
+	if err := (LevelConfig{Level: "debug"}).Apply(); err != nil || Level() != slog.LevelDebug {
+		t.Errorf("Expected debug level, got %v, %v", Level(), err)
+	}
+	if err := (LevelConfig{Level: "loud"}).Apply(); err == nil || Level() != slog.LevelDebug {
+		t.Errorf("Expected an error and an unchanged level, got %v, %v", Level(), err)
+	}
+}
+
+// TestLevelHandler verifies reading and changing the level over HTTP
+func TestLevelHandler(t *testing.T) {
+	setLevel(t, slog.LevelInfo)
+	handler := LevelHandler()
+
+	tests := []struct {
+		method   string
+		target   string
+		body     string
+		status   int
+		expected slog.Level
+	}{
+		{http.MethodGet, "/", "", http.StatusOK, slog.LevelInfo},
+		{http.MethodPut, "/", `{"level":"trace"}`, http.StatusOK, LevelTrace},
+		{http.MethodPost, "/?level=warn", "", http.StatusOK, slog.LevelWarn},
+		{http.MethodPut, "/", `{"level":"loud"}`, http.StatusBadRequest, slog.LevelWarn},
+		{http.MethodPut, "/", `not json`, http.StatusBadRequest, slog.LevelWarn},
+		{http.MethodDelete, "/", "", http.StatusMethodNotAllowed, slog.LevelWarn},
+	}
+	for _, tt := range tests {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body)))
+
+		if recorder.Code != tt.status {
+			t.Errorf("%s %s: expected status %d, got %d", tt.method, tt.target, tt.status, recorder.Code)
+		}
+		if Level() != tt.expected {
+			t.Errorf("%s %s: expected level %v, got %v", tt.method, tt.target, tt.expected, Level())
+		}
+		if tt.status == http.StatusOK {
+			expected := `{"level":"` + LevelName(tt.expected) + `"}`
+			if body := strings.TrimSpace(recorder.Body.String()); body != expected {
+				t.Errorf("%s %s: expected body %s, got %s", tt.method, tt.target, expected, body)
+			}
+		}
+	}
+}
@@ -6,6 +6,7 @@ package log
 import (
 	"context"
 	"errors"
+	"io"
 	"log/slog"
 )
 
@@ -107,6 +108,49 @@ func (l *Logger) WithError(err error) *Logger {
 	}
 }
 
+// Flush waits until the handlers that buffer records, such as AsyncHandler,
+// have written every record logged before the call, or until ctx is done.
+func (l *Logger) Flush(ctx context.Context) error {
+	var errs []error
+	for _, handler := range l.handlers() {
+		if f, ok := handler.(interface{ Flush(context.Context) error }); ok {
+			errs = append(errs, f.Flush(ctx))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close flushes and closes the handlers that hold resources, such as AsyncHandler.
+// It should be deferred in main so that buffered records are written before the process exits.
+//
+// Example:
+//
+//	logger := log.NewLogger(log.NewAsyncHandler(log.NewJsonStdOutHandler(), log.AsyncOptions{}))
+//	defer logger.Close()
+func (l *Logger) Close() error {
+	var errs []error
+	for _, handler := range l.handlers() {
+		if c, ok := handler.(io.Closer); ok {
+			errs = append(errs, c.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// handlers returns the handlers of the logger, unwrapping the multiHandler.
+func (l *Logger) handlers() []slog.Handler {
+	if m, ok := l.Handler().(*multiHandler); ok {
+		return m.handlers
+	}
+	return []slog.Handler{l.Handler()}
+}
+
+// Trace logs a message at the custom TRACE level, below DEBUG.
+// It is meant for very verbose records such as every received message.
+func (l *Logger) Trace(msg string, args ...any) {
+	l.Logger.Log(context.Background(), LevelTrace, msg, args...)
+}
+
 // formatMessage formats the error and additional messages into a log message and arguments.
 // It safely handles the case where the first message might not be a string and ensures
 // the error is properly included in the structured log output.
@@ -6,19 +6,55 @@ package log
 import (
 	"context"
 	"log/slog"
+	"maps"
+	"slices"
+	"sync/atomic"
 )
 
 // multiHandler implements slog.Handler interface to support multiple handlers simultaneously.
 // This allows logging to multiple destinations (e.g., console and file) with a single logger.
 // All handlers are called for each log record, providing comprehensive logging coverage.
+//
+// The multiHandler also applies the level rules (see SetLevelRules). It keeps the top-level
+// attributes of the derived logger, e.g. the service attribute added by StartService, and
+// caches the level of the matching rule until the rules change.
+//
+// The multiHandler also applies the redactor (see SetRedactor) to records and attributes,
+// so no handler ever sees the sensitive values, after adding the attributes of the context
+// extractors (see SetContextExtractors). Context attributes are always top-level: inside
+// a group the record goes to the handlers as they were before the first group, with the
+// groups and attributes added since nested in the record.
 type multiHandler struct {
 	handlers []slog.Handler
+	attrs    map[string]string             // Top-level attributes of the derived logger
+	grouped  bool                          // Attributes added from now on belong to a group
+	root     []slog.Handler                // Handlers before the first group, set once grouped
+	scope    []scopeStep                   // Groups and attributes added since the first group
+	decision atomic.Pointer[levelDecision] // Cached rule decision for the current rules
+}
+
+// scopeStep is a WithGroup or WithAttrs call made after the first group.
+type scopeStep struct {
+	group string      // Group name, empty for attributes
+	attrs []slog.Attr // Attributes, already redacted
+}
+
+// levelDecision is the outcome of matching the level rules against a logger.
+type levelDecision struct {
+	rules   *compiledRules // Rules version the decision was made for
+	level   slog.Level     // Level of the matching rule
+	matched bool           // Whether a rule matched, otherwise the shared level applies
 }
 
 // Enabled checks if logging is enabled for the given level in any of the handlers.
 // Returns true if at least one handler would process a record at the specified level.
 // This is used by slog to optimize performance by avoiding unnecessary work.
+// While level rules are active the record level must also reach the level of the most
+// specific matching rule, or the shared level if no rule matches.
 func (h *multiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if compiled := levelRules.Load(); compiled != nil && level < h.threshold(compiled) {
+		return false
+	}
 	for _, handler := range h.handlers {
 		if handler.Enabled(ctx, level) {
 			return true
@@ -27,13 +63,47 @@ func (h *multiHandler) Enabled(ctx context.Context, level slog.Level) bool {
 	return false
 }
 
+// threshold returns the minimum level of the logger under the rules, using the cached
+// decision when it was made for the same rules.
+func (h *multiHandler) threshold(compiled *compiledRules) slog.Level {
+	decision := h.decision.Load()
+	if decision == nil || decision.rules != compiled {
+		decision = &levelDecision{rules: compiled}
+		decision.level, decision.matched = compiled.match(h.attrs)
+		h.decision.Store(decision)
+	}
+	if decision.matched {
+		return decision.level
+	}
+	return Level()
+}
+
 // Handle processes a log record by forwarding it to all configured handlers.
 // If any handler returns an error, the first error encountered is returned.
 // This ensures that logging continues even if one handler fails, while still
 // reporting errors for debugging purposes.
 func (h *multiHandler) Handle(ctx context.Context, record slog.Record) error {
+	r := redactor.Load()
+	handlers := h.handlers
+	attrs := extractContext(ctx, record, h.attrs, h.grouped)
+	if r != nil {
+		if len(attrs) > 0 {
+			attrs, _ = r.attrs(attrs)
+		}
+		record = r.record(record)
+	}
+	if len(attrs) > 0 {
+		if h.grouped {
+			record = h.withContextAttrs(record, attrs)
+			handlers = h.root
+		} else {
+			record = record.Clone()
+			record.AddAttrs(attrs...)
+		}
+	}
+
 	var firstErr error
-	for _, handler := range h.handlers {
+	for _, handler := range handlers {
 		if err := handler.Handle(ctx, record); err != nil && firstErr == nil {
 			firstErr = err
 		}
@@ -41,18 +111,65 @@ func (h *multiHandler) Handle(ctx context.Context, record slog.Record) error {
 	return firstErr
 }
 
+// withContextAttrs returns a record for the root handlers that has the context attributes
+// at the top level, followed by the groups and attributes of the scope nesting the
+// attributes of the record. The handler chain is not rebuilt for every record.
+func (h *multiHandler) withContextAttrs(record slog.Record, attrs []slog.Attr) slog.Record {
+	nested := make([]slog.Attr, 0, record.NumAttrs())
+	record.Attrs(func(attr slog.Attr) bool {
+		nested = append(nested, attr)
+		return true
+	})
+	for i := len(h.scope) - 1; i >= 0; i-- {
+		if step := h.scope[i]; step.group != "" {
+			nested = []slog.Attr{{Key: step.group, Value: slog.GroupValue(nested...)}}
+		} else {
+			nested = append(slices.Clip(step.attrs), nested...)
+		}
+	}
+
+	scoped := slog.NewRecord(record.Time, record.Level, record.Message, record.PC)
+	scoped.AddAttrs(attrs...)
+	scoped.AddAttrs(nested...)
+	return scoped
+}
+
 // WithAttrs returns a new multiHandler where each underlying handler has the specified
 // attributes added. This maintains the multi-handler structure while propagating
 // the attribute addition to all wrapped handlers.
 //
 // This method is called when logger.With() is used to add structured attributes.
 func (h *multiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if r := redactor.Load(); r != nil {
+		attrs, _ = r.attrs(attrs)
+	}
+
 	handlers := make([]slog.Handler, len(h.handlers))
 	for i, handler := range h.handlers {
 		handlers[i] = handler.WithAttrs(attrs)
 	}
+
+	// Attributes inside a group are not matched by the level rules
+	topLevel := h.attrs
+	if !h.grouped && len(attrs) > 0 {
+		topLevel = maps.Clone(h.attrs)
+		if topLevel == nil {
+			topLevel = make(map[string]string, len(attrs))
+		}
+		for _, attr := range attrs {
+			topLevel[attr.Key] = attr.Value.Resolve().String()
+		}
+	}
+	scope := h.scope
+	if h.grouped && len(attrs) > 0 {
+		scope = append(slices.Clip(h.scope), scopeStep{attrs: attrs})
+	}
 	return &multiHandler{
 		handlers: handlers,
+		attrs:    topLevel,
+		grouped:  h.grouped,
+		root:     h.root,
+		scope:    scope,
 	}
 }
 
@@ -62,11 +179,22 @@ func (h *multiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
 //
 // This method is called when logger.WithGroup() is used to create hierarchical log structure.
 func (h *multiHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
 	handlers := make([]slog.Handler, len(h.handlers))
 	for i, handler := range h.handlers {
 		handlers[i] = handler.WithGroup(name)
 	}
+	root := h.root
+	if !h.grouped {
+		root = h.handlers
+	}
 	return &multiHandler{
 		handlers: handlers,
+		attrs:    h.attrs,
+		grouped:  true,
+		root:     root,
+		scope:    append(slices.Clip(h.scope), scopeStep{group: name}),
 	}
 }
@@ -0,0 +1,427 @@
+// Package log provides redaction of sensitive values before records reach the handlers.
+// This file contains the Redactor that masks, hashes or drops attributes by key name
+// and sensitive substrings such as emails and card numbers, including values nested
+// in groups and returned by slog.LogValuer implementations.
+package log
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"unicode"
+)
+
+// RedactedValue replaces masked values.
+const RedactedValue = "[REDACTED]"
+
+// RedactAction defines what happens to a sensitive value.
+type RedactAction int
+
+const (
+	// RedactMask replaces the value with RedactedValue. This is the default.
+	RedactMask RedactAction = iota
+	// RedactHash replaces the value with a short SHA-256 hash, so equal values can
+	// still be correlated across records without being revealed.
+	RedactHash
+	// RedactDrop removes the attribute from the record.
+	RedactDrop
+)
+
+// String returns the action name.
+func (a RedactAction) String() string {
+	switch a {
+	case RedactMask:
+		return "mask"
+	case RedactHash:
+		return "hash"
+	case RedactDrop:
+		return "drop"
+	default:
+		return fmt.Sprintf("RedactAction(%d)", int(a))
+	}
+}
+
+// ValueMatcher finds sensitive substrings in string values.
+// *regexp.Regexp implements it, so any regular expression can be used.
+type ValueMatcher interface {
+	FindAllStringIndex(s string, n int) [][]int
+}
+
+// EmailMatcher matches email addresses.
+var EmailMatcher ValueMatcher = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+// CardNumberMatcher matches payment card numbers of 13 to 19 digits, optionally
+// separated by spaces or dashes, that pass the Luhn check. About one in ten digit
+// sequences of that length passes the check, so numeric IDs such as order or trade
+// IDs logged as strings may be masked as well; leave it out of Values if that matters.
+var CardNumberMatcher ValueMatcher = cardNumberMatcher{}
+
+// cardNumberCandidate matches digit sequences that may be card numbers.
+var cardNumberCandidate = regexp.MustCompile(`\b\d(?:[ \-]?\d){12,18}\b`)
+
+// cardNumberMatcher filters card number candidates with the Luhn check.
+type cardNumberMatcher struct{}
+
+// FindAllStringIndex returns the positions of the card numbers in s.
+func (cardNumberMatcher) FindAllStringIndex(s string, n int) [][]int {
+	var matches [][]int
+	for _, loc := range cardNumberCandidate.FindAllStringIndex(s, n) {
+		if luhnValid(s[loc[0]:loc[1]]) {
+			matches = append(matches, loc)
+		}
+	}
+	return matches
+}
+
+// luhnValid reports whether the digits of s pass the Luhn checksum.
+func luhnValid(s string) bool {
+	sum, double := 0, false
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// RedactionOptions configures a Redactor.
+type RedactionOptions struct {
+	Keys        []string         // Case-insensitive words of sensitive attribute keys, e.g. "password" matches "db_password" and "dbPassword", "token" does not match "tokens_count"
+	KeyPatterns []*regexp.Regexp // Patterns of sensitive attribute keys
+	Values      []ValueMatcher   // Matchers of sensitive substrings in string and error values
+	Action      RedactAction     // What happens to sensitive values
+	HashKey     []byte           // Key of the HMAC used by RedactHash, plain SHA-256 if empty
+}
+
+// DefaultRedactionOptions returns options that mask the values of password, token,
+// secret and authorization keys, emails and card numbers (see CardNumberMatcher for
+// its false positives).
+func DefaultRedactionOptions() RedactionOptions {
+	return RedactionOptions{
+		Keys:   []string{"password", "token", "secret", "authorization"},
+		Values: []ValueMatcher{EmailMatcher, CardNumberMatcher},
+		Action: RedactMask,
+	}
+}
+
+// Redactor removes sensitive values from log attributes. It is safe for concurrent use.
+//
+// Keys are matched at every level of groups and slog.LogValuer results; string values
+// and error messages are searched for sensitive substrings. Other values, such as maps,
+// structs and slices logged with slog.Any, are passed through as they are, since their
+// content is only formatted by the handler. Implement slog.LogValuer on such types, or
+// log their fields as a group, to have them redacted.
+type Redactor struct {
+	keys        [][]string // Words of the sensitive keys, see keyWords
+	keyPatterns []*regexp.Regexp
+	values      []ValueMatcher
+	action      RedactAction
+	hashKey     []byte
+}
+
+// NewRedactor creates a redactor with the options.
+func NewRedactor(options RedactionOptions) *Redactor {
+	keys := make([][]string, 0, len(options.Keys))
+	for _, key := range options.Keys {
+		if words := keyWords(key); len(words) > 0 {
+			keys = append(keys, words)
+		}
+	}
+	return &Redactor{
+		keys:        keys,
+		keyPatterns: options.KeyPatterns,
+		values:      options.Values,
+		action:      options.Action,
+		hashKey:     options.HashKey,
+	}
+}
+
+// redactor is the redactor applied by all loggers created with NewLogger, nil if disabled.
+var redactor atomic.Pointer[Redactor]
+
+// SetRedactor replaces the redactor applied by all loggers created with NewLogger to
+// record attributes, messages and attributes added later with With or WithFields.
+// Attributes added before the call keep their previous redaction. Nil disables redaction.
+// Redaction is disabled until SetRedactor is called, so output is unchanged by default.
+//
+// Example:
+//
+//	options := log.DefaultRedactionOptions()
+//	options.Keys = append(options.Keys, "api_key")
+//	options.Action = log.RedactHash
+//	log.SetRedactor(log.NewRedactor(options))
+func SetRedactor(r *Redactor) {
+	redactor.Store(r)
+}
+
+// GetRedactor returns the redactor applied by the loggers, nil if redaction is disabled.
+func GetRedactor() *Redactor {
+	return redactor.Load()
+}
+
+// Attr returns the attribute with sensitive values redacted. LogValuer values are
+// resolved and groups are redacted recursively. It reports false if the attribute
+// must be dropped.
+func (r *Redactor) Attr(attr slog.Attr) (slog.Attr, bool) {
+	attr, keep, _ := r.attr(attr)
+	return attr, keep
+}
+
+// attr redacts the attribute and also reports whether it was changed.
+func (r *Redactor) attr(attr slog.Attr) (slog.Attr, bool, bool) {
+	resolved := attr.Value.Resolve()
+	changed := resolved.Kind() != attr.Value.Kind() || resolved.Kind() == slog.KindLogValuer
+	attr.Value = resolved
+
+	if r.sensitiveKey(attr.Key) {
+		if r.action == RedactDrop {
+			return attr, false, true
+		}
+		attr.Value = slog.StringValue(r.replacement(attr.Value.String()))
+		return attr, true, true
+	}
+
+	switch attr.Value.Kind() {
+	case slog.KindGroup:
+		attrs, groupChanged := r.attrs(attr.Value.Group())
+		if groupChanged {
+			attr.Value = slog.GroupValue(attrs...)
+			changed = true
+		}
+	case slog.KindString:
+		if redacted, ok := r.redactString(attr.Value.String()); ok {
+			if r.action == RedactDrop {
+				return attr, false, true
+			}
+			attr.Value = slog.StringValue(redacted)
+			changed = true
+		}
+	case slog.KindAny:
+		if err, ok := attr.Value.Any().(error); ok {
+			if redacted, ok := r.redactString(err.Error()); ok {
+				if r.action == RedactDrop {
+					return attr, false, true
+				}
+				attr.Value = slog.StringValue(redacted)
+				changed = true
+			}
+		}
+	}
+	return attr, true, changed
+}
+
+// attrs redacts the attributes and reports whether any of them was changed or dropped.
+func (r *Redactor) attrs(attrs []slog.Attr) ([]slog.Attr, bool) {
+	var result []slog.Attr
+	for i, attr := range attrs {
+		redacted, keep, changed := r.attr(attr)
+		if changed && result == nil {
+			result = append(make([]slog.Attr, 0, len(attrs)), attrs[:i]...)
+		}
+		if result != nil && keep {
+			result = append(result, redacted)
+		}
+	}
+	if result == nil {
+		return attrs, false
+	}
+	return result, true
+}
+
+// Message returns the message with sensitive substrings redacted.
+// Dropping does not apply to messages, they are masked or hashed instead.
+func (r *Redactor) Message(msg string) string {
+	if redacted, ok := r.redactString(msg); ok {
+		return redacted
+	}
+	return msg
+}
+
+// sensitiveKey reports whether values of the key must be redacted.
+func (r *Redactor) sensitiveKey(key string) bool {
+	if len(r.keys) > 0 {
+		words := keyWords(key)
+		for _, k := range r.keys {
+			if containsWords(words, k) {
+				return true
+			}
+		}
+	}
+	for _, pattern := range r.keyPatterns {
+		if pattern.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// keyWords splits an attribute key into lower case words at separators and
+// camel case boundaries, e.g. "JWT_Secret" and "jwtSecret" into "jwt" and "secret".
+func keyWords(key string) []string {
+	var words []string
+	runes := []rune(key)
+	start := -1
+	for i, c := range runes {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) {
+			if start >= 0 {
+				words = append(words, strings.ToLower(string(runes[start:i])))
+				start = -1
+			}
+			continue
+		}
+		// An upper case letter starts a word after a lower case one, or ends an acronym
+		// when a lower case letter follows, as in "JWTSecret"
+		if start >= 0 && unicode.IsUpper(c) && (unicode.IsLower(runes[i-1]) ||
+			(unicode.IsUpper(runes[i-1]) && i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+			words = append(words, strings.ToLower(string(runes[start:i])))
+			start = i
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		words = append(words, strings.ToLower(string(runes[start:])))
+	}
+	return words
+}
+
+// containsWords reports whether sub occurs in words as a contiguous sequence.
+func containsWords(words, sub []string) bool {
+	for i := 0; i+len(sub) <= len(words); i++ {
+		if slices.Equal(words[i:i+len(sub)], sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactString replaces the sensitive substrings of s and reports whether there were any.
+func (r *Redactor) redactString(s string) (string, bool) {
+	found := false
+	for _, matcher := range r.values {
+		matches := matcher.FindAllStringIndex(s, -1)
+		if len(matches) == 0 {
+			continue
+		}
+		found = true
+		var b strings.Builder
+		last := 0
+		for _, loc := range matches {
+			b.WriteString(s[last:loc[0]])
+			b.WriteString(r.replacement(s[loc[0]:loc[1]]))
+			last = loc[1]
+		}
+		b.WriteString(s[last:])
+		s = b.String()
+	}
+	return s, found
+}
+
+// replacement returns the masked or hashed form of a sensitive value.
+func (r *Redactor) replacement(value string) string {
+	if r.action != RedactHash {
+		return RedactedValue
+	}
+	var h hash.Hash
+	if len(r.hashKey) > 0 {
+		h = hmac.New(sha256.New, r.hashKey)
+	} else {
+		h = sha256.New()
+	}
+	h.Write([]byte(value))
+	return "sha256:" + hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// record returns the record with a redacted message and attributes, or the record
+// itself if nothing had to be redacted.
+func (r *Redactor) record(record slog.Record) slog.Record {
+	msg := r.Message(record.Message)
+
+	// The attributes are copied only from the first one that changes, as in attrs
+	var attrs []slog.Attr
+	index := 0
+	record.Attrs(func(attr slog.Attr) bool {
+		redacted, keep, changed := r.attr(attr)
+		if changed && attrs == nil {
+			attrs = make([]slog.Attr, 0, record.NumAttrs())
+			record.Attrs(func(previous slog.Attr) bool {
+				if len(attrs) == index {
+					return false
+				}
+				attrs = append(attrs, previous)
+				return true
+			})
+		}
+		if attrs != nil && keep {
+			attrs = append(attrs, redacted)
+		}
+		index++
+		return true
+	})
+	if attrs == nil && msg == record.Message {
+		return record
+	}
+
+	redacted := slog.NewRecord(record.Time, record.Level, msg, record.PC)
+	if attrs == nil {
+		record.Attrs(func(attr slog.Attr) bool {
+			redacted.AddAttrs(attr)
+			return true
+		})
+	} else {
+		redacted.AddAttrs(attrs...)
+	}
+	return redacted
+}
+
+// redactingHandler applies a redactor before the wrapped handler sees a record.
+type redactingHandler struct {
+	handler  slog.Handler
+	redactor *Redactor
+}
+
+// NewRedactingHandler wraps a handler that is used without NewLogger, e.g. with
+// slog.New, so that the redactor is applied to its records and attributes.
+// Loggers created with NewLogger are already redacted, see SetRedactor.
+func NewRedactingHandler(handler slog.Handler, r *Redactor) slog.Handler {
+	return &redactingHandler{handler: handler, redactor: r}
+}
+
+// Enabled reports whether the wrapped handler handles records at the level.
+func (h *redactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+// Handle redacts the record and passes it to the wrapped handler.
+func (h *redactingHandler) Handle(ctx context.Context, record slog.Record) error {
+	return h.handler.Handle(ctx, h.redactor.record(record))
+}
+
+// WithAttrs redacts the attributes and adds them to the wrapped handler.
+func (h *redactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	attrs, _ = h.redactor.attrs(attrs)
+	return &redactingHandler{handler: h.handler.WithAttrs(attrs), redactor: h.redactor}
+}
+
+// WithGroup opens the group in the wrapped handler.
+func (h *redactingHandler) WithGroup(name string) slog.Handler {
+	return &redactingHandler{handler: h.handler.WithGroup(name), redactor: h.redactor}
+}
@@ -0,0 +1,251 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setRedactor changes the redactor of the loggers for the duration of the test
+func setRedactor(t *testing.T, r *Redactor) {
+	t.Helper()
+
+	previous := GetRedactor()
+	SetRedactor(r)
+	t.Cleanup(func() { SetRedactor(previous) })
+}
+
+// credentials is a LogValuer that exposes a secret in its resolved value
+type credentials struct {
+	user     string
+	password string
+}
+
+func (c credentials) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("user", c.user), slog.String("password", c.password))
+}
+
+// TestRedactWithFields verifies that fields added with WithFields are redacted with the default options
+func TestRedactWithFields(t *testing.T) {
+	setRedactor(t, NewRedactor(DefaultRedactionOptions()))
+
+	var buf bytes.Buffer
+	logger := NewLogger(NewJsonHandler(&buf)).WithFields(map[string]any{
+		"JWT_SECRET":  "eyJhbGciOiJIUzI1NiJ9",
+		"user_email":  "alice@example.com",
+		"user_id":     12345,
+		"card_number": "4111 1111 1111 1111",
+	})
+	logger.Info("user alice@example.com logged in", "Authorization", "Bearer abc", "order_id", "1234567890123")
+
+	output := buf.String()
+	for _, leaked := range []string{"eyJhbGciOiJIUzI1NiJ9", "alice@example.com", "4111 1111 1111 1111", "Bearer abc"} {
+		if strings.Contains(output, leaked) {
+			t.Errorf("Expected %q to be redacted, got: %s", leaked, output)
+		}
+	}
+	for _, expected := range []string{`"JWT_SECRET":"[REDACTED]"`, `"msg":"user [REDACTED] logged in"`, `"user_id":12345`, `"order_id":"1234567890123"`} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected %s in output, got: %s", expected, output)
+		}
+	}
+}
+
+// TestRedactNested verifies redaction of values in groups, LogValuer results and errors
+func TestRedactNested(t *testing.T) {
+	setRedactor(t, NewRedactor(DefaultRedactionOptions()))
+
+	var buf bytes.Buffer
+	logger := NewLogger(NewJsonHandler(&buf))
+	logger.Info("login",
+		slog.Group("request", slog.Group("headers", slog.String("authorization", "Bearer abc"), slog.String("accept", "*/*"))),
+		slog.Any("credentials", credentials{user: "bob", password: "hunter2"}),
+		slog.Any("error", errors.New("no account for bob@example.com")),
+	)
+
+	output := buf.String()
+	for _, leaked := range []string{"Bearer abc", "hunter2", "bob@example.com"} {
+		if strings.Contains(output, leaked) {
+			t.Errorf("Expected %q to be redacted, got: %s", leaked, output)
+		}
+	}
+	for _, expected := range []string{`"accept":"*/*"`, `"user":"bob"`, `"error":"no account for [REDACTED]"`} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected %s in output, got: %s", expected, output)
+		}
+	}
+}
+
+// TestRedactActions verifies the hash and drop actions and custom matchers
+func TestRedactActions(t *testing.T) {
+	options := RedactionOptions{
+		KeyPatterns: []*regexp.Regexp{regexp.MustCompile(`(?i)^api[_-]?key$`)},
+		Values:      []ValueMatcher{EmailMatcher},
+		Action:      RedactHash,
+	}
+	r := NewRedactor(options)
+
+	first, keep := r.Attr(slog.String("api_key", "k-1"))
+	second, _ := r.Attr(slog.String("ApiKey", "k-1"))
+	if !keep || !strings.HasPrefix(first.Value.String(), "sha256:") || first.Value.String() != second.Value.String() {
+		t.Errorf("Expected equal hashes, got %v and %v", first, second)
+	}
+	if attr, _ := r.Attr(slog.String("contact", "mail bob@example.com")); attr.Value.String() == "mail bob@example.com" ||
+		!strings.HasPrefix(attr.Value.String(), "mail sha256:") {
+		t.Errorf("Expected hashed email, got %v", attr)
+	}
+	if attr, _ := r.Attr(slog.String("token", "t-1")); attr.Value.String() != "t-1" {
+		t.Errorf("Expected keys outside the options to be kept, got %v", attr)
+	}
+
+	options.HashKey = []byte("pepper")
+	if keyed, _ := NewRedactor(options).Attr(slog.String("api_key", "k-1")); keyed.Value.String() == first.Value.String() {
+		t.Errorf("Expected the hash key to change the hash, got %v", keyed)
+	}
+
+	options.Action = RedactDrop
+	setRedactor(t, NewRedactor(options))
+	var buf bytes.Buffer
+	logger := NewLogger(NewJsonHandler(&buf))
+	logger.With("api_key", "k-1").Info("contact bob@example.com", "email", "bob@example.com", "name", "bob")
+	output := buf.String()
+	if strings.Contains(output, "api_key") || strings.Contains(output, `"email"`) || !strings.Contains(output, `"name":"bob"`) {
+		t.Errorf("Expected sensitive attributes to be dropped, got: %s", output)
+	}
+	if !strings.Contains(output, `"msg":"contact [REDACTED]"`) {
+		t.Errorf("Expected the message to be masked, got: %s", output)
+	}
+}
+
+// TestCardNumberMatcher verifies that only digit sequences passing the Luhn check match
+func TestCardNumberMatcher(t *testing.T) {
+	tests := map[string]bool{
+		"4111111111111111":         true,
+		"card 5500-0000-0000-0004": true,
+		"4111111111111112":         false,
+		"1700000000123":            false,
+		"order 12345":              false,
+	}
+	for input, expected := range tests {
+		if matched := len(CardNumberMatcher.FindAllStringIndex(input, -1)) > 0; matched != expected {
+			t.Errorf("%q: expected match %v, got %v", input, expected, matched)
+		}
+	}
+}
+
+// TestRedactKeyWords verifies that keys match whole words of attribute keys
+func TestRedactKeyWords(t *testing.T) {
+	r := NewRedactor(RedactionOptions{Keys: []string{"password", "token", "api_key"}})
+	tests := map[string]bool{
+		"password":      true,
+		"db_password":   true,
+		"dbPassword":    true,
+		"DB-PASSWORD":   true,
+		"access_token":  true,
+		"JWTToken":      true,
+		"apiKey":        true,
+		"x.api.key":     true,
+		"tokens_count":  false,
+		"passwordless":  false,
+		"api":           false,
+		"key":           false,
+		"tokenizer_ms":  false,
+		"monkey_api_id": false,
+	}
+	for key, expected := range tests {
+		if sensitive := r.sensitiveKey(key); sensitive != expected {
+			t.Errorf("%q: expected sensitive %v, got %v", key, expected, sensitive)
+		}
+	}
+}
+
+// TestRedactDisabled verifies that redaction is disabled by default and a nil redactor leaves records unchanged
+func TestRedactDisabled(t *testing.T) {
+	if GetRedactor() != nil {
+		t.Fatal("Expected redaction to be disabled until SetRedactor is called")
+	}
+	setRedactor(t, nil)
+
+	var buf bytes.Buffer
+	NewLogger(NewJsonHandler(&buf)).Info("login", "password", "hunter2")
+	if !strings.Contains(buf.String(), `"password":"hunter2"`) {
+		t.Errorf("Expected no redaction, got: %s", buf.String())
+	}
+}
+
+// TestRedactingHandler verifies the wrapper for handlers used without NewLogger
+func TestRedactingHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewRedactingHandler(NewJsonHandler(&buf), NewRedactor(DefaultRedactionOptions())))
+	logger.With("db_password", "hunter2").WithGroup("user").Info("created", "email", "bob@example.com")
+
+	output := buf.String()
+	if strings.Contains(output, "hunter2") || !strings.Contains(output, `"user":{"email":"[REDACTED]"}`) {
+		t.Errorf("Expected redacted output, got: %s", output)
+	}
+}
+
+// BenchmarkRedact_Clean measures the overhead of redaction for records without sensitive values
+func BenchmarkRedact_Clean(b *testing.B) {
+	var buf bytes.Buffer
+	logger := NewLogger(NewJsonHandler(&buf))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		logger.Info("order processed", "order_id", i, "status", "filled", "symbol", "BTCUSDT")
+	}
+}
+
+// TestRedactUnchangedRecord verifies that a record without sensitive data is returned without copying
+func TestRedactUnchangedRecord(t *testing.T) {
+	r := NewRedactor(DefaultRedactionOptions())
+	record := slog.NewRecord(time.Now(), slog.LevelInfo, "order created", 0)
+	record.AddAttrs(slog.String("user", "bob"), slog.Int("order_id", 42), slog.Group("request", slog.String("method", "GET")))
+
+	attrs := []slog.Attr{slog.String("user", "bob"), slog.Int("order_id", 42), slog.Group("request", slog.String("method", "GET"))}
+	matching := testing.AllocsPerRun(100, func() {
+		r.Message(record.Message)
+		r.attrs(attrs)
+	})
+	allocs := testing.AllocsPerRun(100, func() {
+		r.record(record)
+	})
+	if allocs > matching {
+		t.Errorf("Expected no allocations beyond matching for an unchanged record, got %v instead of %v", allocs, matching)
+	}
+
+	record.AddAttrs(slog.String("password", "hunter2"), slog.Int("attempt", 1))
+	var keys []string
+	r.record(record).Attrs(func(attr slog.Attr) bool {
+		keys = append(keys, attr.Key+"="+attr.Value.String())
+		return true
+	})
+	expected := "user=bob order_id=42 request=[method=GET] password=[REDACTED] attempt=1"
+	if got := strings.Join(keys, " "); got != expected {
+		t.Errorf("Expected attributes %q, got %q", expected, got)
+	}
+}
+
+// TestRedactOpaqueValues documents that maps, structs and slices logged with slog.Any are not inspected
+func TestRedactOpaqueValues(t *testing.T) {
+	setRedactor(t, NewRedactor(DefaultRedactionOptions()))
+
+	var buf bytes.Buffer
+	logger := NewLogger(NewJsonHandler(&buf))
+	logger.Info("login",
+		"form", map[string]any{"password": "hunter2"},
+		"login", credentials{user: "bob", password: "s3cret"},
+	)
+
+	output := buf.String()
+	if !strings.Contains(output, `"form":{"password":"hunter2"}`) {
+		t.Errorf("Expected the map to be passed through unchanged, got: %s", output)
+	}
+	if strings.Contains(output, "s3cret") {
+		t.Errorf("Expected the LogValuer to be redacted, got: %s", output)
+	}
+}
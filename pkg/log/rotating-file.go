@@ -0,0 +1,456 @@
+// Package log provides a rotating log file for the handler constructors.
+// This file contains the RotatingFile writer that rotates by size or time, compresses
+// and prunes rotated files, and reopens the file on SIGHUP for external logrotate.
+package log
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// rotationTimeFormat is the timestamp of rotated file names, e.g. app-20261017T010203.000000.log.
+const rotationTimeFormat = "20060102T150405.000000"
+
+// RotationOptions configures a RotatingFile. Zero values disable the corresponding feature.
+type RotationOptions struct {
+	MaxSize    int64         // Rotate before a write would grow the file beyond MaxSize bytes
+	Interval   time.Duration // Rotate at every multiple of Interval in UTC, e.g. 24h rotates at midnight UTC
+	Compress   bool          // Compress rotated files with gzip in the background
+	MaxBackups int           // Keep at most MaxBackups rotated files
+	MaxAge     time.Duration // Remove rotated files older than MaxAge
+	FileMode   os.FileMode   // Permissions of new files, zero means 0644
+}
+
+// RotatingFile is an io.Writer that writes to a file and rotates it by size or time.
+// A rotated file is renamed to <name>-<timestamp><ext>, optionally compressed to .gz,
+// and rotated files are pruned by count and age. It is safe for concurrent use, so a
+// single RotatingFile can be shared by several handlers and loggers; the same path
+// must not be opened twice.
+//
+// Example:
+//
+//	file, err := log.OpenRotatingFile("/var/log/app/app.log", log.RotationOptions{
+//		MaxSize:    100 << 20, // 100 MiB
+//		Compress:   true,
+//		MaxBackups: 10,
+//		MaxAge:     7 * 24 * time.Hour,
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	defer file.Close()
+//	defer file.ReopenOnSignal()() // external logrotate sends SIGHUP
+//
+//	logger := log.NewLogger(log.NewJsonHandler(file))
+type RotatingFile struct {
+	path     string
+	options  RotationOptions
+	now      func() time.Time                                                // Clock, replaced in tests
+	openFile func(name string, flag int, perm os.FileMode) (*os.File, error) // os.OpenFile, replaced in tests
+	mu       sync.Mutex
+	file     *os.File
+	size     int64     // Bytes written to the current file
+	rotateAt time.Time // Next time rotation, zero if Interval is not set
+	closed   bool
+	maintMu  sync.Mutex     // Serializes compression and pruning
+	maintWG  sync.WaitGroup // Background compression and pruning in progress
+}
+
+// OpenRotatingFile opens or creates the file at path for appending, creating the
+// directory if needed.
+func OpenRotatingFile(path string, options RotationOptions) (*RotatingFile, error) {
+	if options.FileMode == 0 {
+		options.FileMode = 0o644
+	}
+	f := &RotatingFile{path: path, options: options, now: time.Now, openFile: os.OpenFile}
+	if _, err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Write writes p to the current file, rotating it first if the write would exceed
+// MaxSize or the rotation time has passed.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+	if f.shouldRotate(int64(len(p))) {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Rotate rotates the file now, regardless of its size and age.
+func (f *RotatingFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return os.ErrClosed
+	}
+	return f.rotate()
+}
+
+// Reopen opens the path again and closes the previous file. It is meant for external
+// rotation tools such as logrotate that rename the file and then signal the process.
+// If the path cannot be opened, writes continue to go to the previous file.
+func (f *RotatingFile) Reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return os.ErrClosed
+	}
+	previous, err := f.open()
+	if err != nil {
+		return err
+	}
+	return previous.Close()
+}
+
+// ReopenOnSignal reopens the file whenever one of the signals is received, SIGHUP if
+// none are given, and returns a function that stops listening. Reopen errors are
+// written to stderr since the log file itself is not usable.
+// The launcher also handles SIGHUP (as a reload), both receive the signal.
+func (f *RotatingFile) ReopenOnSignal(signals ...os.Signal) (stop func()) {
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGHUP}
+	}
+	received := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(received, signals...)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			case <-received:
+				if err := f.Reopen(); err != nil && !errors.Is(err, os.ErrClosed) {
+					fmt.Fprintf(os.Stderr, "log: failed to reopen %s: %v\n", f.path, err)
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(received)
+			close(done)
+			wg.Wait()
+		})
+	}
+}
+
+// Close closes the file and waits for background compression and pruning.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	if f.closed {
+		f.mu.Unlock()
+		return nil
+	}
+	f.closed = true
+	err := f.file.Close()
+	f.mu.Unlock()
+
+	f.maintWG.Wait()
+	return err
+}
+
+// open opens the path for appending and returns the file it replaces, which the caller
+// closes. On failure the current file is kept. The caller must hold f.mu, except in
+// OpenRotatingFile.
+func (f *RotatingFile) open() (previous *os.File, err error) {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+		return nil, err
+	}
+	file, err := f.openFile(f.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, f.options.FileMode)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	previous = f.file
+	f.file = file
+	f.size = info.Size()
+	if f.options.Interval > 0 {
+		f.rotateAt = f.now().Truncate(f.options.Interval).Add(f.options.Interval)
+	}
+	return previous, nil
+}
+
+// shouldRotate reports whether the file must be rotated before writing n bytes.
+func (f *RotatingFile) shouldRotate(n int64) bool {
+	if f.options.MaxSize > 0 && f.size > 0 && f.size+n > f.options.MaxSize {
+		return true
+	}
+	if f.rotateAt.IsZero() || f.now().Before(f.rotateAt) {
+		return false
+	}
+	if f.size == 0 {
+		// Nothing was written during the interval, keep the empty file
+		f.rotateAt = f.now().Truncate(f.options.Interval).Add(f.options.Interval)
+		return false
+	}
+	return true
+}
+
+// rotate renames the current file, opens a new one and starts the background
+// compression and pruning. The current file stays open until the new one is, so
+// a failed rotation keeps writing to it rather than losing records. The caller
+// must hold f.mu.
+func (f *RotatingFile) rotate() error {
+	backup := f.backupName(f.now())
+	renameErr := os.Rename(f.path, backup)
+	if renameErr != nil && !errors.Is(renameErr, os.ErrNotExist) {
+		return renameErr
+	}
+	previous, err := f.open()
+	if err != nil {
+		if renameErr == nil {
+			// Move the current file back, so the next rotation starts over
+			err = errors.Join(err, os.Rename(backup, f.path))
+		}
+		return err
+	}
+	closeErr := previous.Close()
+	if renameErr != nil {
+		// The file was removed externally, there is nothing to compress
+		return closeErr
+	}
+
+	f.maintWG.Add(1)
+	go func() {
+		defer f.maintWG.Done()
+		f.maintain(backup)
+	}()
+	return closeErr
+}
+
+// backupName returns an unused name for the file rotated at t.
+func (f *RotatingFile) backupName(t time.Time) string {
+	dir, prefix, ext := f.nameParts()
+	name := filepath.Join(dir, prefix+t.UTC().Format(rotationTimeFormat)+ext)
+	for i := 1; ; i++ {
+		if _, err := os.Stat(name); errors.Is(err, os.ErrNotExist) {
+			if _, err := os.Stat(name + ".gz"); errors.Is(err, os.ErrNotExist) {
+				return name
+			}
+		}
+		name = filepath.Join(dir, fmt.Sprintf("%s%s.%d%s", prefix, t.UTC().Format(rotationTimeFormat), i, ext))
+	}
+}
+
+// nameParts splits the path into the directory, the rotated file name prefix and the extension.
+func (f *RotatingFile) nameParts() (dir, prefix, ext string) {
+	dir = filepath.Dir(f.path)
+	base := filepath.Base(f.path)
+	ext = filepath.Ext(base)
+	return dir, strings.TrimSuffix(base, ext) + "-", ext
+}
+
+// maintain compresses the rotated file and prunes old rotated files.
+// Failures are written to stderr since the log file itself is not a safe place for them.
+func (f *RotatingFile) maintain(backup string) {
+	f.maintMu.Lock()
+	defer f.maintMu.Unlock()
+
+	// The file may already be pruned by an earlier maintenance run
+	if f.options.Compress {
+		if err := compressFile(backup); err != nil && !errors.Is(err, os.ErrNotExist) {
+			fmt.Fprintf(os.Stderr, "log: failed to compress %s: %v\n", backup, err)
+		}
+	}
+	if err := f.prune(); err != nil {
+		fmt.Fprintf(os.Stderr, "log: failed to prune rotated files of %s: %v\n", f.path, err)
+	}
+}
+
+// rotatedFile is a rotated file found by prune.
+type rotatedFile struct {
+	path      string
+	rotatedAt time.Time
+	seq       int // Suffix of files rotated at the same time, see backupName
+}
+
+// prune removes the rotated files beyond MaxBackups and older than MaxAge.
+func (f *RotatingFile) prune() error {
+	if f.options.MaxBackups <= 0 && f.options.MaxAge <= 0 {
+		return nil
+	}
+
+	files, err := f.rotatedFiles()
+	if err != nil {
+		return err
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if !files[i].rotatedAt.Equal(files[j].rotatedAt) {
+			return files[i].rotatedAt.After(files[j].rotatedAt)
+		}
+		return files[i].seq > files[j].seq
+	})
+
+	cutoff := f.now().Add(-f.options.MaxAge)
+	var errs []error
+	for i, file := range files {
+		tooMany := f.options.MaxBackups > 0 && i >= f.options.MaxBackups
+		tooOld := f.options.MaxAge > 0 && file.rotatedAt.Before(cutoff)
+		if tooMany || tooOld {
+			if err := os.Remove(file.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// rotatedFiles lists the rotated files of the path with the time encoded in their names.
+func (f *RotatingFile) rotatedFiles() ([]rotatedFile, error) {
+	dir, prefix, ext := f.nameParts()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []rotatedFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || strings.HasSuffix(name, ".tmp") {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimSuffix(name, ".gz"), ext)
+		stamp = strings.TrimPrefix(stamp, prefix)
+		if len(stamp) < len(rotationTimeFormat) {
+			continue
+		}
+		rotatedAt, err := time.Parse(rotationTimeFormat, stamp[:len(rotationTimeFormat)])
+		if err != nil {
+			continue
+		}
+		file := rotatedFile{path: filepath.Join(dir, name), rotatedAt: rotatedAt}
+		if suffix := stamp[len(rotationTimeFormat):]; suffix != "" {
+			if _, err := fmt.Sscanf(suffix, ".%d", &file.seq); err != nil {
+				continue
+			}
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// compressFile compresses path to path.gz and removes path.
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	tmp := path + ".gz.tmp"
+	dst, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode())
+	if err != nil {
+		return err
+	}
+
+	zw := gzip.NewWriter(dst)
+	_, err = io.Copy(zw, src)
+	err = errors.Join(err, zw.Close(), dst.Close())
+	if err == nil {
+		err = os.Rename(tmp, path+".gz")
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Remove(path)
+}
+
+// FileHandler is a handler that writes to a RotatingFile and closes it on Close.
+// Logger.Close closes the file of every FileHandler of the logger.
+type FileHandler struct {
+	slog.Handler
+	File *RotatingFile // The file the handler writes to, e.g. for ReopenOnSignal
+}
+
+// WithAttrs returns a handler writing to the same file with the attributes added.
+func (h *FileHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &FileHandler{Handler: h.Handler.WithAttrs(attrs), File: h.File}
+}
+
+// WithGroup returns a handler writing to the same file with the group opened.
+func (h *FileHandler) WithGroup(name string) slog.Handler {
+	return &FileHandler{Handler: h.Handler.WithGroup(name), File: h.File}
+}
+
+// Close closes the file.
+func (h *FileHandler) Close() error {
+	return h.File.Close()
+}
+
+// NewTextFileHandler creates a text-formatted handler that writes to a rotating file.
+//
+// Example:
+//
+//	handler, err := log.NewTextFileHandler("logs/app.log", log.RotationOptions{Interval: 24 * time.Hour, MaxAge: 30 * 24 * time.Hour})
+//	if err != nil {
+//		return err
+//	}
+//	logger := log.NewLogger(log.NewTextStdOutHandler(), handler)
+//	defer logger.Close()
+func NewTextFileHandler(path string, options RotationOptions) (*FileHandler, error) {
+	file, err := OpenRotatingFile(path, options)
+	if err != nil {
+		return nil, err
+	}
+	return &FileHandler{Handler: NewTextHandler(file), File: file}, nil
+}
+
+// NewJsonFileHandler creates a JSON-formatted handler that writes to a rotating file.
+//
+// Example:
+//
+//	handler, err := log.NewJsonFileHandler("logs/app.json", log.RotationOptions{MaxSize: 50 << 20, Compress: true, MaxBackups: 5})
+//	if err != nil {
+//		return err
+//	}
+//	defer handler.File.ReopenOnSignal()()
+//	logger := log.NewLogger(handler)
+//	defer logger.Close()
+func NewJsonFileHandler(path string, options RotationOptions) (*FileHandler, error) {
+	file, err := OpenRotatingFile(path, options)
+	if err != nil {
+		return nil, err
+	}
+	return &FileHandler{Handler: NewJsonHandler(file), File: file}, nil
+}
@@ -0,0 +1,302 @@
+package log
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// readLogFiles returns the names of the files in dir and their contents, decompressing .gz files
+func readLogFiles(t *testing.T, dir string) ([]string, map[string]string) {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to read dir: %v", err)
+	}
+	var names []string
+	contents := make(map[string]string)
+	for _, entry := range entries {
+		file, err := os.Open(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatalf("Failed to open %s: %v", entry.Name(), err)
+		}
+		var reader io.Reader = file
+		if strings.HasSuffix(entry.Name(), ".gz") {
+			if reader, err = gzip.NewReader(file); err != nil {
+				t.Fatalf("Failed to decompress %s: %v", entry.Name(), err)
+			}
+		}
+		data, err := io.ReadAll(reader)
+		file.Close()
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", entry.Name(), err)
+		}
+		names = append(names, entry.Name())
+		contents[entry.Name()] = string(data)
+	}
+	sort.Strings(names)
+	return names, contents
+}
+
+// steppingClock returns a clock that advances by a second on every call
+func steppingClock() func() time.Time {
+	var mu sync.Mutex
+	clock := time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC)
+	return func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		clock = clock.Add(time.Second)
+		return clock
+	}
+}
+
+// TestRotatingFileSize verifies rotation before a write exceeds the maximum size
+func TestRotatingFileSize(t *testing.T) {
+	dir := t.TempDir()
+	file, err := OpenRotatingFile(filepath.Join(dir, "app.log"), RotationOptions{MaxSize: 100})
+	if err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	file.now = steppingClock()
+
+	for i := 0; i < 3; i++ {
+		fmt.Fprintf(file, "%059d\n", i)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("Failed to close: %v", err)
+	}
+
+	names, contents := readLogFiles(t, dir)
+	if len(names) != 3 || names[2] != "app.log" || !strings.HasPrefix(names[0], "app-") {
+		t.Fatalf("Expected 2 rotated files and app.log, got %v", names)
+	}
+	if !strings.HasSuffix(contents[names[0]], "0\n") || !strings.HasSuffix(contents["app.log"], "2\n") {
+		t.Errorf("Expected the oldest record in the first rotated file, got %v", contents)
+	}
+}
+
+// TestRotatingFileCompressAndMaxBackups verifies compression and pruning by count
+func TestRotatingFileCompressAndMaxBackups(t *testing.T) {
+	dir := t.TempDir()
+	file, err := OpenRotatingFile(filepath.Join(dir, "app.log"), RotationOptions{Compress: true, MaxBackups: 2})
+	if err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	file.now = steppingClock()
+
+	for i := 0; i < 5; i++ {
+		fmt.Fprintf(file, "record %d\n", i)
+		if err := file.Rotate(); err != nil {
+			t.Fatalf("Failed to rotate: %v", err)
+		}
+	}
+	file.Close()
+
+	names, contents := readLogFiles(t, dir)
+	if len(names) != 3 || !strings.HasSuffix(names[0], ".log.gz") || !strings.HasSuffix(names[1], ".log.gz") {
+		t.Fatalf("Expected 2 compressed rotated files and app.log, got %v", names)
+	}
+	if contents[names[0]] != "record 3\n" || contents[names[1]] != "record 4\n" || contents["app.log"] != "" {
+		t.Errorf("Expected the newest records to be kept, got %v", contents)
+	}
+}
+
+// TestRotatingFileInterval verifies time-based rotation and pruning by age
+func TestRotatingFileInterval(t *testing.T) {
+	dir := t.TempDir()
+	clock := time.Date(2026, 10, 17, 10, 30, 0, 0, time.UTC)
+
+	// A rotated file from the previous week and an unrelated file
+	os.WriteFile(filepath.Join(dir, "app-20261010T000000.000000.log.gz"), nil, 0o644)
+	os.WriteFile(filepath.Join(dir, "app-errors.log"), nil, 0o644)
+
+	file, err := OpenRotatingFile(filepath.Join(dir, "app.log"), RotationOptions{Interval: time.Hour, MaxAge: 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	file.now = func() time.Time { return clock }
+	file.rotateAt = clock.Truncate(time.Hour).Add(time.Hour)
+
+	fmt.Fprintln(file, "at 10:30")
+	clock = clock.Add(20 * time.Minute)
+	fmt.Fprintln(file, "at 10:50")
+	clock = clock.Add(20 * time.Minute)
+	fmt.Fprintln(file, "at 11:10")
+	file.Close()
+
+	names, contents := readLogFiles(t, dir)
+	expected := []string{"app-20261017T111000.000000.log", "app-errors.log", "app.log"}
+	if strings.Join(names, ",") != strings.Join(expected, ",") {
+		t.Fatalf("Expected %v, got %v", expected, names)
+	}
+	if contents[expected[0]] != "at 10:30\nat 10:50\n" || contents["app.log"] != "at 11:10\n" {
+		t.Errorf("Unexpected contents: %v", contents)
+	}
+}
+
+// TestRotatingFileReopen verifies reopening after an external rotation, also on SIGHUP
+func TestRotatingFileReopen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	file, err := OpenRotatingFile(path, RotationOptions{})
+	if err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	defer file.Close()
+
+	fmt.Fprintln(file, "before logrotate")
+	os.Rename(path, path+".1")
+	fmt.Fprintln(file, "still in the renamed file")
+	if err := file.Reopen(); err != nil {
+		t.Fatalf("Failed to reopen: %v", err)
+	}
+	fmt.Fprintln(file, "after reopen")
+
+	stop := file.ReopenOnSignal()
+	defer stop()
+	os.Rename(path, path+".2")
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatalf("Failed to send SIGHUP: %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, err := os.Stat(path); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the file to be reopened on SIGHUP")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	fmt.Fprintln(file, "after SIGHUP")
+
+	_, contents := readLogFiles(t, dir)
+	if contents["app.log.1"] != "before logrotate\nstill in the renamed file\n" ||
+		contents["app.log.2"] != "after reopen\n" || contents["app.log"] != "after SIGHUP\n" {
+		t.Errorf("Unexpected contents: %v", contents)
+	}
+}
+
+// TestRotatingFileOpenFailure verifies that a failed reopen or rotation keeps writing to
+// the current file and that the next attempt succeeds
+func TestRotatingFileOpenFailure(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	file, err := OpenRotatingFile(path, RotationOptions{})
+	if err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	defer file.Close()
+	file.now = steppingClock()
+
+	failOnce := func() {
+		file.openFile = func(name string, flag int, perm os.FileMode) (*os.File, error) {
+			file.openFile = os.OpenFile
+			return nil, syscall.EMFILE
+		}
+	}
+
+	fmt.Fprintln(file, "first")
+	failOnce()
+	if err := file.Reopen(); err == nil {
+		t.Fatal("Expected the reopen to fail")
+	}
+	if _, err := fmt.Fprintln(file, "after failed reopen"); err != nil {
+		t.Fatalf("Expected writes to continue after a failed reopen, got: %v", err)
+	}
+	if err := file.Reopen(); err != nil {
+		t.Fatalf("Failed to reopen: %v", err)
+	}
+
+	failOnce()
+	if err := file.Rotate(); err == nil {
+		t.Fatal("Expected the rotation to fail")
+	}
+	if _, err := fmt.Fprintln(file, "after failed rotation"); err != nil {
+		t.Fatalf("Expected writes to continue after a failed rotation, got: %v", err)
+	}
+	if err := file.Rotate(); err != nil {
+		t.Fatalf("Failed to rotate: %v", err)
+	}
+	fmt.Fprintln(file, "after rotation")
+
+	names, contents := readLogFiles(t, dir)
+	if len(names) != 2 || contents[names[0]] != "first\nafter failed reopen\nafter failed rotation\n" ||
+		contents["app.log"] != "after rotation\n" {
+		t.Errorf("Unexpected files %v: %v", names, contents)
+	}
+}
+
+// TestRotatingFileConcurrent verifies that concurrent loggers sharing a file lose no records
+func TestRotatingFileConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	handler, err := NewJsonFileHandler(filepath.Join(dir, "app.json"), RotationOptions{MaxSize: 4096, Compress: true})
+	if err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	logger := NewLogger(handler)
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			workerLogger := logger.With("worker", worker)
+			for i := 0; i < 100; i++ {
+				workerLogger.Info("record", "i", i)
+			}
+		}(worker)
+	}
+	wg.Wait()
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Failed to close: %v", err)
+	}
+
+	names, contents := readLogFiles(t, dir)
+	lines := 0
+	for _, name := range names {
+		if name != "app.json" && !strings.HasSuffix(name, ".json.gz") {
+			t.Errorf("Unexpected file %s", name)
+		}
+		lines += strings.Count(contents[name], `"msg":"record"`)
+	}
+	if lines != 800 || len(names) < 3 {
+		t.Errorf("Expected 800 records in several files, got %d in %v", lines, names)
+	}
+}
+
+// TestRotatingFileSameTimestamp verifies unique names and pruning order for rotations at the same time
+func TestRotatingFileSameTimestamp(t *testing.T) {
+	dir := t.TempDir()
+	file, err := OpenRotatingFile(filepath.Join(dir, "app.log"), RotationOptions{MaxBackups: 2})
+	if err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	clock := time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC)
+	file.now = func() time.Time { return clock }
+
+	for i := 0; i < 4; i++ {
+		fmt.Fprintf(file, "record %d\n", i)
+		file.Rotate()
+	}
+	file.Close()
+
+	names, contents := readLogFiles(t, dir)
+	expected := []string{"app-20261017T100000.000000.2.log", "app-20261017T100000.000000.3.log", "app.log"}
+	if strings.Join(names, ",") != strings.Join(expected, ",") {
+		t.Fatalf("Expected %v, got %v", expected, names)
+	}
+	if contents[expected[1]] != "record 3\n" {
+		t.Errorf("Expected the last rotation to be kept, got %v", contents)
+	}
+}
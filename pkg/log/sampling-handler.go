@@ -0,0 +1,375 @@
+// Package log provides a sampling handler wrapper for slog.
+// This file contains the SamplingHandler type that limits high-volume records by
+// sampling them per level and message, or by collapsing identical consecutive
+// records into one with a repeat count.
+package log
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Attribute keys added by SamplingHandler.
+const (
+	SampledCountKey = "sampled_count" // Number of records a sampled record stands for
+	RepeatCountKey  = "repeat_count"  // Number of repeats collapsed into a deduplicated record
+)
+
+const (
+	// DefaultSamplingInterval is the default sampling and deduplication interval.
+	DefaultSamplingInterval = time.Second
+	// DefaultSamplingFirst is the default number of records logged per key and interval.
+	DefaultSamplingFirst = 100
+	// DefaultSamplingThereafter is the default sampling rate after the first records.
+	DefaultSamplingThereafter = 100
+)
+
+// SamplingMode defines how SamplingHandler limits records.
+type SamplingMode int
+
+const (
+	// SampleByMessage logs the first records of each level and message per interval
+	// and then one in every Thereafter records. This is the default.
+	SampleByMessage SamplingMode = iota
+	// DedupConsecutive collapses identical consecutive records into the first one and
+	// a copy with the number of repeats, logged when a different record arrives or
+	// at the latest after the interval.
+	DedupConsecutive
+)
+
+// String returns the mode name.
+func (m SamplingMode) String() string {
+	switch m {
+	case SampleByMessage:
+		return "sample"
+	case DedupConsecutive:
+		return "dedup"
+	default:
+		return fmt.Sprintf("SamplingMode(%d)", int(m))
+	}
+}
+
+// SamplingOptions configures a SamplingHandler.
+type SamplingOptions struct {
+	Mode       SamplingMode  // How records are limited
+	Interval   time.Duration // Sampling interval or longest delay of a repeat count, zero means DefaultSamplingInterval
+	First      int           // Records logged per level and message and interval, zero means DefaultSamplingFirst
+	Thereafter int           // One in Thereafter records is logged after the first ones, zero means DefaultSamplingThereafter, negative drops them all
+}
+
+// samplingKey identifies the records sampled together.
+type samplingKey struct {
+	level   slog.Level
+	message string
+}
+
+// samplingCounter counts the records of a key.
+type samplingCounter struct {
+	count   int // Records in the current interval
+	skipped int // Records dropped since the last logged one
+}
+
+// dedupEntry is the last logged record in the DedupConsecutive mode.
+type dedupEntry struct {
+	handler   *SamplingHandler // Handler that logged the record and logs its repeat count
+	signature string           // Attributes of the handler and the record, see recordSignature
+	ctx       context.Context
+	record    slog.Record
+	repeats   int // Repeats dropped since the record or the last repeat count was logged
+}
+
+// samplingCore is the state shared by a SamplingHandler and all handlers derived from it
+// with WithAttrs and WithGroup.
+type samplingCore struct {
+	options  SamplingOptions
+	now      func() time.Time
+	mu       sync.Mutex
+	window   time.Time // Start of the current sampling interval
+	counters map[samplingKey]*samplingCounter
+	last     *dedupEntry
+	timer    *time.Timer // Logs the pending repeat count after the interval
+	closed   bool
+}
+
+// SamplingHandler wraps a handler to limit high-volume records such as a warning logged
+// for every received message. Records at ERROR and above always pass through.
+//
+// In the SampleByMessage mode the first records of each level and message are logged
+// in every interval, then one in every Thereafter records. A logged record that stands
+// for dropped ones carries the sampled_count attribute with their number plus one.
+//
+// In the DedupConsecutive mode a record identical to the previous one (same level,
+// message and attributes, including those added with With and WithGroup) is dropped,
+// and the number of drops is logged as a copy of the record with the repeat_count
+// attribute and the time the count is logged. A record at ERROR and above logs the
+// pending repeat count first and ends the run of repeats. Attribute values implementing
+// slog.LogValuer are resolved once, before the comparison, and passed on resolved.
+//
+// Flush and Close log the pending repeat count and are passed on to the wrapped handler.
+//
+// Example:
+//
+//	handler := log.NewSamplingHandler(log.NewJsonStdOutHandler(), log.SamplingOptions{
+//		First:      10,
+//		Thereafter: 1000,
+//	})
+//	logger := log.NewLogger(handler)
+type SamplingHandler struct {
+	core    *samplingCore
+	handler slog.Handler
+	scope   string // Attributes and groups added with WithAttrs and WithGroup, part of the dedup signature
+}
+
+// NewSamplingHandler wraps handler with the sampling options.
+func NewSamplingHandler(handler slog.Handler, options SamplingOptions) *SamplingHandler {
+	if options.Interval <= 0 {
+		options.Interval = DefaultSamplingInterval
+	}
+	if options.First <= 0 {
+		options.First = DefaultSamplingFirst
+	}
+	if options.Thereafter == 0 {
+		options.Thereafter = DefaultSamplingThereafter
+	}
+
+	core := &samplingCore{
+		options:  options,
+		now:      time.Now,
+		counters: make(map[samplingKey]*samplingCounter),
+	}
+	return &SamplingHandler{core: core, handler: handler}
+}
+
+// Enabled reports whether the wrapped handler handles records at the level.
+func (h *SamplingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+// Handle passes the record to the wrapped handler unless it is sampled out or a repeat.
+func (h *SamplingHandler) Handle(ctx context.Context, record slog.Record) error {
+	if h.core.options.Mode == DedupConsecutive {
+		return h.dedup(ctx, record)
+	}
+	if record.Level >= slog.LevelError {
+		return h.handler.Handle(ctx, record)
+	}
+	return h.sample(ctx, record)
+}
+
+// WithAttrs returns a handler that shares the sampling state and adds the attributes.
+func (h *SamplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var b strings.Builder
+	b.WriteString(h.scope)
+	resolved := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		resolved[i] = resolveAttr(attr)
+		writeAttr(&b, resolved[i])
+	}
+	attrs = resolved
+	return &SamplingHandler{core: h.core, handler: h.handler.WithAttrs(attrs), scope: b.String()}
+}
+
+// WithGroup returns a handler that shares the sampling state and opens the group.
+func (h *SamplingHandler) WithGroup(name string) slog.Handler {
+	return &SamplingHandler{core: h.core, handler: h.handler.WithGroup(name), scope: h.scope + "\x01" + name}
+}
+
+// Flush logs the pending repeat count and flushes the wrapped handler if it buffers records.
+func (h *SamplingHandler) Flush(ctx context.Context) error {
+	c := h.core
+	c.mu.Lock()
+	pending := c.takePending()
+	c.mu.Unlock()
+
+	err := pending.log()
+	if f, ok := h.handler.(interface{ Flush(context.Context) error }); ok {
+		err = errors.Join(err, f.Flush(ctx))
+	}
+	return err
+}
+
+// Close logs the pending repeat count and closes the wrapped handler if it holds resources.
+// Records handled after Close are no longer deduplicated.
+func (h *SamplingHandler) Close() error {
+	c := h.core
+	c.mu.Lock()
+	c.closed = true
+	pending := c.takePending()
+	c.last = nil
+	c.mu.Unlock()
+
+	err := pending.log()
+	if closer, ok := h.handler.(io.Closer); ok {
+		err = errors.Join(err, closer.Close())
+	}
+	return err
+}
+
+// sample logs the first records of the level and message in the interval and then one
+// in every Thereafter records.
+func (h *SamplingHandler) sample(ctx context.Context, record slog.Record) error {
+	c := h.core
+	c.mu.Lock()
+	if now := c.now(); now.Sub(c.window) >= c.options.Interval {
+		c.window = now
+		for key, counter := range c.counters {
+			// Keys not seen for a whole interval are forgotten to bound the map
+			if counter.count == 0 {
+				delete(c.counters, key)
+			}
+			counter.count = 0
+		}
+	}
+
+	key := samplingKey{level: record.Level, message: record.Message}
+	counter := c.counters[key]
+	if counter == nil {
+		counter = &samplingCounter{}
+		c.counters[key] = counter
+	}
+	counter.count++
+	if sampled := counter.count - c.options.First; sampled > 0 &&
+		(c.options.Thereafter < 0 || sampled%c.options.Thereafter != 0) {
+		counter.skipped++
+		c.mu.Unlock()
+		return nil
+	}
+	skipped := counter.skipped
+	counter.skipped = 0
+	c.mu.Unlock()
+
+	if skipped > 0 {
+		record = record.Clone()
+		record.AddAttrs(slog.Int(SampledCountKey, skipped+1))
+	}
+	return h.handler.Handle(ctx, record)
+}
+
+// dedup drops the record if it is identical to the previous one, otherwise it logs the
+// repeat count of the previous record and the record. Records at ERROR and above are
+// never dropped and are not compared with the next record.
+func (h *SamplingHandler) dedup(ctx context.Context, record slog.Record) error {
+	c := h.core
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return h.handler.Handle(ctx, record)
+	}
+	if record.Level >= slog.LevelError {
+		pending := c.takePending()
+		c.last = nil
+		c.mu.Unlock()
+		return errors.Join(pending.log(), h.handler.Handle(ctx, record))
+	}
+
+	record = resolveRecord(record)
+	signature := h.scope + "\x02" + recordSignature(record)
+	if last := c.last; last != nil && last.signature == signature {
+		last.repeats++
+		if c.timer == nil {
+			c.timer = time.AfterFunc(c.options.Interval, c.flushRepeats)
+		}
+		c.mu.Unlock()
+		return nil
+	}
+	pending := c.takePending()
+	c.last = &dedupEntry{
+		handler:   h,
+		signature: signature,
+		ctx:       context.WithoutCancel(ctx),
+		record:    record.Clone(),
+	}
+	c.mu.Unlock()
+
+	return errors.Join(pending.log(), h.handler.Handle(ctx, record))
+}
+
+// flushRepeats logs the pending repeat count when the interval has passed.
+func (c *samplingCore) flushRepeats() {
+	c.mu.Lock()
+	c.timer = nil
+	pending := c.takePending()
+	c.mu.Unlock()
+
+	// Write errors cannot be returned to a logging call, the repeat count is lost
+	_ = pending.log()
+}
+
+// takePending returns the repeat count record of the last entry, if there were repeats,
+// and resets its count. The record has the current time, so that it is not logged with
+// a time older than the records before it. It must be called with the mutex held.
+func (c *samplingCore) takePending() *dedupEntry {
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+	last := c.last
+	if last == nil || last.repeats == 0 {
+		return nil
+	}
+
+	pending := &dedupEntry{handler: last.handler, ctx: last.ctx, record: last.record.Clone()}
+	pending.record.Time = c.now()
+	pending.record.AddAttrs(slog.Int(RepeatCountKey, last.repeats))
+	last.repeats = 0
+	return pending
+}
+
+// log writes the repeat count record, if any, to the wrapped handler.
+func (e *dedupEntry) log() error {
+	if e == nil {
+		return nil
+	}
+	return e.handler.handler.Handle(e.ctx, e.record)
+}
+
+// resolveRecord returns a copy of the record with resolved attribute values.
+func resolveRecord(record slog.Record) slog.Record {
+	resolved := slog.NewRecord(record.Time, record.Level, record.Message, record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		resolved.AddAttrs(resolveAttr(attr))
+		return true
+	})
+	return resolved
+}
+
+// resolveAttr resolves the value of the attribute and of the attributes of its groups.
+func resolveAttr(attr slog.Attr) slog.Attr {
+	attr.Value = attr.Value.Resolve()
+	if attr.Value.Kind() == slog.KindGroup {
+		group := attr.Value.Group()
+		resolved := make([]slog.Attr, len(group))
+		for i, a := range group {
+			resolved[i] = resolveAttr(a)
+		}
+		attr.Value = slog.GroupValue(resolved...)
+	}
+	return attr
+}
+
+// recordSignature identifies the level, message and resolved attributes of a record.
+func recordSignature(record slog.Record) string {
+	var b strings.Builder
+	b.WriteString(record.Level.String())
+	b.WriteByte(0)
+	b.WriteString(record.Message)
+	record.Attrs(func(attr slog.Attr) bool {
+		writeAttr(&b, attr)
+		return true
+	})
+	return b.String()
+}
+
+// writeAttr writes the key and the resolved value of an attribute to a signature.
+func writeAttr(b *strings.Builder, attr slog.Attr) {
+	b.WriteByte(0)
+	b.WriteString(attr.Key)
+	b.WriteByte('=')
+	b.WriteString(attr.Value.String())
+}
@@ -0,0 +1,332 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a buffer safe for the concurrent writes of the repeat count timer
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// records returns the decoded records written so far
+func (b *syncBuffer) records(t *testing.T) []map[string]any {
+	t.Helper()
+
+	b.mu.Lock()
+	output := b.buf.String()
+	b.mu.Unlock()
+
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if line != "" {
+			records = append(records, decodeRecord(t, line))
+		}
+	}
+	return records
+}
+
+// TestSamplingHandlerSample verifies the first records, the sampling rate and sampled_count
+func TestSamplingHandlerSample(t *testing.T) {
+	var buf syncBuffer
+	handler := NewSamplingHandler(NewJsonHandler(&buf), SamplingOptions{First: 3, Thereafter: 5})
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	handler.core.now = func() time.Time { return now }
+	logger := NewLogger(handler)
+
+	for i := 0; i < 20; i++ {
+		logger.Warn("tick dropped", "i", i)
+		logger.Error("tick failed", "i", i)
+	}
+	logger.Info("other message")
+
+	var warnings, errs, others []map[string]any
+	for _, record := range buf.records(t) {
+		switch record["msg"] {
+		case "tick dropped":
+			warnings = append(warnings, record)
+		case "tick failed":
+			errs = append(errs, record)
+		default:
+			others = append(others, record)
+		}
+	}
+	if len(errs) != 20 || len(others) != 1 {
+		t.Fatalf("Expected all errors and other messages, got %d and %d", len(errs), len(others))
+	}
+
+	// Records 1-3 are logged, then records 8, 13 and 18, each standing for five records
+	expected := []float64{0, 1, 2, 7, 12, 17}
+	if len(warnings) != len(expected) {
+		t.Fatalf("Expected %d warnings, got %d: %v", len(expected), len(warnings), warnings)
+	}
+	for i, record := range warnings {
+		if record["i"] != expected[i] {
+			t.Errorf("Warning %d: expected i=%v, got %v", i, expected[i], record["i"])
+		}
+		if count, ok := record[SampledCountKey]; (i < 3 && ok) || (i >= 3 && count != float64(5)) {
+			t.Errorf("Warning %d: unexpected sampled_count %v", i, count)
+		}
+	}
+
+	// The next interval logs the first records again, carrying the dropped ones
+	now = now.Add(time.Second)
+	logger.Warn("tick dropped", "i", 20)
+	records := buf.records(t)
+	if last := records[len(records)-1]; last["i"] != float64(20) || last[SampledCountKey] != float64(3) {
+		t.Errorf("Expected the first record of the interval with sampled_count 3, got %v", last)
+	}
+}
+
+// TestSamplingHandlerDropThereafter verifies that a negative rate drops all records after the first ones
+func TestSamplingHandlerDropThereafter(t *testing.T) {
+	var buf syncBuffer
+	logger := NewLogger(NewSamplingHandler(NewJsonHandler(&buf), SamplingOptions{First: 2, Thereafter: -1, Interval: time.Hour}))
+	for i := 0; i < 10; i++ {
+		logger.Warn("flood")
+	}
+	if records := buf.records(t); len(records) != 2 {
+		t.Errorf("Expected 2 records, got %d", len(records))
+	}
+}
+
+// TestSamplingHandlerDedup verifies that identical consecutive records are collapsed with a repeat count
+func TestSamplingHandlerDedup(t *testing.T) {
+	var buf syncBuffer
+	handler := NewSamplingHandler(NewJsonHandler(&buf), SamplingOptions{Mode: DedupConsecutive, Interval: time.Hour})
+	logger := NewLogger(handler)
+	consumer := logger.With("service", "tick-consumer")
+
+	for i := 0; i < 5; i++ {
+		consumer.Warn("stale tick", "symbol", "BTCUSDT")
+	}
+	consumer.Error("stale tick", "symbol", "BTCUSDT")
+	logger.Warn("stale tick", "symbol", "BTCUSDT")
+	logger.Warn("stale tick", "symbol", "ETHUSDT")
+	logger.Warn("stale tick", "symbol", "ETHUSDT")
+	if err := logger.Flush(context.Background()); err != nil {
+		t.Fatalf("Unexpected flush error: %v", err)
+	}
+
+	type summary struct {
+		level, symbol, service string
+		repeats                any
+	}
+	var got []summary
+	for _, record := range buf.records(t) {
+		service, _ := record["service"].(string)
+		got = append(got, summary{record["level"].(string), record["symbol"].(string), service, record[RepeatCountKey]})
+	}
+	expected := []summary{
+		{"WARN", "BTCUSDT", "tick-consumer", nil},
+		{"WARN", "BTCUSDT", "tick-consumer", float64(4)},
+		{"ERROR", "BTCUSDT", "tick-consumer", nil},
+		{"WARN", "BTCUSDT", "", nil},
+		{"WARN", "ETHUSDT", "", nil},
+		{"WARN", "ETHUSDT", "", float64(1)},
+	}
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("Record %d: expected %v, got %v", i, expected[i], got[i])
+		}
+	}
+}
+
+// TestSamplingHandlerDedupDerived verifies that records of loggers derived with the same
+// attributes are collapsed and that an error ends a run of repeats
+func TestSamplingHandlerDedupDerived(t *testing.T) {
+	var buf syncBuffer
+	logger := NewLogger(NewSamplingHandler(NewJsonHandler(&buf), SamplingOptions{Mode: DedupConsecutive, Interval: time.Hour}))
+
+	for i := 0; i < 3; i++ {
+		logger.With("symbol", "BTCUSDT").WithGroup("tick").Warn("stale tick", "age", "5s")
+	}
+	logger.With("symbol", "ETHUSDT").WithGroup("tick").Warn("stale tick", "age", "5s")
+	logger.Warn("reconnecting")
+	logger.Error("connection lost")
+	logger.Warn("reconnecting")
+	if err := logger.Flush(context.Background()); err != nil {
+		t.Fatalf("Unexpected flush error: %v", err)
+	}
+
+	var got []string
+	for _, record := range buf.records(t) {
+		summary := record["msg"].(string)
+		if symbol, ok := record["symbol"]; ok {
+			summary += " " + symbol.(string)
+		}
+		// The repeat count belongs to the group of the record
+		attrs := record
+		if tick, ok := record["tick"].(map[string]any); ok {
+			attrs = tick
+		}
+		if repeats, ok := attrs[RepeatCountKey]; ok {
+			summary += fmt.Sprintf(" x%v", repeats)
+		}
+		got = append(got, summary)
+	}
+	expected := "stale tick BTCUSDT,stale tick BTCUSDT x2,stale tick ETHUSDT,reconnecting,connection lost,reconnecting"
+	if strings.Join(got, ",") != expected {
+		t.Errorf("Expected %s, got %s", expected, strings.Join(got, ","))
+	}
+}
+
+// TestSamplingHandlerDedupGroupedErrors verifies that errors inside a run of repeats of a
+// grouped logger log the pending count first and start a new run
+func TestSamplingHandlerDedupGroupedErrors(t *testing.T) {
+	var buf syncBuffer
+	logger := NewLogger(NewSamplingHandler(NewJsonHandler(&buf), SamplingOptions{Mode: DedupConsecutive, Interval: time.Hour}))
+	grouped := logger.With("service", "tick-consumer").WithGroup("tick")
+
+	for i := 0; i < 3; i++ {
+		grouped.Warn("stale tick", "symbol", "BTCUSDT")
+	}
+	grouped.Error("stale tick", "symbol", "BTCUSDT")
+	grouped.Error("stale tick", "symbol", "BTCUSDT")
+	for i := 0; i < 2; i++ {
+		grouped.Warn("stale tick", "symbol", "BTCUSDT")
+	}
+	if err := logger.Flush(context.Background()); err != nil {
+		t.Fatalf("Unexpected flush error: %v", err)
+	}
+
+	var got []string
+	for _, record := range buf.records(t) {
+		tick, ok := record["tick"].(map[string]any)
+		if !ok || tick["symbol"] != "BTCUSDT" || record["service"] != "tick-consumer" {
+			t.Fatalf("Expected the grouped attributes, got %v", record)
+		}
+		summary := record["level"].(string)
+		if repeats, ok := tick[RepeatCountKey]; ok {
+			summary += fmt.Sprintf(" x%v", repeats)
+		}
+		got = append(got, summary)
+	}
+	expected := "WARN,WARN x2,ERROR,ERROR,WARN,WARN x1"
+	if strings.Join(got, ",") != expected {
+		t.Errorf("Expected %s, got %s", expected, strings.Join(got, ","))
+	}
+}
+
+// countingValuer counts how many times it is resolved
+type countingValuer struct {
+	calls *atomic.Int32
+}
+
+func (v countingValuer) LogValue() slog.Value {
+	v.calls.Add(1)
+	return slog.StringValue("BTCUSDT")
+}
+
+// TestSamplingHandlerDedupResolve verifies that attribute values are resolved once per record
+// and that the repeat count is logged with the time it is logged at
+func TestSamplingHandlerDedupResolve(t *testing.T) {
+	var buf syncBuffer
+	handler := NewSamplingHandler(NewJsonHandler(&buf), SamplingOptions{Mode: DedupConsecutive, Interval: time.Hour})
+	flushedAt := time.Now().Add(time.Minute).Truncate(time.Millisecond)
+	handler.core.now = func() time.Time { return flushedAt }
+	logger := NewLogger(handler)
+
+	var calls atomic.Int32
+	for i := 0; i < 3; i++ {
+		logger.Warn("stale tick", "symbol", countingValuer{calls: &calls}, slog.Group("tick", "source", countingValuer{calls: &calls}))
+	}
+	if err := logger.Flush(context.Background()); err != nil {
+		t.Fatalf("Unexpected flush error: %v", err)
+	}
+
+	if got := calls.Load(); got != 6 {
+		t.Errorf("Expected 6 resolutions, got %d", got)
+	}
+	records := buf.records(t)
+	if len(records) != 2 || records[0]["symbol"] != "BTCUSDT" || records[1][RepeatCountKey] != float64(2) {
+		t.Fatalf("Expected the record and its repeat count, got %v", records)
+	}
+	if got, err := time.Parse(time.RFC3339Nano, records[1]["time"].(string)); err != nil || !got.Equal(flushedAt) {
+		t.Errorf("Expected the repeat count at %v, got %v", flushedAt, records[1]["time"])
+	}
+}
+
+// TestSamplingHandlerDedupInterval verifies that the repeat count is logged after the interval
+func TestSamplingHandlerDedupInterval(t *testing.T) {
+	var buf syncBuffer
+	logger := NewLogger(NewSamplingHandler(NewJsonHandler(&buf), SamplingOptions{Mode: DedupConsecutive, Interval: 20 * time.Millisecond}))
+
+	for i := 0; i < 3; i++ {
+		logger.Warn("stale tick")
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for len(buf.records(t)) < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	records := buf.records(t)
+	if len(records) != 2 || records[1][RepeatCountKey] != float64(2) {
+		t.Fatalf("Expected the repeat count after the interval, got %v", records)
+	}
+
+	// Repeats after the count are collapsed again
+	logger.Warn("stale tick")
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Unexpected close error: %v", err)
+	}
+	records = buf.records(t)
+	if len(records) != 3 || records[2][RepeatCountKey] != float64(1) {
+		t.Errorf("Expected a second repeat count on close, got %v", records)
+	}
+
+	logger.Warn("stale tick")
+	if records := buf.records(t); len(records) != 4 {
+		t.Errorf("Expected records after close to pass through, got %d", len(records))
+	}
+}
+
+// TestSamplingHandlerFlushWrapped verifies that Flush and Close reach the wrapped handler
+func TestSamplingHandlerFlushWrapped(t *testing.T) {
+	gated := newGatedHandler()
+	async := NewAsyncHandler(gated, AsyncOptions{DropReportInterval: -1})
+	logger := NewLogger(NewSamplingHandler(async, SamplingOptions{}))
+
+	logger.Warn("buffered")
+	close(gated.release)
+	if err := logger.Flush(context.Background()); err != nil {
+		t.Fatalf("Unexpected flush error: %v", err)
+	}
+	if !strings.Contains(gated.written(), "buffered") {
+		t.Errorf("Expected the buffered record to be written, got %q", gated.written())
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Unexpected close error: %v", err)
+	}
+	logger.Log(context.Background(), slog.LevelWarn, "after close")
+	if !strings.Contains(gated.written(), "after close") {
+		t.Errorf("Expected records after close to be written synchronously, got %q", gated.written())
+	}
+}
+
+// BenchmarkSamplingHandler_Sampled measures the cost of a sampled out record
+func BenchmarkSamplingHandler_Sampled(b *testing.B) {
+	var buf bytes.Buffer
+	logger := NewLogger(NewSamplingHandler(NewJsonHandler(&buf), SamplingOptions{First: 1, Thereafter: -1, Interval: time.Hour}))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Warn("tick dropped", "i", i)
+	}
+}
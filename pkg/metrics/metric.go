@@ -0,0 +1,159 @@
+// Package metrics provides counters, gauges and histograms.
+// This file contains the metric types and the labeled series they record into.
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// series is a single labeled time series of a family.
+type series struct {
+	labelValues []string
+	value       float64  // Counter and gauge value
+	bucketHits  []uint64 // Histogram observations per bucket, not cumulative
+	sum         float64  // Histogram sum of observations
+	count       uint64   // Histogram number of observations
+}
+
+// funcSeries is a labeled time series whose value is read on every scrape.
+type funcSeries struct {
+	labelValues []string
+	fn          func() float64
+}
+
+// family is a named metric with all its series.
+type family struct {
+	name       string
+	help       string
+	typ        metricType
+	labelNames []string
+	buckets    []float64 // Histogram upper bounds, sorted
+
+	mu     sync.Mutex
+	series map[string]*series
+	funcs  map[string]*funcSeries
+}
+
+// seriesKey joins label values into a map key.
+func seriesKey(labelValues []string) string {
+	return strings.Join(labelValues, "\xff")
+}
+
+// with returns the series for the label values, creating it on first use.
+// The caller must hold f.mu.
+func (f *family) with(labelValues []string) *series {
+	if len(labelValues) != len(f.labelNames) {
+		panic(fmt.Sprintf("metrics: metric %q expects %d label values, got %d", f.name, len(f.labelNames), len(labelValues)))
+	}
+
+	key := seriesKey(labelValues)
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{labelValues: append([]string(nil), labelValues...)}
+		if f.typ == typeHistogram {
+			s.bucketHits = make([]uint64, len(f.buckets))
+		}
+		f.series[key] = s
+	}
+	return s
+}
+
+// snapshot returns a copy of all series, including callback series, ordered by label values.
+func (f *family) snapshot() []series {
+	f.mu.Lock()
+	result := make([]series, 0, len(f.series)+len(f.funcs))
+	for _, s := range f.series {
+		copied := *s
+		copied.bucketHits = append([]uint64(nil), s.bucketHits...)
+		result = append(result, copied)
+	}
+	funcs := make([]*funcSeries, 0, len(f.funcs))
+	for _, fs := range f.funcs {
+		funcs = append(funcs, fs)
+	}
+	f.mu.Unlock()
+
+	// Callbacks run without the lock, they may be slow or use other metrics
+	for _, fs := range funcs {
+		result = append(result, series{labelValues: fs.labelValues, value: fs.fn()})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return seriesKey(result[i].labelValues) < seriesKey(result[j].labelValues)
+	})
+	return result
+}
+
+// Counter is a monotonically increasing value, such as the number of processed requests.
+type Counter struct {
+	family *family
+}
+
+// Inc increments the counter of the series with the given label values by one.
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add increases the counter of the series with the given label values.
+// Negative values are ignored, since counters never decrease.
+func (c *Counter) Add(value float64, labelValues ...string) {
+	if value < 0 {
+		return
+	}
+	c.family.mu.Lock()
+	defer c.family.mu.Unlock()
+	c.family.with(labelValues).value += value
+}
+
+// Gauge is a value that can go up and down, such as the number of running tasks.
+type Gauge struct {
+	family *family
+}
+
+// Set sets the gauge of the series with the given label values.
+func (g *Gauge) Set(value float64, labelValues ...string) {
+	g.family.mu.Lock()
+	defer g.family.mu.Unlock()
+	g.family.with(labelValues).value = value
+}
+
+// Add adds the value, which may be negative, to the gauge of the series.
+func (g *Gauge) Add(value float64, labelValues ...string) {
+	g.family.mu.Lock()
+	defer g.family.mu.Unlock()
+	g.family.with(labelValues).value += value
+}
+
+// Inc increments the gauge of the series by one.
+func (g *Gauge) Inc(labelValues ...string) {
+	g.Add(1, labelValues...)
+}
+
+// Dec decrements the gauge of the series by one.
+func (g *Gauge) Dec(labelValues ...string) {
+	g.Add(-1, labelValues...)
+}
+
+// Histogram counts observations, such as durations, in configurable buckets.
+type Histogram struct {
+	family *family
+}
+
+// Observe records a value in the series with the given label values.
+func (h *Histogram) Observe(value float64, labelValues ...string) {
+	h.family.mu.Lock()
+	defer h.family.mu.Unlock()
+
+	s := h.family.with(labelValues)
+	s.sum += value
+	s.count++
+	for i, upperBound := range h.family.buckets {
+		if value <= upperBound {
+			s.bucketHits[i]++
+			break
+		}
+	}
+}
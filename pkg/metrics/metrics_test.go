@@ -0,0 +1,170 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// scrape renders the registry in the text format
+func scrape(t *testing.T, registry *Registry) string {
+	t.Helper()
+	var out strings.Builder
+	if err := registry.WriteText(&out); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+	return out.String()
+}
+
+// TestTextFormat tests the exposition of every metric type
+func TestTextFormat(t *testing.T) {
+	registry := NewRegistry()
+
+	requests := registry.Counter("http_requests_total", "Handled HTTP requests.", "method", "code")
+	requests.Inc("GET", "200")
+	requests.Add(2, "GET", "200")
+	requests.Inc("POST", "500")
+	requests.Add(-5, "POST", "500") // Ignored
+
+	inFlight := registry.Gauge("http_in_flight", "Requests in flight.")
+	inFlight.Inc()
+	inFlight.Inc()
+	inFlight.Dec()
+
+	latency := registry.Histogram("http_latency_seconds", "Request latency.", []float64{1, 0.1}, "method")
+	latency.Observe(0.05, "GET")
+	latency.Observe(0.5, "GET")
+	latency.Observe(3, "GET")
+
+	registry.GaugeFunc("pool_idle", "Idle connections.", Labels{"pool": "main"}, func() float64 { return 7 })
+
+	expected := `# HELP http_in_flight Requests in flight.
+# TYPE http_in_flight gauge
+http_in_flight 1
+# HELP http_latency_seconds Request latency.
+# TYPE http_latency_seconds histogram
+http_latency_seconds_bucket{method="GET",le="0.1"} 1
+http_latency_seconds_bucket{method="GET",le="1"} 2
+http_latency_seconds_bucket{method="GET",le="+Inf"} 3
+http_latency_seconds_sum{method="GET"} 3.55
+http_latency_seconds_count{method="GET"} 3
+# HELP http_requests_total Handled HTTP requests.
+# TYPE http_requests_total counter
+http_requests_total{method="GET",code="200"} 3
+http_requests_total{method="POST",code="500"} 1
+# HELP pool_idle Idle connections.
+# TYPE pool_idle gauge
+pool_idle{pool="main"} 7
+`
+	if got := scrape(t, registry); got != expected {
+		t.Errorf("Unexpected output:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+// TestEscaping tests escaping of help text and label values
+func TestEscaping(t *testing.T) {
+	registry := NewRegistry()
+	registry.Counter("events_total", "Line one\nback\\slash", "source").Inc("a \"quoted\"\nvalue")
+
+	output := scrape(t, registry)
+	for _, expected := range []string{
+		`# HELP events_total Line one\nback\\slash`,
+		`events_total{source="a \"quoted\"\nvalue"} 1`,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %s, got: %s", expected, output)
+		}
+	}
+}
+
+// TestRegistration tests re-registration and registration errors
+func TestRegistration(t *testing.T) {
+	registry := NewRegistry()
+
+	first := registry.Counter("jobs_total", "Jobs.", "queue")
+	second := registry.Counter("jobs_total", "Jobs.", "queue")
+	first.Inc("a")
+	second.Inc("a")
+	if output := scrape(t, registry); !strings.Contains(output, `jobs_total{queue="a"} 2`) {
+		t.Errorf("Expected re-registration to share the metric, got: %s", output)
+	}
+
+	// Metrics without series are not exposed
+	registry.Gauge("unused", "Never set.")
+	if output := scrape(t, registry); strings.Contains(output, "unused") {
+		t.Errorf("Expected metric without series to be omitted, got: %s", output)
+	}
+
+	for name, register := range map[string]func(){
+		"conflicting type":   func() { registry.Gauge("jobs_total", "Jobs.", "queue") },
+		"conflicting labels": func() { registry.Counter("jobs_total", "Jobs.", "worker") },
+		"invalid name":       func() { registry.Counter("jobs-total", "Jobs.") },
+		"reserved label":     func() { registry.Histogram("latency", "Latency.", nil, "le") },
+		"label count":        func() { first.Inc("a", "b") },
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Expected panic")
+				}
+			}()
+			register()
+		})
+	}
+}
+
+// TestHandler tests the HTTP endpoint
+func TestHandler(t *testing.T) {
+	registry := NewRegistry()
+	registry.Counter("hits_total", "Hits.").Inc()
+
+	server := httptest.NewServer(registry.Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	if contentType := resp.Header.Get("Content-Type"); contentType != ContentType {
+		t.Errorf("Expected content type %q, got %q", ContentType, contentType)
+	}
+	if !strings.Contains(string(body), "hits_total 1\n") {
+		t.Errorf("Unexpected body: %s", body)
+	}
+}
+
+// TestConcurrentUpdates tests that metrics can be updated while being scraped
+func TestConcurrentUpdates(t *testing.T) {
+	registry := NewRegistry()
+	counter := registry.Counter("ops_total", "Operations.", "worker")
+	histogram := registry.Histogram("op_seconds", "Operation time.", nil)
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 1000 {
+				counter.Inc("w")
+				histogram.Observe(0.01)
+			}
+		}()
+	}
+	for range 10 {
+		scrape(t, registry)
+	}
+	wg.Wait()
+
+	output := scrape(t, registry)
+	for _, expected := range []string{`ops_total{worker="w"} 4000`, "op_seconds_count 4000"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %s, got: %s", expected, output)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+// Package metrics provides a dependency-free metrics registry.
+// This file contains the Registry type that owns metric families and registers
+// counters, gauges, histograms and callback-based metrics.
+package metrics
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// metricType is the Prometheus type of a metric family.
+type metricType string
+
+const (
+	typeCounter   metricType = "counter"
+	typeGauge     metricType = "gauge"
+	typeHistogram metricType = "histogram"
+)
+
+// Labels is a set of constant label names and values attached to a callback metric.
+type Labels map[string]string
+
+// DefaultBuckets are histogram buckets suited to durations in seconds, from 5ms to 10s.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// namePattern matches valid metric and label names.
+var namePattern = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
+// Registry holds metric families and renders them in the Prometheus text format.
+// Registering a metric that already exists with the same type and labels returns
+// the existing metric, so independent components can share a registry.
+// Registering a conflicting metric (different type or labels) or using an invalid
+// name is a programming error and panics.
+//
+// Example:
+//
+//	registry := metrics.NewRegistry()
+//	requests := registry.Counter("http_requests_total", "Handled HTTP requests.", "method", "code")
+//	requests.Inc("GET", "200")
+//
+//	http.Handle("/metrics", registry.Handler())
+type Registry struct {
+	mu       sync.RWMutex
+	families map[string]*family
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		families: make(map[string]*family),
+	}
+}
+
+// Counter registers a counter with the given label names.
+func (r *Registry) Counter(name, help string, labelNames ...string) *Counter {
+	return &Counter{family: r.register(name, help, typeCounter, labelNames, nil)}
+}
+
+// Gauge registers a gauge with the given label names.
+func (r *Registry) Gauge(name, help string, labelNames ...string) *Gauge {
+	return &Gauge{family: r.register(name, help, typeGauge, labelNames, nil)}
+}
+
+// Histogram registers a histogram with the given upper bounds and label names.
+// Nil buckets mean DefaultBuckets.
+func (r *Registry) Histogram(name, help string, buckets []float64, labelNames ...string) *Histogram {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	buckets = slices.Clone(buckets)
+	sort.Float64s(buckets)
+	return &Histogram{family: r.register(name, help, typeHistogram, labelNames, buckets)}
+}
+
+// CounterFunc registers a counter whose value is read from fn on every scrape,
+// for example the hit count of a connection pool. Registering the same name and
+// labels again replaces the callback.
+func (r *Registry) CounterFunc(name, help string, labels Labels, fn func() float64) {
+	r.registerFunc(name, help, typeCounter, labels, fn)
+}
+
+// GaugeFunc registers a gauge whose value is read from fn on every scrape,
+// for example the number of idle connections of a pool. Registering the same name
+// and labels again replaces the callback.
+func (r *Registry) GaugeFunc(name, help string, labels Labels, fn func() float64) {
+	r.registerFunc(name, help, typeGauge, labels, fn)
+}
+
+// registerFunc registers a callback series under a counter or gauge family.
+func (r *Registry) registerFunc(name, help string, typ metricType, labels Labels, fn func() float64) {
+	labelNames := make([]string, 0, len(labels))
+	for labelName := range labels {
+		labelNames = append(labelNames, labelName)
+	}
+	sort.Strings(labelNames)
+
+	labelValues := make([]string, len(labelNames))
+	for i, labelName := range labelNames {
+		labelValues[i] = labels[labelName]
+	}
+
+	f := r.register(name, help, typ, labelNames, nil)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.funcs[seriesKey(labelValues)] = &funcSeries{labelValues: labelValues, fn: fn}
+}
+
+// register returns the family with the given name, creating it on first use.
+func (r *Registry) register(name, help string, typ metricType, labelNames []string, buckets []float64) *family {
+	if !namePattern.MatchString(name) {
+		panic(fmt.Sprintf("metrics: invalid metric name %q", name))
+	}
+	for _, labelName := range labelNames {
+		if !namePattern.MatchString(labelName) || strings.HasPrefix(labelName, "__") || labelName == "le" {
+			panic(fmt.Sprintf("metrics: invalid label name %q for metric %q", labelName, name))
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.families[name]; ok {
+		if existing.typ != typ || !slices.Equal(existing.labelNames, labelNames) || !slices.Equal(existing.buckets, buckets) {
+			panic(fmt.Sprintf("metrics: metric %q is already registered with a different type, labels or buckets", name))
+		}
+		return existing
+	}
+
+	f := &family{
+		name:       name,
+		help:       help,
+		typ:        typ,
+		labelNames: slices.Clone(labelNames),
+		buckets:    buckets,
+		series:     make(map[string]*series),
+		funcs:      make(map[string]*funcSeries),
+	}
+	r.families[name] = f
+	return f
+}
+
+// sortedFamilies returns the registered families ordered by name.
+func (r *Registry) sortedFamilies() []*family {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	families := make([]*family, 0, len(r.families))
+	for _, f := range r.families {
+		families = append(families, f)
+	}
+	sort.Slice(families, func(i, j int) bool { return families[i].name < families[j].name })
+	return families
+}
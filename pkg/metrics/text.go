@@ -0,0 +1,114 @@
+// Package metrics provides the Prometheus text exposition format.
+// This file contains the writer that renders a registry in text format version 0.0.4
+// and the HTTP handler that serves it on a /metrics endpoint.
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ContentType is the content type of the Prometheus text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// WriteText writes all metrics in the Prometheus text exposition format,
+// families ordered by name and series ordered by label values.
+func (r *Registry) WriteText(w io.Writer) error {
+	buf := bufio.NewWriter(w)
+
+	for _, f := range r.sortedFamilies() {
+		snapshot := f.snapshot()
+		if len(snapshot) == 0 {
+			continue
+		}
+
+		if f.help != "" {
+			buf.WriteString("# HELP " + f.name + " " + escapeHelp(f.help) + "\n")
+		}
+		buf.WriteString("# TYPE " + f.name + " " + string(f.typ) + "\n")
+
+		for _, s := range snapshot {
+			if f.typ != typeHistogram {
+				writeSample(buf, f.name, f.labelNames, s.labelValues, "", s.value)
+				continue
+			}
+
+			var cumulative uint64
+			for i, upperBound := range f.buckets {
+				cumulative += s.bucketHits[i]
+				writeSample(buf, f.name+"_bucket", f.labelNames, s.labelValues, formatFloat(upperBound), float64(cumulative))
+			}
+			writeSample(buf, f.name+"_bucket", f.labelNames, s.labelValues, "+Inf", float64(s.count))
+			writeSample(buf, f.name+"_sum", f.labelNames, s.labelValues, "", s.sum)
+			writeSample(buf, f.name+"_count", f.labelNames, s.labelValues, "", float64(s.count))
+		}
+	}
+	return buf.Flush()
+}
+
+// Handler returns an HTTP handler serving the metrics in the Prometheus text format.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		_ = r.WriteText(w)
+	})
+}
+
+// writeSample writes a single sample line. A non-empty le adds the histogram bucket label.
+func writeSample(buf *bufio.Writer, name string, labelNames, labelValues []string, le string, value float64) {
+	buf.WriteString(name)
+
+	if len(labelNames) > 0 || le != "" {
+		buf.WriteByte('{')
+		for i, labelName := range labelNames {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(labelName + `="` + escapeLabelValue(labelValues[i]) + `"`)
+		}
+		if le != "" {
+			if len(labelNames) > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(`le="` + le + `"`)
+		}
+		buf.WriteByte('}')
+	}
+
+	buf.WriteByte(' ')
+	buf.WriteString(formatFloat(value))
+	buf.WriteByte('\n')
+}
+
+// formatFloat formats a sample value as expected by Prometheus.
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
+
+var (
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+// escapeHelp escapes backslashes and line feeds in help text.
+func escapeHelp(help string) string {
+	return helpEscaper.Replace(help)
+}
+
+// escapeLabelValue escapes backslashes, double quotes and line feeds in label values.
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
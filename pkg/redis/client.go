@@ -38,3 +38,9 @@ func NewClient(ctx context.Context, redisURL string) (*Client, func(), error) {
 		},
 		nil
 }
+
+// HealthCheck pings the Redis server. It implements launcher.HealthChecker,
+// so the client can be registered in the launcher health registry.
+func (c *Client) HealthCheck(ctx context.Context) error {
+	return c.Ping(ctx).Err()
+}
@@ -0,0 +1,272 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// fakeEntry is a value stored by the fake server
+type fakeEntry struct {
+	value     string
+	expiresAt time.Time // Zero if the key does not expire
+}
+
+// fakeServer is an in-process Redis stand-in speaking RESP2.
+// It supports the commands used by this package: PING, GET, SET (NX, EX, PX),
+// DEL, PEXPIRE and the lease scripts through EVALSHA/EVAL.
+type fakeServer struct {
+	listener net.Listener
+	mu       sync.Mutex
+	data     map[string]fakeEntry
+	failing  bool          // Reply to every command with an error
+	stalled  chan struct{} // Replies wait until it is closed, nil when not stalled
+}
+
+// newFakeServer starts a fake server and a client connected to it
+func newFakeServer(t *testing.T) (*fakeServer, *Client) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+
+	server := &fakeServer{listener: listener, data: make(map[string]fakeEntry)}
+	go server.serve()
+
+	client := &Client{Client: redis.NewClient(&redis.Options{Addr: listener.Addr().String(), Protocol: 2})}
+	t.Cleanup(func() {
+		client.Close()
+		listener.Close()
+	})
+	return server, client
+}
+
+func (s *fakeServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeServer) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		stalled := s.stalled
+		s.mu.Unlock()
+		if stalled != nil {
+			<-stalled
+		}
+		if _, err := io.WriteString(conn, s.execute(args)); err != nil {
+			return
+		}
+	}
+}
+
+// readCommand reads a RESP array of bulk strings
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]string, count)
+	for i := range args {
+		if line, err = reader.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "$")))
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+func (s *fakeServer) execute(args []string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failing {
+		return "-ERR server unavailable\r\n"
+	}
+
+	switch strings.ToUpper(args[0]) {
+	case "PING":
+		return "+PONG\r\n"
+	case "GET":
+		if entry, ok := s.lookup(args[1]); ok {
+			return bulkString(entry.value)
+		}
+		return "$-1\r\n"
+	case "SET":
+		return s.set(args[1:])
+	case "DEL":
+		return s.del(args[1])
+	case "PEXPIRE":
+		return s.pexpire(args[1], args[2])
+	case "EVALSHA":
+		return "-NOSCRIPT No matching script.\r\n"
+	case "EVAL":
+		return s.eval(args[1], args[3], args[4:])
+	default:
+		return fmt.Sprintf("-ERR unknown command '%s'\r\n", args[0])
+	}
+}
+
+func (s *fakeServer) lookup(key string) (fakeEntry, bool) {
+	entry, ok := s.data[key]
+	if ok && !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		delete(s.data, key)
+		return fakeEntry{}, false
+	}
+	return entry, ok
+}
+
+func (s *fakeServer) set(args []string) string {
+	key, entry := args[0], fakeEntry{value: args[1]}
+	nx := false
+	for i := 2; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case "NX":
+			nx = true
+		case "PX", "EX":
+			amount, _ := strconv.Atoi(args[i+1])
+			unit := time.Millisecond
+			if strings.ToUpper(args[i]) == "EX" {
+				unit = time.Second
+			}
+			entry.expiresAt = time.Now().Add(time.Duration(amount) * unit)
+			i++
+		}
+	}
+	if _, exists := s.lookup(key); exists && nx {
+		return "$-1\r\n"
+	}
+	s.data[key] = entry
+	return "+OK\r\n"
+}
+
+func (s *fakeServer) del(key string) string {
+	if _, ok := s.lookup(key); ok {
+		delete(s.data, key)
+		return ":1\r\n"
+	}
+	return ":0\r\n"
+}
+
+func (s *fakeServer) pexpire(key, ms string) string {
+	entry, ok := s.lookup(key)
+	if !ok {
+		return ":0\r\n"
+	}
+	amount, _ := strconv.Atoi(ms)
+	entry.expiresAt = time.Now().Add(time.Duration(amount) * time.Millisecond)
+	s.data[key] = entry
+	return ":1\r\n"
+}
+
+// eval interprets the lease scripts of this package
+func (s *fakeServer) eval(script, key string, args []string) string {
+	entry, ok := s.lookup(key)
+	if !ok || entry.value != args[0] {
+		return ":0\r\n"
+	}
+	switch script {
+	case renewLeaseSource:
+		return s.pexpire(key, args[1])
+	case releaseLeaseSource:
+		return s.del(key)
+	}
+	return "-ERR unsupported script\r\n"
+}
+
+// holder returns the value of the key
+func (s *fakeServer) holder(key string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, _ := s.lookup(key)
+	return entry.value
+}
+
+// steal overwrites the key as if another replica took the lease over
+func (s *fakeServer) steal(key, value string, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[key] = fakeEntry{value: value, expiresAt: time.Now().Add(ttl)}
+}
+
+// setFailing makes the server reply to every command with an error
+func (s *fakeServer) setFailing(failing bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failing = failing
+}
+
+// stall makes the server hold its replies until the returned function is called
+func (s *fakeServer) stall() (resume func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stalled := make(chan struct{})
+	s.stalled = stalled
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.mu.Lock()
+			s.stalled = nil
+			s.mu.Unlock()
+			close(stalled)
+		})
+	}
+}
+
+func bulkString(value string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+}
+
+// TestFakeServer checks the stand-in itself through the real client
+func TestFakeServer(t *testing.T) {
+	_, client := newFakeServer(t)
+	ctx := context.Background()
+
+	if err := client.HealthCheck(ctx); err != nil {
+		t.Fatalf("Ping failed: %v", err)
+	}
+	if ok, err := client.SetNX(ctx, "k", "v", time.Second).Result(); err != nil || !ok {
+		t.Fatalf("SetNX failed: %v %v", ok, err)
+	}
+	if ok, _ := client.SetNX(ctx, "k", "other", time.Second).Result(); ok {
+		t.Error("SetNX must not overwrite an existing key")
+	}
+	if value, _ := client.Get(ctx, "k").Result(); value != "v" {
+		t.Errorf("Expected v, got %q", value)
+	}
+}
@@ -0,0 +1,192 @@
+package redis
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrLeaseLost is the cancellation cause of a leader context whose lease
+// was taken over by another replica or could not be renewed in time.
+var ErrLeaseLost = errors.New("leader lease lost")
+
+// renewLeaseSource extends the lease only if it is still held by the caller.
+const renewLeaseSource = `
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`
+
+// releaseLeaseSource deletes the lease only if it is still held by the caller.
+const releaseLeaseSource = `
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`
+
+var (
+	renewLeaseScript   = redis.NewScript(renewLeaseSource)
+	releaseLeaseScript = redis.NewScript(releaseLeaseSource)
+)
+
+// LeaderElector elects a leader using a lease key: the replica that sets the key
+// holds the leadership and renews the key expiration in the background.
+// It implements launcher.LeaderElector.
+type LeaderElector struct {
+	client        *Client
+	key           string
+	id            string
+	ttl           time.Duration
+	retryInterval time.Duration
+}
+
+// NewLeaderElector creates an elector for the given lease key.
+// The lease expires ttl after the last renewal, so a crashed leader is replaced
+// after at most ttl; the lease is renewed every ttl/3.
+func (c *Client) NewLeaderElector(key string, ttl time.Duration) *LeaderElector {
+	return &LeaderElector{
+		client:        c,
+		key:           key,
+		id:            newLeaderID(),
+		ttl:           ttl,
+		retryInterval: ttl / 3,
+	}
+}
+
+// WithID overrides the replica identity stored in the lease key, a random value by default.
+func (e *LeaderElector) WithID(id string) *LeaderElector {
+	e.id = id
+	return e
+}
+
+// WithRetryInterval sets how often a follower tries to acquire the lease, ttl/3 by default.
+// The interval must be positive, otherwise Lead fails instead of hammering Redis.
+func (e *LeaderElector) WithRetryInterval(interval time.Duration) *LeaderElector {
+	e.retryInterval = interval
+	return e
+}
+
+// ID returns the replica identity stored in the lease key.
+func (e *LeaderElector) ID() string {
+	return e.id
+}
+
+// Lead blocks until the lease is acquired or ctx is done. Redis errors while
+// campaigning are retried. The returned context is canceled with ErrLeaseLost
+// when the lease is taken over or cannot be renewed before it expires.
+func (e *LeaderElector) Lead(ctx context.Context) (context.Context, func(), error) {
+	if e.ttl <= 0 {
+		return nil, nil, fmt.Errorf("leader lease %q: ttl must be positive", e.key)
+	}
+	if e.retryInterval <= 0 {
+		return nil, nil, fmt.Errorf("leader lease %q: retry interval must be positive", e.key)
+	}
+
+	var acquiredAt time.Time
+	for {
+		// The lease expires no earlier than ttl after the request was sent
+		acquiredAt = time.Now()
+		acquired, err := e.client.SetNX(ctx, e.key, e.id, e.ttl).Result()
+		if err == nil && acquired {
+			break
+		}
+
+		timer := time.NewTimer(e.retryInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	leaderCtx, cancel := context.WithCancelCause(ctx)
+	renewDone := make(chan struct{})
+	go func() {
+		defer close(renewDone)
+		e.renew(leaderCtx, cancel, acquiredAt.Add(e.ttl))
+	}()
+
+	release := func() {
+		cancel(context.Canceled)
+		<-renewDone
+
+		releaseCtx, releaseCancel := context.WithTimeout(context.WithoutCancel(ctx), e.ttl)
+		defer releaseCancel()
+		_ = releaseLeaseScript.Run(releaseCtx, e.client, []string{e.key}, e.id).Err()
+	}
+	return leaderCtx, release, nil
+}
+
+// renew extends the lease every ttl/3 until ctx is done. Transient errors are
+// retried until the lease would have expired; then the leadership is considered lost.
+// Every renewal must complete before the lease expires, so a stalled Redis ends the
+// leadership before another replica can take the lease over.
+func (e *LeaderElector) renew(ctx context.Context, cancel context.CancelCauseFunc, expiresAt time.Time) {
+	interval := e.ttl / 3
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		renewedAt := time.Now()
+		renewed, err := e.renewBefore(ctx, expiresAt)
+		switch {
+		case ctx.Err() != nil:
+			return
+		case err == nil && renewed:
+			expiresAt = renewedAt.Add(e.ttl)
+		case err == nil:
+			cancel(ErrLeaseLost)
+			return
+		case time.Now().Add(interval).After(expiresAt):
+			cancel(fmt.Errorf("%w: %w", ErrLeaseLost, err))
+			return
+		}
+	}
+}
+
+// renewBefore extends the lease, giving up with context.DeadlineExceeded at expiresAt.
+// The client only honors context deadlines when ContextTimeoutEnabled is set, so the
+// call runs in its own goroutine, which returns at the latest after the read timeout.
+func (e *LeaderElector) renewBefore(ctx context.Context, expiresAt time.Time) (bool, error) {
+	ctx, cancel := context.WithDeadline(ctx, expiresAt)
+	defer cancel()
+
+	type result struct {
+		renewed int
+		err     error
+	}
+	done := make(chan result, 1)
+	go func() {
+		renewed, err := renewLeaseScript.Run(ctx, e.client, []string{e.key}, e.id, e.ttl.Milliseconds()).Int()
+		done <- result{renewed, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.renewed == 1, r.err
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+}
+
+// newLeaderID returns a replica identity made of the host name, the process id and a random suffix.
+func newLeaderID() string {
+	host, _ := os.Hostname()
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(suffix))
+}
@@ -0,0 +1,188 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/goregion/hexago/pkg/launcher"
+)
+
+const testLeaseKey = "leader:test"
+
+// TestLeaderElectorSingleLeader tests that only one replica holds the lease at a time
+func TestLeaderElectorSingleLeader(t *testing.T) {
+	server, client := newFakeServer(t)
+	ctx := context.Background()
+
+	first := client.NewLeaderElector(testLeaseKey, 300*time.Millisecond).WithID("replica-1")
+	second := client.NewLeaderElector(testLeaseKey, 300*time.Millisecond).WithID("replica-2").
+		WithRetryInterval(10 * time.Millisecond)
+
+	_, releaseFirst, err := first.Lead(ctx)
+	if err != nil {
+		t.Fatalf("First replica failed to lead: %v", err)
+	}
+	if holder := server.holder(testLeaseKey); holder != "replica-1" {
+		t.Fatalf("Expected replica-1 to hold the lease, got %q", holder)
+	}
+
+	campaignCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if _, _, err := second.Lead(campaignCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Second replica must not lead while the lease is held, got: %v", err)
+	}
+
+	releaseFirst()
+	leaderCtx, releaseSecond, err := second.Lead(ctx)
+	if err != nil {
+		t.Fatalf("Second replica failed to lead after release: %v", err)
+	}
+	defer releaseSecond()
+	if leaderCtx.Err() != nil || server.holder(testLeaseKey) != "replica-2" {
+		t.Errorf("Expected replica-2 to lead, holder is %q", server.holder(testLeaseKey))
+	}
+}
+
+// TestLeaderElectorInvalidRetryInterval tests that a non-positive retry interval is rejected
+func TestLeaderElectorInvalidRetryInterval(t *testing.T) {
+	_, client := newFakeServer(t)
+
+	for _, interval := range []time.Duration{0, -time.Second} {
+		elector := client.NewLeaderElector(testLeaseKey, time.Second).WithRetryInterval(interval)
+		if _, _, err := elector.Lead(context.Background()); err == nil {
+			t.Errorf("Expected an error for retry interval %v", interval)
+		}
+	}
+}
+
+// TestLeaderElectorRenewal tests that the lease outlives its ttl while renewed
+func TestLeaderElectorRenewal(t *testing.T) {
+	server, client := newFakeServer(t)
+
+	leaderCtx, release, err := client.NewLeaderElector(testLeaseKey, 60*time.Millisecond).Lead(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to lead: %v", err)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	if leaderCtx.Err() != nil || server.holder(testLeaseKey) == "" {
+		t.Fatalf("Lease was not renewed: %v", context.Cause(leaderCtx))
+	}
+
+	release()
+	if holder := server.holder(testLeaseKey); holder != "" {
+		t.Errorf("Expected the lease to be deleted on release, got %q", holder)
+	}
+}
+
+// TestLeaderElectorLeaseLost tests that the leader context is canceled when the lease is taken over
+func TestLeaderElectorLeaseLost(t *testing.T) {
+	server, client := newFakeServer(t)
+
+	leaderCtx, release, err := client.NewLeaderElector(testLeaseKey, 60*time.Millisecond).Lead(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to lead: %v", err)
+	}
+	defer release()
+
+	server.steal(testLeaseKey, "intruder", time.Minute)
+
+	select {
+	case <-leaderCtx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Leader context was not canceled after the lease was taken over")
+	}
+	if !errors.Is(context.Cause(leaderCtx), ErrLeaseLost) {
+		t.Errorf("Expected ErrLeaseLost, got: %v", context.Cause(leaderCtx))
+	}
+
+	release()
+	if holder := server.holder(testLeaseKey); holder != "intruder" {
+		t.Errorf("Release must not delete a lease held by another replica, got %q", holder)
+	}
+}
+
+// TestLeaderElectorRenewalFailure tests that leadership is given up when renewals keep failing
+func TestLeaderElectorRenewalFailure(t *testing.T) {
+	server, client := newFakeServer(t)
+
+	leaderCtx, release, err := client.NewLeaderElector(testLeaseKey, 60*time.Millisecond).Lead(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to lead: %v", err)
+	}
+	defer release()
+
+	server.setFailing(true)
+
+	select {
+	case <-leaderCtx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Leader context was not canceled while Redis was unavailable")
+	}
+	if !errors.Is(context.Cause(leaderCtx), ErrLeaseLost) {
+		t.Errorf("Expected ErrLeaseLost, got: %v", context.Cause(leaderCtx))
+	}
+}
+
+// TestLeaderElectorRenewalStalled tests that leadership is given up before the lease
+// expires when a renewal hangs
+func TestLeaderElectorRenewalStalled(t *testing.T) {
+	server, client := newFakeServer(t)
+
+	ttl := 90 * time.Millisecond
+	leadStarted := time.Now()
+	leaderCtx, release, err := client.NewLeaderElector(testLeaseKey, ttl).Lead(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to lead: %v", err)
+	}
+	resume := server.stall()
+	defer release()
+	defer resume()
+
+	select {
+	case <-leaderCtx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Leader context was not canceled while the renewal was stalled")
+	}
+	if elapsed := time.Since(leadStarted); elapsed > ttl+20*time.Millisecond {
+		t.Errorf("Expected the leadership to end before the lease expired, took %v", elapsed)
+	}
+	if !errors.Is(context.Cause(leaderCtx), ErrLeaseLost) {
+		t.Errorf("Expected ErrLeaseLost, got: %v", context.Cause(leaderCtx))
+	}
+}
+
+// TestLeaderTaskWithRedisElector tests that only one of several replicas runs the singleton task
+func TestLeaderTaskWithRedisElector(t *testing.T) {
+	_, client := newFakeServer(t)
+
+	var running, maxRunning int32
+	singleton := func(ctx context.Context) error {
+		if current := atomic.AddInt32(&running, 1); current > atomic.LoadInt32(&maxRunning) {
+			atomic.StoreInt32(&maxRunning, current)
+		}
+		<-ctx.Done()
+		atomic.AddInt32(&running, -1)
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+	defer cancel()
+
+	replicas := make([]func(ctx context.Context) error, 3)
+	for i := range replicas {
+		elector := client.NewLeaderElector(testLeaseKey, 60*time.Millisecond)
+		replicas[i] = launcher.LeaderTask("ohlc-generator", elector, singleton)
+	}
+
+	result := launcher.NewAppLauncherWithContext(ctx).WaitApplications(replicas[0], replicas[1], replicas[2])
+	if result.Error() != nil && !errors.Is(result.Error(), context.DeadlineExceeded) {
+		t.Errorf("Unexpected error: %v", result.Error())
+	}
+	if got := atomic.LoadInt32(&maxRunning); got != 1 {
+		t.Errorf("Expected exactly one replica to run the task, got %d", got)
+	}
+}
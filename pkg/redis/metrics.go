@@ -0,0 +1,25 @@
+package redis
+
+import (
+	"github.com/goregion/hexago/pkg/metrics"
+)
+
+// RegisterMetrics registers the connection pool stats of the client in the registry.
+// The name is used as the "client" label, so several clients can share a registry.
+// Pool stats are read on every scrape.
+func (c *Client) RegisterMetrics(registry *metrics.Registry, name string) {
+	labels := metrics.Labels{"client": name}
+
+	registry.CounterFunc("redis_pool_hits_total", "Times a free connection was found in the Redis pool.", labels,
+		func() float64 { return float64(c.PoolStats().Hits) })
+	registry.CounterFunc("redis_pool_misses_total", "Times a free connection was not found in the Redis pool.", labels,
+		func() float64 { return float64(c.PoolStats().Misses) })
+	registry.CounterFunc("redis_pool_timeouts_total", "Times waiting for a Redis pool connection timed out.", labels,
+		func() float64 { return float64(c.PoolStats().Timeouts) })
+	registry.GaugeFunc("redis_pool_total_connections", "Connections in the Redis pool.", labels,
+		func() float64 { return float64(c.PoolStats().TotalConns) })
+	registry.GaugeFunc("redis_pool_idle_connections", "Idle connections in the Redis pool.", labels,
+		func() float64 { return float64(c.PoolStats().IdleConns) })
+	registry.CounterFunc("redis_pool_stale_connections_total", "Stale connections removed from the Redis pool.", labels,
+		func() float64 { return float64(c.PoolStats().StaleConns) })
+}
@@ -0,0 +1,35 @@
+package redis
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/goregion/hexago/pkg/metrics"
+)
+
+// TestRegisterMetrics tests that pool stats are exposed with the client label
+func TestRegisterMetrics(t *testing.T) {
+	_, client := newFakeServer(t)
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		t.Fatalf("Ping failed: %v", err)
+	}
+
+	registry := metrics.NewRegistry()
+	client.RegisterMetrics(registry, "ticks")
+
+	var output strings.Builder
+	if err := registry.WriteText(&output); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+	for _, expected := range []string{
+		`redis_pool_total_connections{client="ticks"} 1`,
+		`redis_pool_idle_connections{client="ticks"} 1`,
+		`redis_pool_misses_total{client="ticks"} `,
+		"# TYPE redis_pool_hits_total counter",
+	} {
+		if !strings.Contains(output.String(), expected) {
+			t.Errorf("Expected output to contain %q, got: %s", expected, output.String())
+		}
+	}
+}
@@ -0,0 +1,202 @@
+// Package resilience provides composable task wrappers for tasks that talk to external systems.
+// This file contains the CircuitBreaker type that rejects calls for a while after
+// repeated failures and probes the dependency before closing again.
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/goregion/goture"
+	"github.com/goregion/hexago/pkg/log"
+)
+
+// ErrCircuitOpen is returned instead of calling the task while the breaker is open
+// or while the half-open breaker is already probing with other calls.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// BreakerState is the state of a circuit breaker.
+type BreakerState int
+
+const (
+	// StateClosed lets every call through and counts consecutive failures.
+	StateClosed BreakerState = iota
+	// StateOpen rejects every call with ErrCircuitOpen until OpenTimeout elapses.
+	StateOpen
+	// StateHalfOpen lets a limited number of probe calls through: a successful probe
+	// closes the breaker, a failed one opens it again.
+	StateHalfOpen
+)
+
+// String returns the state name used in logs.
+func (s BreakerState) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("BreakerState(%d)", int(s))
+	}
+}
+
+// BreakerOptions configures a circuit breaker.
+type BreakerOptions struct {
+	FailureThreshold int              // Consecutive failures that open the breaker, zero means 5
+	OpenTimeout      time.Duration    // Time the breaker stays open before probing, zero means 30s
+	HalfOpenCalls    int              // Concurrent probe calls allowed while half-open, zero means 1
+	IsFailure        func(error) bool // Reports whether an error counts as a failure, nil means every error except context cancellation
+}
+
+// CircuitBreaker protects a dependency from calls that are likely to fail.
+// It is safe for concurrent use and can be shared by several tasks calling the same dependency.
+// State transitions are logged through the logger of the context of the call that caused them.
+type CircuitBreaker struct {
+	name     string
+	options  BreakerOptions
+	mu       sync.Mutex
+	state    BreakerState
+	failures int       // Consecutive failures while closed
+	openedAt time.Time // When the breaker was opened
+	probes   int       // Probe calls in flight while half-open
+}
+
+// NewCircuitBreaker creates a closed circuit breaker. The name identifies the breaker in logs.
+func NewCircuitBreaker(name string, options BreakerOptions) *CircuitBreaker {
+	if options.FailureThreshold <= 0 {
+		options.FailureThreshold = 5
+	}
+	if options.OpenTimeout <= 0 {
+		options.OpenTimeout = 30 * time.Second
+	}
+	if options.HalfOpenCalls <= 0 {
+		options.HalfOpenCalls = 1
+	}
+	return &CircuitBreaker{name: name, options: options}
+}
+
+// State returns the current state of the breaker. An open breaker whose timeout has
+// elapsed is reported as open until the next call moves it to half-open.
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Execute calls the task if the breaker allows it and records the outcome.
+// It returns ErrCircuitOpen without calling the task when the call is rejected.
+// A panicking task is recorded as a failure before the panic is propagated.
+func (b *CircuitBreaker) Execute(ctx context.Context, task goture.Task) error {
+	probe, err := b.before(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// Releases the probe slot, otherwise the breaker would stay half-open for good
+		if r := recover(); r != nil {
+			b.after(ctx, probe, fmt.Errorf("circuit breaker %s: task panicked: %v", b.name, r), true)
+			panic(r)
+		}
+	}()
+	err = task(ctx)
+	b.after(ctx, probe, err, b.isFailure(ctx, err))
+	return err
+}
+
+// before decides whether a call may proceed and reports whether it is a probe call.
+func (b *CircuitBreaker) before(ctx context.Context) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == StateOpen {
+		if time.Since(b.openedAt) < b.options.OpenTimeout {
+			return false, fmt.Errorf("%w: %s", ErrCircuitOpen, b.name)
+		}
+		b.transition(ctx, StateHalfOpen, nil)
+	}
+	if b.state == StateHalfOpen {
+		if b.probes >= b.options.HalfOpenCalls {
+			return false, fmt.Errorf("%w: %s", ErrCircuitOpen, b.name)
+		}
+		b.probes++
+		return true, nil
+	}
+	return false, nil
+}
+
+// after records the outcome of a call.
+func (b *CircuitBreaker) after(ctx context.Context, probe bool, err error, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if probe {
+		b.probes--
+	}
+
+	switch b.state {
+	case StateClosed:
+		if !failed {
+			b.failures = 0
+			return
+		}
+		b.failures++
+		if b.failures >= b.options.FailureThreshold {
+			b.transition(ctx, StateOpen, err)
+		}
+	case StateHalfOpen:
+		// Only probes decide, calls admitted before the breaker opened are ignored
+		if !probe {
+			return
+		}
+		if failed {
+			b.transition(ctx, StateOpen, err)
+		} else if err == nil {
+			b.transition(ctx, StateClosed, nil)
+		}
+	}
+}
+
+// isFailure reports whether the call outcome counts as a failure.
+func (b *CircuitBreaker) isFailure(ctx context.Context, err error) bool {
+	if b.options.IsFailure != nil {
+		return b.options.IsFailure(err)
+	}
+	return err != nil && !(ctx.Err() != nil && errors.Is(err, context.Canceled))
+}
+
+// transition changes the state and logs the change. The caller must hold b.mu.
+func (b *CircuitBreaker) transition(ctx context.Context, to BreakerState, cause error) {
+	from := b.state
+	b.state = to
+	b.failures = 0
+	if to == StateOpen {
+		b.openedAt = time.Now()
+	}
+
+	logger, err := log.GetLoggerFromContext(ctx)
+	if err != nil {
+		return
+	}
+	attrs := []any{"breaker", b.name, "from", from.String(), "to", to.String()}
+	switch to {
+	case StateOpen:
+		attrs = append(attrs, "error", cause, "open_timeout", b.options.OpenTimeout)
+		logger.Warn("circuit breaker state changed", attrs...)
+	default:
+		logger.Info("circuit breaker state changed", attrs...)
+	}
+}
+
+// WithCircuitBreaker returns a wrapper that calls the task through the breaker.
+func WithCircuitBreaker(breaker *CircuitBreaker) Wrapper {
+	return func(task goture.Task) goture.Task {
+		return func(ctx context.Context) error {
+			return breaker.Execute(ctx, task)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package resilience
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goregion/hexago/pkg/log"
+)
+
+// transitions decodes the state transitions logged by the breaker
+func transitions(t *testing.T, output string) []string {
+	t.Helper()
+
+	var result []string
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Failed to decode log line %q: %v", line, err)
+		}
+		if record["msg"] == "circuit breaker state changed" && record["breaker"] == "exchange-api" {
+			result = append(result, record["from"].(string)+"->"+record["to"].(string))
+		}
+	}
+	return result
+}
+
+// TestCircuitBreakerStates tests the closed, open and half-open cycle and the logged transitions
+func TestCircuitBreakerStates(t *testing.T) {
+	var logOutput bytes.Buffer
+	ctx := log.WithLoggerContext(context.Background(), log.NewLogger(log.NewJsonHandler(&logOutput)))
+
+	breaker := NewCircuitBreaker("exchange-api", BreakerOptions{FailureThreshold: 2, OpenTimeout: 20 * time.Millisecond})
+	failure := errors.New("502 bad gateway")
+	var calls int
+	fail := func(ctx context.Context) error {
+		calls++
+		return failure
+	}
+	succeed := func(ctx context.Context) error {
+		calls++
+		return nil
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := breaker.Execute(ctx, fail); err != failure {
+			t.Fatalf("Expected the task error, got: %v", err)
+		}
+	}
+	if breaker.State() != StateOpen {
+		t.Fatalf("Expected the breaker to open, got %s", breaker.State())
+	}
+	if err := breaker.Execute(ctx, succeed); !errors.Is(err, ErrCircuitOpen) || calls != 2 {
+		t.Fatalf("Expected the call to be rejected, got %d calls and: %v", calls, err)
+	}
+
+	// A failed probe opens the breaker again
+	time.Sleep(25 * time.Millisecond)
+	if err := breaker.Execute(ctx, fail); err != failure || breaker.State() != StateOpen {
+		t.Fatalf("Expected the failed probe to reopen the breaker, got %s and: %v", breaker.State(), err)
+	}
+
+	// A successful probe closes it
+	time.Sleep(25 * time.Millisecond)
+	if err := breaker.Execute(ctx, succeed); err != nil || breaker.State() != StateClosed {
+		t.Fatalf("Expected the successful probe to close the breaker, got %s and: %v", breaker.State(), err)
+	}
+
+	expected := "closed->open,open->half-open,half-open->open,open->half-open,half-open->closed"
+	if got := strings.Join(transitions(t, logOutput.String()), ","); got != expected {
+		t.Errorf("Expected transitions %s, got %s", expected, got)
+	}
+}
+
+// TestCircuitBreakerHalfOpenProbes tests that only the allowed number of probes run concurrently
+func TestCircuitBreakerHalfOpenProbes(t *testing.T) {
+	breaker := NewCircuitBreaker("exchange-api", BreakerOptions{FailureThreshold: 1, OpenTimeout: time.Millisecond})
+	breaker.Execute(context.Background(), func(ctx context.Context) error { return errors.New("down") })
+	time.Sleep(5 * time.Millisecond)
+
+	probing := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- breaker.Execute(context.Background(), func(ctx context.Context) error {
+			close(probing)
+			<-release
+			return nil
+		})
+	}()
+	<-probing
+
+	if err := breaker.Execute(context.Background(), func(ctx context.Context) error { return nil }); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected the second probe to be rejected, got: %v", err)
+	}
+	close(release)
+	if err := <-done; err != nil || breaker.State() != StateClosed {
+		t.Errorf("Expected the probe to close the breaker, got %s and: %v", breaker.State(), err)
+	}
+}
+
+// TestCircuitBreakerPanickingProbe tests that a panicking probe releases its slot and reopens the breaker
+func TestCircuitBreakerPanickingProbe(t *testing.T) {
+	breaker := NewCircuitBreaker("exchange-api", BreakerOptions{FailureThreshold: 1, OpenTimeout: time.Millisecond})
+	breaker.Execute(context.Background(), func(ctx context.Context) error { return errors.New("down") })
+	time.Sleep(5 * time.Millisecond)
+
+	func() {
+		defer func() {
+			if r := recover(); r != "nil map" {
+				t.Errorf("Expected the probe panic to be propagated, got %v", r)
+			}
+		}()
+		breaker.Execute(context.Background(), func(ctx context.Context) error { panic("nil map") })
+	}()
+	if breaker.State() != StateOpen {
+		t.Fatalf("Expected the panicking probe to reopen the breaker, got %s", breaker.State())
+	}
+
+	// The probe slot is free again, so the next probe can close the breaker
+	time.Sleep(5 * time.Millisecond)
+	if err := breaker.Execute(context.Background(), func(ctx context.Context) error { return nil }); err != nil || breaker.State() != StateClosed {
+		t.Errorf("Expected the next probe to close the breaker, got %s and: %v", breaker.State(), err)
+	}
+}
+
+// TestCircuitBreakerIgnoresCancellation tests that shutdown cancellation does not count as a failure
+func TestCircuitBreakerIgnoresCancellation(t *testing.T) {
+	breaker := NewCircuitBreaker("exchange-api", BreakerOptions{FailureThreshold: 1})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	task := Wrap(func(ctx context.Context) error { return ctx.Err() }, WithCircuitBreaker(breaker))
+	if err := task(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the context error, got: %v", err)
+	}
+	if breaker.State() != StateClosed {
+		t.Errorf("Expected the breaker to stay closed, got %s", breaker.State())
+	}
+}
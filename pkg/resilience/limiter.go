@@ -0,0 +1,111 @@
+// Package resilience provides composable task wrappers for tasks that talk to external systems.
+// This file contains the token bucket rate limiter and the rate limit wrapper.
+package resilience
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/goregion/goture"
+)
+
+// RateLimiter is a token bucket rate limiter safe for concurrent use.
+// The bucket holds up to burst tokens and is refilled at rate tokens per second;
+// every call takes one token.
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64   // Tokens added per second
+	burst  float64   // Bucket capacity
+	tokens float64   // Tokens currently available
+	last   time.Time // Last time the bucket was refilled
+}
+
+// NewRateLimiter creates a limiter that allows rate calls per second with bursts of up
+// to burst calls. The bucket starts full. A burst below 1 is treated as 1 and a
+// non-positive rate disables the limit.
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Allow takes a token if one is available and reports whether it did.
+func (l *RateLimiter) Allow() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.rate <= 0 {
+		return true
+	}
+	l.refill(time.Now())
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// Wait blocks until a token is available and takes it.
+// It returns the context error if ctx is done first.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	for {
+		delay, ok := l.reserve()
+		if ok {
+			return nil
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve takes a token if one is available, otherwise it returns the time
+// until the next token is added.
+func (l *RateLimiter) reserve() (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.rate <= 0 {
+		return 0, true
+	}
+	l.refill(time.Now())
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0, true
+	}
+	return time.Duration(math.Ceil((1 - l.tokens) / l.rate * float64(time.Second))), false
+}
+
+// refill adds the tokens accumulated since the last refill.
+func (l *RateLimiter) refill(now time.Time) {
+	if elapsed := now.Sub(l.last); elapsed > 0 {
+		l.tokens = math.Min(l.burst, l.tokens+elapsed.Seconds()*l.rate)
+		l.last = now
+	}
+}
+
+// WithRateLimit returns a wrapper that waits for a token of the limiter before every call.
+// The limiter can be shared by several tasks to limit their combined rate.
+func WithRateLimit(limiter *RateLimiter) Wrapper {
+	return func(task goture.Task) goture.Task {
+		return func(ctx context.Context) error {
+			if err := limiter.Wait(ctx); err != nil {
+				return err
+			}
+			return task(ctx)
+		}
+	}
+}
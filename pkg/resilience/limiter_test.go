@@ -0,0 +1,64 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestRateLimiterBurst tests that the bucket allows the burst and then refills at the rate
+func TestRateLimiterBurst(t *testing.T) {
+	limiter := NewRateLimiter(50, 2)
+
+	if !limiter.Allow() || !limiter.Allow() {
+		t.Fatal("Expected the burst to be allowed")
+	}
+	if limiter.Allow() {
+		t.Error("Expected the third call to be limited")
+	}
+
+	time.Sleep(25 * time.Millisecond)
+	if !limiter.Allow() {
+		t.Error("Expected a token after the refill interval")
+	}
+}
+
+// TestRateLimitWrapper tests that wrapped calls are spaced according to the rate
+func TestRateLimitWrapper(t *testing.T) {
+	var calls int
+	task := Wrap(func(ctx context.Context) error {
+		calls++
+		return nil
+	}, WithRateLimit(NewRateLimiter(100, 1)))
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := task(context.Background()); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 25*time.Millisecond {
+		t.Errorf("Expected 3 calls to wait for tokens (~30ms), took %v", elapsed)
+	}
+	if calls != 4 {
+		t.Errorf("Expected 4 calls, got %d", calls)
+	}
+}
+
+// TestRateLimiterWaitCanceled tests that waiting stops when the context is done
+func TestRateLimiterWaitCanceled(t *testing.T) {
+	limiter := NewRateLimiter(0.1, 1)
+	limiter.Allow()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	task := WithRateLimit(limiter)(func(ctx context.Context) error {
+		t.Error("Task must not be called without a token")
+		return nil
+	})
+	if err := task(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got: %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+// Package resilience provides composable task wrappers for tasks that talk to external systems.
+// This file contains the retry wrapper that calls a failed task again after a backoff delay.
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/goregion/goture"
+	"github.com/goregion/hexago/pkg/launcher"
+	"github.com/goregion/hexago/pkg/log"
+)
+
+// ErrRetriesExhausted is returned when a task keeps failing after the last attempt
+// allowed by its retry policy. The returned error wraps both this sentinel and the
+// last task error.
+var ErrRetriesExhausted = errors.New("retries exhausted")
+
+// RetryPolicy configures how a failed task is retried.
+type RetryPolicy struct {
+	Backoff     launcher.Backoff // Delay between attempts
+	MaxAttempts int              // Maximum number of calls including the first one, zero means unlimited
+	Retryable   func(error) bool // Reports whether an error is worth retrying, nil means every error
+}
+
+// DefaultRetryPolicy returns a policy that makes up to 5 attempts with the launcher default backoff.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		Backoff:     launcher.DefaultBackoff(),
+		MaxAttempts: 5,
+	}
+}
+
+// WithRetry returns a wrapper that calls the task again when it fails, waiting the policy
+// backoff between attempts. Errors rejected by Retryable are returned immediately, and
+// once the context is done the last error is returned without further attempts.
+// Every retry is logged at the warning level through the context logger.
+func WithRetry(policy RetryPolicy) Wrapper {
+	return func(task goture.Task) goture.Task {
+		return func(ctx context.Context) error {
+			for attempt := 1; ; attempt++ {
+				err := task(ctx)
+				if err == nil || ctx.Err() != nil {
+					return err
+				}
+				if policy.Retryable != nil && !policy.Retryable(err) {
+					return err
+				}
+				if policy.MaxAttempts > 0 && attempt >= policy.MaxAttempts {
+					return fmt.Errorf("%w after %d attempts: %w", ErrRetriesExhausted, attempt, err)
+				}
+
+				delay := policy.Backoff.Delay(attempt - 1)
+				if logger, logErr := log.GetLoggerFromContext(ctx); logErr == nil {
+					logger.Warn("retrying task",
+						"error", err,
+						"attempt", attempt,
+						"backoff", delay,
+					)
+				}
+
+				timer := time.NewTimer(delay)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return err
+				case <-timer.C:
+				}
+			}
+		}
+	}
+}
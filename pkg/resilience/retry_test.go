@@ -0,0 +1,90 @@
+package resilience
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goregion/hexago/pkg/launcher"
+	"github.com/goregion/hexago/pkg/log"
+)
+
+// TestRetrySucceeds tests that a failing task is retried until it succeeds and retries are logged
+func TestRetrySucceeds(t *testing.T) {
+	var logOutput bytes.Buffer
+	ctx := log.WithLoggerContext(context.Background(), log.NewLogger(log.NewJsonHandler(&logOutput)))
+
+	var calls int
+	task := Wrap(func(ctx context.Context) error {
+		calls++
+		if calls < 3 {
+			return errors.New("503 service unavailable")
+		}
+		return nil
+	}, WithRetry(RetryPolicy{Backoff: launcher.Backoff{Initial: time.Millisecond}, MaxAttempts: 5}))
+
+	if err := task(ctx); err != nil {
+		t.Fatalf("Expected success, got: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls)
+	}
+	if count := strings.Count(logOutput.String(), "retrying task"); count != 2 {
+		t.Errorf("Expected 2 retry records, got %d:\n%s", count, logOutput.String())
+	}
+}
+
+// TestRetryExhausted tests that the last error is returned once the attempts are used up
+func TestRetryExhausted(t *testing.T) {
+	failure := errors.New("connection refused")
+	var calls int
+	task := WithRetry(RetryPolicy{MaxAttempts: 3})(func(ctx context.Context) error {
+		calls++
+		return failure
+	})
+
+	err := task(context.Background())
+	if !errors.Is(err, ErrRetriesExhausted) || !errors.Is(err, failure) {
+		t.Errorf("Expected exhausted retries wrapping the failure, got: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls)
+	}
+}
+
+// TestRetryNotRetryable tests that rejected errors are returned immediately
+func TestRetryNotRetryable(t *testing.T) {
+	unauthorized := errors.New("401 unauthorized")
+	var calls int
+	task := WithRetry(RetryPolicy{Retryable: func(err error) bool { return !errors.Is(err, unauthorized) }})(
+		func(ctx context.Context) error {
+			calls++
+			return unauthorized
+		})
+
+	if err := task(context.Background()); err != unauthorized || calls != 1 {
+		t.Errorf("Expected a single call returning the error, got %d calls and: %v", calls, err)
+	}
+}
+
+// TestRetryCanceled tests that the backoff wait ends when the context is done
+func TestRetryCanceled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	failure := errors.New("timeout")
+	task := WithRetry(RetryPolicy{Backoff: launcher.Backoff{Initial: time.Hour}})(func(ctx context.Context) error {
+		return failure
+	})
+
+	start := time.Now()
+	if err := task(ctx); err != failure {
+		t.Errorf("Expected the last error, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the wait to be canceled, took %v", elapsed)
+	}
+}
@@ -0,0 +1,40 @@
+// Package resilience provides composable task wrappers for tasks that talk to external systems.
+// This file contains the Wrapper type and the Wrap function that decorates a task
+// with a chain of wrappers, e.g. a rate limit, retries and a circuit breaker.
+package resilience
+
+import (
+	"github.com/goregion/goture"
+)
+
+// Wrapper decorates a task with additional behaviour.
+// Any func(ctx context.Context) error can be wrapped since it is assignable to goture.Task.
+type Wrapper func(task goture.Task) goture.Task
+
+// Wrap decorates the task with the wrappers. The first wrapper is the outermost one,
+// so it sees every call made by the wrappers that follow it.
+//
+// Example:
+//
+//	limiter := resilience.NewRateLimiter(5, 1) // 5 calls per second
+//	breaker := resilience.NewCircuitBreaker("exchange-api", resilience.BreakerOptions{})
+//
+//	poll := resilience.Wrap(exchange.PollTicker,
+//		resilience.WithRetry(resilience.DefaultRetryPolicy()), // retries every failed or rejected call
+//		resilience.WithRateLimit(limiter),                     // each attempt waits for a token
+//		resilience.WithCircuitBreaker(breaker),                // attempts fail fast while the API is down
+//	)
+//
+//	launcher.NewAppLauncher().
+//		WithLoggerContext(logger).
+//		WithGrexitContext().
+//		WaitApplication(poll).
+//		LogIfError(logger, "Application stopped")
+func Wrap(task goture.Task, wrappers ...Wrapper) goture.Task {
+	for i := len(wrappers) - 1; i >= 0; i-- {
+		if wrappers[i] != nil {
+			task = wrappers[i](task)
+		}
+	}
+	return task
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/doug-martin/goqu/v9"
+	"github.com/goregion/hexago/pkg/metrics"
 )
 
 type Transaction interface {
@@ -110,3 +111,43 @@ func GetTransaction(ctx context.Context, client *Client) Transaction {
 	}
 	return client
 }
+
+// HealthCheck pings the database. It implements launcher.HealthChecker,
+// so the client can be registered in the launcher health registry.
+func (db *Client) HealthCheck(ctx context.Context) error {
+	if pinger, ok := db.Database.Db.(interface{ PingContext(context.Context) error }); ok {
+		return pinger.PingContext(ctx)
+	}
+	_, err := db.Database.Db.ExecContext(ctx, "SELECT 1")
+	return err
+}
+
+// RegisterMetrics registers the connection pool stats of the database in the registry.
+// The name is used as the "db" label, so several clients can share a registry.
+// Pool stats are read on every scrape. It fails if the underlying database does not
+// expose pool stats, which is the case for anything other than *sql.DB.
+func (db *Client) RegisterMetrics(registry *metrics.Registry, name string) error {
+	statser, ok := db.Database.Db.(interface{ Stats() sql.DBStats })
+	if !ok {
+		return fmt.Errorf("database %q does not expose connection pool stats", name)
+	}
+	labels := metrics.Labels{"db": name}
+
+	registry.GaugeFunc("sql_pool_open_connections", "Established connections, both in use and idle.", labels,
+		func() float64 { return float64(statser.Stats().OpenConnections) })
+	registry.GaugeFunc("sql_pool_in_use_connections", "Connections currently in use.", labels,
+		func() float64 { return float64(statser.Stats().InUse) })
+	registry.GaugeFunc("sql_pool_idle_connections", "Idle connections.", labels,
+		func() float64 { return float64(statser.Stats().Idle) })
+	registry.GaugeFunc("sql_pool_max_open_connections", "Maximum number of open connections, zero means unlimited.", labels,
+		func() float64 { return float64(statser.Stats().MaxOpenConnections) })
+	registry.CounterFunc("sql_pool_wait_count_total", "Connections waited for.", labels,
+		func() float64 { return float64(statser.Stats().WaitCount) })
+	registry.CounterFunc("sql_pool_wait_duration_seconds_total", "Time blocked waiting for a new connection.", labels,
+		func() float64 { return statser.Stats().WaitDuration.Seconds() })
+	registry.CounterFunc("sql_pool_max_idle_closed_total", "Connections closed due to the idle connection limit.", labels,
+		func() float64 { return float64(statser.Stats().MaxIdleClosed) })
+	registry.CounterFunc("sql_pool_max_lifetime_closed_total", "Connections closed due to the maximum connection lifetime.", labels,
+		func() float64 { return float64(statser.Stats().MaxLifetimeClosed) })
+	return nil
+}